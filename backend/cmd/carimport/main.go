@@ -0,0 +1,68 @@
+// carimport นำเข้ารถจากไฟล์ CSV (หัวตารางตามชื่อคอลัมน์ ดู services.CarImportService)
+//
+//	go run ./cmd/carimport -file cars.csv -manager 1 -dry-run -result result.csv
+//
+// ถ้ามีแถวผิดจะไม่บันทึกอะไรเลยและจบด้วย exit code 1
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/configs"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+)
+
+func main() {
+	dbName := flag.String("db", "car_full_data.db", "sqlite database file")
+	file := flag.String("file", "", "CSV file to import")
+	managerID := flag.Uint("manager", 0, "manager id for rows without manager_id")
+	dryRun := flag.Bool("dry-run", false, "validate and report without saving")
+	resultPath := flag.String("result", "", "write per-row result CSV to this path")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	configs.ConnectDatabase(*dbName)
+	if err := services.RegisterAuditCallbacks(configs.DB); err != nil {
+		log.Fatal("Failed to register audit log:", err)
+	}
+	svc := services.NewCarImportService(configs.DB)
+	result, err := svc.Import(f, services.CarImportOptions{
+		FileName:  *file,
+		DryRun:    *dryRun,
+		ManagerID: uint(*managerID),
+	})
+	if err != nil {
+		log.Fatal("Import failed:", err)
+	}
+
+	for _, row := range result.Rows {
+		if row.Status == services.ImportRowError {
+			log.Printf("row %d: %s", row.Row, row.Message)
+		}
+	}
+	if *resultPath != "" {
+		record, err := svc.Get(result.ImportID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*resultPath, []byte(record.ResultCSV), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Printf("Import %d: %d rows, %d created, %d skipped, %d failed, committed=%v",
+		result.ImportID, result.TotalRows, result.Created, result.Skipped, result.Failed, result.Committed)
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
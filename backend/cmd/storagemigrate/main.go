@@ -0,0 +1,186 @@
+// storagemigrate ย้ายไฟล์รูปรถและเอกสารจากโฟลเดอร์เดิมบนเครื่องไปยัง storage ที่ตั้งค่าไว้
+// (STORAGE_BACKEND และค่าอื่นๆ ดูที่ configs.ConnectStorage) แล้วแก้ CarPicture.Path ให้ชี้ไฟล์ใหม่
+//
+//	go run ./cmd/storagemigrate -db car_full_data.db -images ./public/images/cars -documents ./public/documents
+//
+// รูปชื่อเดิม (เช่น car2_main.jpg) จะถูกบันทึกผ่าน ImageStore ใหม่ ได้ชื่อจาก hash พร้อมขนาดย่อย
+// รันซ้ำได้ รูปที่ย้ายแล้วจะถูกข้าม
+package main
+
+import (
+	"context"
+	"flag"
+	"io/fs"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/configs"
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+)
+
+func main() {
+	dbName := flag.String("db", "car_full_data.db", "sqlite database file")
+	imageDir := flag.String("images", "./public/images/cars", "legacy car image folder")
+	documentDir := flag.String("documents", "", "legacy document folder (copied under documents/)")
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	flag.Parse()
+
+	configs.ConnectDatabase(*dbName)
+	configs.ConnectStorage()
+	images := storage.NewImageStore(configs.Storage, storage.CarImagePrefix)
+
+	var pictures []entity.CarPicture
+	if err := configs.DB.Find(&pictures).Error; err != nil {
+		log.Fatal("Failed to load car pictures:", err)
+	}
+
+	moved, rewritten, skipped, failed := 0, 0, 0, 0
+	for _, pic := range pictures {
+		// path เก่าอาจมีโฟลเดอร์ติดมา เช่น ./public/images/cars/x.jpg หรือ /images/cars/x.jpg
+		name := path.Base(strings.ReplaceAll(pic.Path, "\\", "/"))
+		if name == "" || name == "." || name == "/" {
+			log.Printf("picture %d: empty path, skipped", pic.ID)
+			skipped++
+			continue
+		}
+
+		if storage.IsContentAddressed(name) {
+			exists, err := images.Exists(name)
+			if err != nil {
+				log.Printf("picture %d: %v", pic.ID, err)
+				failed++
+				continue
+			}
+			if !exists {
+				if err := copyImageFiles(*imageDir, name, *dryRun); err != nil {
+					log.Printf("picture %d: %v", pic.ID, err)
+					failed++
+					continue
+				}
+				moved++
+			}
+			if name != pic.Path {
+				if err := rewritePath(pic, name, *dryRun); err != nil {
+					log.Printf("picture %d: %v", pic.ID, err)
+					failed++
+					continue
+				}
+				rewritten++
+			}
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(*imageDir, name))
+		if err != nil {
+			log.Printf("picture %d: %v", pic.ID, err)
+			failed++
+			continue
+		}
+		if *dryRun {
+			f.Close()
+			log.Printf("picture %d: would store %s and rewrite path", pic.ID, name)
+			moved++
+			rewritten++
+			continue
+		}
+		stored, err := images.Save(f)
+		f.Close()
+		if err != nil {
+			log.Printf("picture %d (%s): %v", pic.ID, name, err)
+			failed++
+			continue
+		}
+		moved++
+		if err := rewritePath(pic, stored.Name, false); err != nil {
+			log.Printf("picture %d: %v", pic.ID, err)
+			failed++
+			continue
+		}
+		rewritten++
+	}
+	log.Printf("Car pictures: %d stored, %d paths rewritten, %d skipped, %d failed", moved, rewritten, skipped, failed)
+
+	if *documentDir != "" {
+		copied, err := copyDocuments(*documentDir, *dryRun)
+		if err != nil {
+			log.Fatal("Failed to copy documents:", err)
+		}
+		log.Printf("Documents: %d copied", copied)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// copyImageFiles คัดลอกรูปที่ตั้งชื่อจาก hash แล้ว (พร้อมขนาดย่อยถ้ามี) ไปยัง storage ตามเดิม
+func copyImageFiles(dir, name string, dryRun bool) error {
+	names := []string{name, storage.VariantName(name, storage.VariantWeb), storage.VariantName(name, storage.VariantThumb)}
+	for i, n := range names {
+		f, err := os.Open(filepath.Join(dir, n))
+		if err != nil {
+			if i > 0 && os.IsNotExist(err) {
+				continue // ขนาดย่อยไม่มีก็ได้ ระบบจะส่งต้นฉบับแทน
+			}
+			return err
+		}
+		if dryRun {
+			f.Close()
+			log.Printf("would copy %s", n)
+			continue
+		}
+		err = configs.Storage.Put(context.Background(), storage.CarImagePrefix+n, f, mime.TypeByExtension(filepath.Ext(n)))
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rewritePath(pic entity.CarPicture, name string, dryRun bool) error {
+	if dryRun {
+		log.Printf("picture %d: would rewrite %q -> %q", pic.ID, pic.Path, name)
+		return nil
+	}
+	return configs.DB.Model(&entity.CarPicture{}).Where("id = ?", pic.ID).Update("path", name).Error
+}
+
+// copyDocuments คัดลอกทุกไฟล์ในโฟลเดอร์ไปที่ documents/<path เดิม> (key ที่เก็บในฐานข้อมูลยังใช้ได้)
+func copyDocuments(dir string, dryRun bool) (int, error) {
+	copied := 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		key := "documents/" + filepath.ToSlash(rel)
+		if !storage.ValidKey(key) {
+			log.Printf("skip %s: unsupported file name", p)
+			return nil
+		}
+		if dryRun {
+			log.Printf("would copy %s -> %s", p, key)
+			copied++
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := configs.Storage.Put(context.Background(), key, f, mime.TypeByExtension(filepath.Ext(p))); err != nil {
+			return err
+		}
+		copied++
+		return nil
+	})
+	return copied, err
+}
@@ -30,6 +30,11 @@ func ConnectDatabase(dbName string) {
 		&entity.Detail{},
 		&entity.Car{},
 		&entity.CarPicture{},
+		&entity.CarImport{},
+		&entity.CarExpense{},
+		&entity.CommissionRule{},
+		&entity.CommissionStatement{},
+		&entity.CommissionStatementLine{},
 		&entity.SaleList{},
 		&entity.RentList{},
 		&entity.DateforRent{},
@@ -37,6 +42,7 @@ func ConnectDatabase(dbName string) {
 		&entity.Customer{},
 		&entity.CarSystem{},
 		&entity.SalesContract{},
+		&entity.RentContract{},
 		&entity.InspectionAppointment{},
 		&entity.InspectionSystem{},
 		&entity.PickupDelivery{},
@@ -44,7 +50,35 @@ func ConnectDatabase(dbName string) {
 		&entity.District{},
 		&entity.SubDistrict{},
 		&entity.Payment{},
+		&entity.Receipt{},
 		&entity.LeaveRequest{}, // ✅ เพิ่ม
+		&entity.LeaveBalance{},
+		&entity.ShiftTemplate{},
+		&entity.Roster{},
+		&entity.RosterShift{},
+		&entity.Attendance{},
+		&entity.Lead{},
+		&entity.LeadTask{},
+		&entity.LeadActivity{},
+		&entity.TestDrive{},
+		&entity.TradeIn{},
+		&entity.TradeInPhoto{},
+		&entity.TradeInAppraisalItem{},
+		&entity.RentalPriceRule{},
+		&entity.RentalDiscountTier{},
+		&entity.RentalPriceAdjustment{},
+		&entity.MaintenanceRecord{},
+		&entity.ServiceReminder{},
+		&entity.CarLifecycleEvent{},
+		&entity.SalePriceChange{},
+		&entity.PriceAlert{},
+		&entity.PriceAlertHit{},
+		&entity.Favourite{},
+		&entity.SavedSearch{},
+		&entity.Notification{},
+		&entity.NotificationPreference{},
+		&entity.NotificationDelivery{},
+		&entity.AuditLog{},
 
 	)
 	if err != nil {
@@ -0,0 +1,62 @@
+package configs
+
+import (
+	"log"
+	"os"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/notify"
+)
+
+// Channels ช่องทางแจ้งเตือนที่ตั้งค่าไว้ (key = entity.NotificationChannel*)
+// ช่องทางที่ไม่ได้ตั้งค่าจะไม่มีใน map และรายการส่งจะถูกย้ายไป dead-letter
+var Channels map[string]notify.Channel
+
+// ConnectNotify เลือกช่องทางแจ้งเตือนจาก environment
+//
+//	NOTIFY_SMTP_ADDR (host:port), NOTIFY_SMTP_FROM, NOTIFY_SMTP_USER, NOTIFY_SMTP_PASS
+//	NOTIFY_LINE_TOKEN, NOTIFY_LINE_URL (ไม่ตั้ง = LINE push API)
+//	NOTIFY_SMS_URL, NOTIFY_SMS_TOKEN, NOTIFY_SMS_SENDER
+func ConnectNotify() {
+	Channels = map[string]notify.Channel{}
+
+	if addr := os.Getenv("NOTIFY_SMTP_ADDR"); addr != "" {
+		ch, err := notify.NewSMTPChannel(notify.SMTPConfig{
+			Addr:     addr,
+			From:     os.Getenv("NOTIFY_SMTP_FROM"),
+			Username: os.Getenv("NOTIFY_SMTP_USER"),
+			Password: os.Getenv("NOTIFY_SMTP_PASS"),
+		})
+		if err != nil {
+			log.Fatal("Failed to configure email notifications:", err)
+		}
+		Channels[entity.NotificationChannelEmail] = ch
+	}
+	if token := os.Getenv("NOTIFY_LINE_TOKEN"); token != "" {
+		ch, err := notify.NewLineChannel(notify.LineConfig{
+			Endpoint: os.Getenv("NOTIFY_LINE_URL"),
+			Token:    token,
+		})
+		if err != nil {
+			log.Fatal("Failed to configure LINE notifications:", err)
+		}
+		Channels[entity.NotificationChannelLine] = ch
+	}
+	if endpoint := os.Getenv("NOTIFY_SMS_URL"); endpoint != "" {
+		ch, err := notify.NewSMSChannel(notify.SMSConfig{
+			Endpoint: endpoint,
+			Token:    os.Getenv("NOTIFY_SMS_TOKEN"),
+			Sender:   os.Getenv("NOTIFY_SMS_SENDER"),
+		})
+		if err != nil {
+			log.Fatal("Failed to configure SMS notifications:", err)
+		}
+		Channels[entity.NotificationChannelSMS] = ch
+	}
+
+	names := make([]string, 0, len(Channels))
+	for name := range Channels {
+		names = append(names, name)
+	}
+	log.Println("Notification channels:", names)
+}
@@ -0,0 +1,54 @@
+package configs
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+)
+
+var Storage storage.Backend
+
+// ConnectStorage เลือกที่เก็บไฟล์จาก environment
+//
+//	STORAGE_BACKEND=local (ค่าเริ่มต้น) เก็บใน STORAGE_LOCAL_ROOT (./public)
+//	STORAGE_BACKEND=s3 ใช้ S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY, S3_PATH_STYLE
+//	STORAGE_SIGNING_KEY ใช้เซ็นลิงก์ดาวน์โหลดของ local (ไม่ตั้งจะใช้ SECRET_KEY)
+func ConnectStorage() {
+	backend, err := newStorageBackend()
+	if err != nil {
+		log.Fatal("Failed to configure storage:", err)
+	}
+	Storage = backend
+}
+
+func newStorageBackend() (storage.Backend, error) {
+	switch strings.ToLower(os.Getenv("STORAGE_BACKEND")) {
+	case "s3":
+		backend, err := storage.NewS3Backend(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Storage: s3 bucket", os.Getenv("S3_BUCKET"))
+		return backend, nil
+	default:
+		root := os.Getenv("STORAGE_LOCAL_ROOT")
+		if root == "" {
+			root = "./public"
+		}
+		secret := os.Getenv("STORAGE_SIGNING_KEY")
+		if secret == "" {
+			secret = SECRET_KEY
+		}
+		log.Println("Storage: local", root)
+		return storage.NewLocalBackend(root, "/files", []byte(secret)), nil
+	}
+}
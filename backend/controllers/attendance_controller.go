@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AttendanceController struct {
+	svc *services.AttendanceService
+}
+
+func NewAttendanceController(db *gorm.DB) *AttendanceController {
+	return &AttendanceController{svc: services.NewAttendanceService(db)}
+}
+
+// =========================
+// POST /employees/me/clock-in (Employee)
+// body (ไม่บังคับ): {"note": "..."}
+// =========================
+func (ac *AttendanceController) ClockIn(c *gin.Context) {
+	var body struct {
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	att, err := ac.svc.WithContext(c).ClockIn(c.GetUint("employeeID"), body.Note)
+	if err != nil {
+		respondAttendanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, att)
+}
+
+// =========================
+// POST /employees/me/clock-out (Employee)
+// =========================
+func (ac *AttendanceController) ClockOut(c *gin.Context) {
+	att, err := ac.svc.WithContext(c).ClockOut(c.GetUint("employeeID"))
+	if err != nil {
+		respondAttendanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, att)
+}
+
+// =========================
+// GET /employees/me/attendance?date_from=&date_to= (Employee)
+// =========================
+func (ac *AttendanceController) GetMyAttendance(c *gin.Context) {
+	var filter services.AttendanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.EmployeeID = c.GetUint("employeeID")
+	items, err := ac.svc.List(filter)
+	if err != nil {
+		respondAttendanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// =========================
+// GET /attendances?employee_id=&date_from=&date_to= (Manager)
+// =========================
+func (ac *AttendanceController) ListAttendances(c *gin.Context) {
+	ac.respond(c, func(f services.AttendanceFilter) (interface{}, error) { return ac.svc.List(f) })
+}
+
+// =========================
+// GET /reports/attendance?employee_id=&date_from=&date_to= (Manager)
+// =========================
+func (ac *AttendanceController) GetAttendanceReport(c *gin.Context) {
+	ac.respond(c, func(f services.AttendanceFilter) (interface{}, error) { return ac.svc.Summary(f) })
+}
+
+// =========================
+// GET /reports/lateness?employee_id=&date_from=&date_to= (Manager)
+// =========================
+func (ac *AttendanceController) GetLatenessReport(c *gin.Context) {
+	ac.respond(c, func(f services.AttendanceFilter) (interface{}, error) { return ac.svc.Lateness(f) })
+}
+
+func (ac *AttendanceController) respond(c *gin.Context, run func(services.AttendanceFilter) (interface{}, error)) {
+	var filter services.AttendanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := run(filter)
+	if err != nil {
+		respondAttendanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+func respondAttendanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAlreadyClockedIn), errors.Is(err, services.ErrNotClockedIn):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDateFilter), errors.Is(err, services.ErrInvalidReportRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AuditController struct {
+	svc *services.AuditService
+}
+
+func NewAuditController(db *gorm.DB) *AuditController {
+	return &AuditController{svc: services.NewAuditService(db)}
+}
+
+// =========================
+// GET /audit-logs?entity=&record_id=&actor_id=&role=&action=&date_from=&date_to=&page=&limit= (Manager)
+// ประวัติของ record ใดก็ได้: ?entity=sale_lists&record_id=12
+// =========================
+func (ac *AuditController) ListAuditLogs(c *gin.Context) {
+	var filter services.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logs, total, err := ac.svc.List(filter)
+	if err != nil {
+		respondAuditError(c, err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, logs)
+}
+
+// =========================
+// GET /audit-logs/entities (Manager)
+// =========================
+func (ac *AuditController) ListAuditEntities(c *gin.Context) {
+	names, err := ac.svc.Entities()
+	if err != nil {
+		respondAuditError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, names)
+}
+
+func respondAuditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAuditFilter), errors.Is(err, services.ErrInvalidDateFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
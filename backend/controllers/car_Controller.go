@@ -1,45 +1,140 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type CarController struct {
-	DB *gorm.DB
+	DB        *gorm.DB
+	svc       *services.CarService
+	search    *services.CarSearchService
+	inventory *services.CarInventoryService
+	images    *storage.ImageStore
 }
 
-func NewCarController(db *gorm.DB) *CarController {
-	return &CarController{DB: db}
+func NewCarController(db *gorm.DB, backend storage.Backend) *CarController {
+	return &CarController{
+		DB:        db,
+		svc:       services.NewCarService(db),
+		search:    services.NewCarSearchService(db),
+		inventory: services.NewCarInventoryService(db),
+		images:    storage.NewImageStore(backend, storage.CarImagePrefix),
+	}
 }
 
 // =========================
 // GET /cars
+// ?brand=&model=&sub_model=&year_min=&year_max=&price_min=&price_max=
+// &mileage_min=&mileage_max=&color=&condition=&province=&status=sale|rent
+// &plate=&vin=
+// &sort=priceAsc|priceDesc|yearUsedAsc|yearUsedDesc|mileageAsc|mileageDesc|condition
+// &page=&limit= หรือ &cursor=&limit=
 // =========================
 func (cc *CarController) GetAllCars(c *gin.Context) {
-	var cars []entity.Car
-	if err := cc.DB.Preload("Detail.Brand").
-		Preload("Detail.CarModel").
-		Preload("Detail.SubModel").
-		Preload("Pictures").
-		Preload("Province").
-		Preload("Manager").
-		Preload("SaleList.Employee"). // Preload Employee
-		Preload("RentList").
-		Preload("RentList.RentAbleDates.DateforRent").
-		Find(&cars).Error; err != nil {
+	var filter services.CarFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := cc.svc.List(filter)
+	if err == services.ErrInvalidCursor || errors.Is(err, services.ErrInvalidSort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := make([]entity.CarResponse, 0)
-	for _, car := range cars {
+	resp := make([]entity.CarResponse, 0, len(page.Cars))
+	for _, car := range page.Cars {
 		resp = append(resp, mapCarToResponse(car))
 	}
+	if err := markFavourites(cc.DB, c, resp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// ข้อมูลการแบ่งหน้าส่งผ่าน header เพื่อให้ body ยังเป็น array เหมือนเดิม
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if filter.Paginated() {
+		c.Header("X-Limit", strconv.Itoa(page.Limit))
+		if page.Page > 0 {
+			c.Header("X-Page", strconv.Itoa(page.Page))
+		}
+		if page.NextCursor != "" {
+			c.Header("X-Next-Cursor", page.NextCursor)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// =========================
+// GET /cars/search?q=camry ขาว 2012&page=&limit=
+// ค้นหาแบบ full-text เรียงตามความเกี่ยวข้อง
+// =========================
+func (cc *CarController) SearchCars(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultCarPageLimit)))
+	if limit <= 0 || limit > services.MaxCarPageLimit {
+		limit = services.DefaultCarPageLimit
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page <= 0 {
+		page = 1
+	}
+
+	hits, total, err := cc.search.Search(c.Query("q"), limit, (page-1)*limit)
+	if err == services.ErrEmptySearchQuery {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
+	ids := make([]uint, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.CarID)
+	}
+	var cars []entity.Car
+	if len(ids) > 0 {
+		if err := services.PreloadCar(cc.DB).Where("id IN ?", ids).Find(&cars).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	byID := make(map[uint]entity.Car, len(cars))
+	for _, car := range cars {
+		byID[car.ID] = car
+	}
+
+	// คงลำดับตามคะแนนความเกี่ยวข้อง
+	resp := make([]entity.CarResponse, 0, len(hits))
+	for _, h := range hits {
+		if car, ok := byID[h.CarID]; ok {
+			resp = append(resp, mapCarToResponse(car))
+		}
+	}
+	if err := markFavourites(cc.DB, c, resp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("X-Page", strconv.Itoa(page))
+	c.Header("X-Limit", strconv.Itoa(limit))
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -50,16 +145,10 @@ func (cc *CarController) GetCarByID(c *gin.Context) {
 	id := c.Param("id")
 	var car entity.Car
 
-	if err := cc.DB.Preload("Detail.Brand").
-		Preload("Detail.CarModel").
-		Preload("Detail.SubModel").
-		Preload("Pictures").
-		Preload("Province").
-		Preload("Manager").
-		Preload("SaleList.Employee"). // Preload Employee
-		Preload("RentList").
-		Preload("RentList.RentAbleDates.DateforRent").
-		First(&car, id).Error; err != nil {
+	err := services.PreloadCar(cc.DB).
+		Preload("SaleList.PriceHistory", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&car, id).Error
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
 		} else {
@@ -68,8 +157,132 @@ func (cc *CarController) GetCarByID(c *gin.Context) {
 		return
 	}
 
-	resp := mapCarToResponse(car)
-	c.JSON(http.StatusOK, resp)
+	resp := []entity.CarResponse{mapCarToResponse(car)}
+	if err := markFavourites(cc.DB, c, resp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp[0])
+}
+
+// =========================
+// POST /cars (Manager)
+// =========================
+func (cc *CarController) CreateCar(c *gin.Context) {
+	managerID, ok := c.Get("managerID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CarInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	car, err := cc.inventory.WithContext(c).Create(managerID.(uint), input)
+	if err != nil {
+		respondCarError(c, err)
+		return
+	}
+	cc.respondCar(c, http.StatusCreated, car.ID)
+}
+
+// =========================
+// PUT /cars/:id (Manager)
+// =========================
+func (cc *CarController) UpdateCar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var input services.CarInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	car, err := cc.inventory.WithContext(c).Update(uint(id), input)
+	if err != nil {
+		respondCarError(c, err)
+		return
+	}
+	cc.respondCar(c, http.StatusOK, car.ID)
+}
+
+// =========================
+// DELETE /cars/:id (Manager)
+// ลบได้เฉพาะรถที่ไม่มีสัญญาค้างอยู่
+// =========================
+func (cc *CarController) DeleteCar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := cc.inventory.WithContext(c).Delete(uint(id)); err != nil {
+		respondCarError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ส่ง CarResponse ของรถที่เพิ่งบันทึก
+func (cc *CarController) respondCar(c *gin.Context, status int, id uint) {
+	var car entity.Car
+	if err := services.PreloadCar(cc.DB.WithContext(c)).First(&car, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, mapCarToResponse(car))
+}
+
+// แปลง error จาก service เป็น HTTP status
+func respondCarError(c *gin.Context, err error) {
+	var parseErr *time.ParseError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
+	case errors.Is(err, services.ErrCarPictureNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCarHasActiveContract),
+		errors.Is(err, services.ErrDuplicateVIN),
+		errors.Is(err, services.ErrDuplicateEngineNumber),
+		errors.Is(err, services.ErrDuplicatePlate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCarDetailRequired),
+		errors.Is(err, services.ErrPictureOrderMismatch),
+		errors.Is(err, services.ErrInvalidCarReference),
+		errors.Is(err, services.ErrInvalidVIN),
+		errors.As(err, &parseErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// markFavourites ตั้ง is_favourite ให้ลูกค้าที่ login (OptionalCustomerMiddleware)
+func markFavourites(db *gorm.DB, c *gin.Context, resp []entity.CarResponse) error {
+	customerID := c.GetUint("userID")
+	if customerID == 0 {
+		return nil
+	}
+	ids := make([]uint, len(resp))
+	for i, r := range resp {
+		ids[i] = r.ID
+	}
+	liked, err := services.FavouriteCarIDs(db, customerID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range resp {
+		resp[i].IsFavourite = liked[resp[i].ID]
+	}
+	return nil
 }
 
 // =========================
@@ -97,6 +310,7 @@ func mapCarToResponse(car entity.Car) entity.CarResponse {
 			EmployeeID:    employeeID,
 			EmployeeName:  employeeName,
 			EmployeePhone: employeePhone,
+			PriceHistory:  s.PriceHistory,
 		})
 	}
 
@@ -118,10 +332,17 @@ func mapCarToResponse(car entity.Car) entity.CarResponse {
 	}
 
 	// Detail
-	detail := entity.DetailFilter{
-		Brand:    *car.Detail.Brand,
-		Model:    *car.Detail.CarModel,
-		SubModel: *car.Detail.SubModel,
+	var detail entity.DetailFilter
+	if car.Detail != nil {
+		if car.Detail.Brand != nil {
+			detail.Brand = *car.Detail.Brand
+		}
+		if car.Detail.CarModel != nil {
+			detail.Model = *car.Detail.CarModel
+		}
+		if car.Detail.SubModel != nil {
+			detail.SubModel = *car.Detail.SubModel
+		}
 	}
 
 	// Pictures
@@ -145,6 +366,8 @@ func mapCarToResponse(car entity.Car) entity.CarResponse {
 		PurchaseDate:    car.PurchaseDate,
 		Mileage:         car.Mileage,
 		Condition:       car.Condition,
+		Lifecycle:       car.Lifecycle,
+		Identity:        services.CarIdentityOf(car),
 		SaleList:        saleList,
 		RentList:        rentList,
 		Pictures:        pictures,
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CarExpenseController struct {
+	svc *services.CarExpenseService
+}
+
+func NewCarExpenseController(db *gorm.DB) *CarExpenseController {
+	return &CarExpenseController{svc: services.NewCarExpenseService(db)}
+}
+
+// อ่าน :id และ :expenseId (ถ้ามี) จาก path
+func expensePathIDs(c *gin.Context) (carID, expenseID uint, ok bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, 0, false
+	}
+	if p := c.Param("expenseId"); p != "" {
+		eid, err := strconv.Atoi(p)
+		if err != nil || eid <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid expense id"})
+			return 0, 0, false
+		}
+		expenseID = uint(eid)
+	}
+	return uint(id), expenseID, true
+}
+
+// =========================
+// GET /cars/:id/expenses (Manager)
+// =========================
+func (ec *CarExpenseController) ListExpenses(c *gin.Context) {
+	carID, _, ok := expensePathIDs(c)
+	if !ok {
+		return
+	}
+	expenses, err := ec.svc.List(carID)
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, expenses)
+}
+
+// =========================
+// POST /cars/:id/expenses (Manager)
+// ใบเสร็จแนบภายหลังผ่าน POST /documents/car-expenses/:expenseId
+// =========================
+func (ec *CarExpenseController) CreateExpense(c *gin.Context) {
+	carID, _, ok := expensePathIDs(c)
+	if !ok {
+		return
+	}
+	var input services.CarExpenseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	expense, err := ec.svc.WithContext(c).Create(carID, c.GetUint("managerID"), input)
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, expense)
+}
+
+// =========================
+// PUT /cars/:id/expenses/:expenseId (Manager)
+// =========================
+func (ec *CarExpenseController) UpdateExpense(c *gin.Context) {
+	carID, expenseID, ok := expensePathIDs(c)
+	if !ok {
+		return
+	}
+	var input services.CarExpenseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	expense, err := ec.svc.WithContext(c).Update(carID, expenseID, input)
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, expense)
+}
+
+// =========================
+// DELETE /cars/:id/expenses/:expenseId (Manager)
+// =========================
+func (ec *CarExpenseController) DeleteExpense(c *gin.Context) {
+	carID, expenseID, ok := expensePathIDs(c)
+	if !ok {
+		return
+	}
+	if err := ec.svc.WithContext(c).Delete(carID, expenseID); err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// =========================
+// GET /cars/:id/profit (Manager)
+// ต้นทุนรวม กำไรจากการขาย และรายได้ค่าเช่า
+// =========================
+func (ec *CarExpenseController) GetProfit(c *gin.Context) {
+	carID, _, ok := expensePathIDs(c)
+	if !ok {
+		return
+	}
+	profit, err := ec.svc.Profit(carID)
+	if err != nil {
+		respondExpenseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, profit)
+}
+
+func respondExpenseError(c *gin.Context, err error) {
+	var parseErr *time.ParseError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
+	case errors.Is(err, services.ErrCarExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidExpenseCategory),
+		errors.Is(err, services.ErrInvalidExpenseAmount),
+		errors.As(err, &parseErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
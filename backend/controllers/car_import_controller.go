@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CarImportController struct {
+	svc *services.CarImportService
+}
+
+func NewCarImportController(db *gorm.DB) *CarImportController {
+	return &CarImportController{svc: services.NewCarImportService(db)}
+}
+
+// =========================
+// POST /cars/import?dry_run=true (Manager)
+// multipart: file (CSV มีหัวตาราง) — ถ้ามีแถวผิดจะไม่บันทึกเลยและตอบ 422 พร้อมผลรายแถว
+// =========================
+func (ic *CarImportController) ImportCars(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", c.PostForm("dry_run")))
+	result, err := ic.svc.WithContext(c).Import(file, services.CarImportOptions{
+		FileName:  header.Filename,
+		DryRun:    dryRun,
+		ManagerID: c.GetUint("managerID"),
+	})
+	if errors.Is(err, services.ErrInvalidImportFile) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case result.Failed > 0:
+		status = http.StatusUnprocessableEntity
+	case result.Committed && result.Created > 0:
+		status = http.StatusCreated
+	}
+	c.JSON(status, result)
+}
+
+// =========================
+// GET /cars/imports (Manager)
+// =========================
+func (ic *CarImportController) ListImports(c *gin.Context) {
+	records, err := ic.svc.List(50)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// =========================
+// GET /cars/imports/:id/result (Manager)
+// ดาวน์โหลดผลรายแถวเป็น CSV
+// =========================
+func (ic *CarImportController) DownloadImportResult(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	record, err := ic.svc.Get(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="car-import-%d-result.csv"`, record.ID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", []byte(record.ResultCSV))
+}
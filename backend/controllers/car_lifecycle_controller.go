@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CarLifecycleController struct {
+	svc *services.CarLifecycleService
+}
+
+func NewCarLifecycleController(db *gorm.DB) *CarLifecycleController {
+	return &CarLifecycleController{svc: services.NewCarLifecycleService(db)}
+}
+
+// =========================
+// GET /cars/:id/lifecycle (Manager)
+// =========================
+func (lc *CarLifecycleController) GetLifecycle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	view, err := lc.svc.Get(uint(id))
+	if err != nil {
+		respondLifecycleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// =========================
+// PUT /cars/:id/lifecycle (Manager)
+// body: {"state": "reserved", "reason": "มัดจำแล้ว"}
+// =========================
+func (lc *CarLifecycleController) SetLifecycle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var body struct {
+		State  string `json:"state" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	view, err := lc.svc.WithContext(c).SetManual(uint(id), c.GetUint("managerID"), body.State, body.Reason)
+	if err != nil {
+		respondLifecycleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// respondLifecycleError ใช้ร่วมกับ controller อื่นที่เปลี่ยนสถานะรถ
+func respondLifecycleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrLifecycleCarNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLifecycleTransition), errors.Is(err, services.ErrCarHasUpcomingRental),
+		errors.Is(err, services.ErrCarNotForSale), errors.Is(err, services.ErrCarNotForRent):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidLifecycleState):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// =========================
+// POST /cars/:id/pictures (Manager)
+// multipart: pictures (หลายไฟล์), titles (เรียงตามไฟล์)
+// =========================
+func (cc *CarController) UploadCarPictures(c *gin.Context) {
+	carID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || carID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := cc.DB.WithContext(c).First(&entity.Car{}, carID).Error; err != nil {
+		respondCarError(c, err)
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	files := form.File["pictures"]
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pictures is required"})
+		return
+	}
+	titles := form.Value["titles"]
+
+	pictures := make([]entity.CarPicture, 0, len(files))
+	for i, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		stored, err := cc.images.Save(file)
+		file.Close()
+		if err != nil {
+			respondImageError(c, header.Filename, err)
+			return
+		}
+
+		title := ""
+		if i < len(titles) {
+			title = titles[i]
+		}
+		pictures = append(pictures, entity.CarPicture{Title: title, Path: stored.Name})
+	}
+
+	pictures, err = cc.inventory.WithContext(c).AddPictures(uint(carID), pictures)
+	if err != nil {
+		respondCarError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, pictures)
+}
+
+// =========================
+// PUT /cars/:id/pictures/order (Manager)
+// body: { "picture_ids": [3, 1, 2] }
+// =========================
+func (cc *CarController) ReorderCarPictures(c *gin.Context) {
+	carID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || carID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var payload struct {
+		PictureIDs []uint `json:"picture_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pictures, err := cc.inventory.WithContext(c).ReorderPictures(uint(carID), payload.PictureIDs)
+	if err != nil {
+		respondCarError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pictures)
+}
+
+// =========================
+// DELETE /cars/:id/pictures/:pictureId (Manager)
+// =========================
+func (cc *CarController) DeleteCarPicture(c *gin.Context) {
+	carID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || carID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	pictureID, err := strconv.Atoi(c.Param("pictureId"))
+	if err != nil || pictureID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid picture id"})
+		return
+	}
+
+	picture, err := cc.inventory.WithContext(c).RemovePicture(uint(carID), uint(pictureID))
+	if err != nil {
+		respondCarError(c, err)
+		return
+	}
+
+	// ไฟล์เดียวกันอาจถูกใช้ในรูปอื่น (ชื่อไฟล์มาจาก hash) ลบเมื่อไม่มีใครอ้างถึงแล้วเท่านั้น
+	var refs int64
+	cc.DB.WithContext(c).Model(&entity.CarPicture{}).Where("path = ?", picture.Path).Count(&refs)
+	if refs == 0 {
+		// รูปรถเทิร์นใช้ไฟล์ในที่เดียวกัน
+		cc.DB.WithContext(c).Model(&entity.TradeInPhoto{}).Where("path = ?", picture.Path).Count(&refs)
+	}
+	if refs == 0 && storage.IsContentAddressed(picture.Path) {
+		if err := cc.images.Delete(picture.Path); err != nil {
+			log.Println("failed to delete car image:", err)
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondImageError(c *gin.Context, filename string, err error) {
+	switch {
+	case errors.Is(err, storage.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": filename + ": " + err.Error()})
+	case errors.Is(err, storage.ErrUnsupportedImage), errors.Is(err, storage.ErrImageTooManyPixels):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": filename + ": " + err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
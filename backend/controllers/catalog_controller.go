@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CatalogController struct {
+	svc *services.CatalogService
+}
+
+func NewCatalogController(db *gorm.DB) *CatalogController {
+	return &CatalogController{svc: services.NewCatalogService(db)}
+}
+
+type catalogNameInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type catalogMergeInput struct {
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+}
+
+func catalogID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /catalog/brands?q=
+// =========================
+func (cc *CatalogController) ListBrands(c *gin.Context) {
+	brands, err := cc.svc.ListBrands(c.Query("q"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, brands)
+}
+
+// =========================
+// GET /catalog/brands/:id/models
+// =========================
+func (cc *CatalogController) ListModels(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	models, err := cc.svc.ListModels(id)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models)
+}
+
+// =========================
+// GET /catalog/models/:id/sub-models
+// =========================
+func (cc *CatalogController) ListSubModels(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	subs, err := cc.svc.ListSubModels(id)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// =========================
+// GET /catalog/duplicates (Manager)
+// =========================
+func (cc *CatalogController) ListDuplicates(c *gin.Context) {
+	groups, err := cc.svc.Duplicates()
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// =========================
+// Brand (Manager)
+// POST /catalog/brands, PUT/DELETE /catalog/brands/:id, POST /catalog/brands/:id/merge
+// =========================
+func (cc *CatalogController) CreateBrand(c *gin.Context) {
+	var in catalogNameInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	brand, err := cc.svc.WithContext(c).CreateBrand(in.Name)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, brand)
+}
+
+func (cc *CatalogController) UpdateBrand(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogNameInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	brand, err := cc.svc.WithContext(c).RenameBrand(id, in.Name)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, brand)
+}
+
+func (cc *CatalogController) DeleteBrand(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	if err := cc.svc.WithContext(c).DeleteBrand(id); err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (cc *CatalogController) MergeBrands(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogMergeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	brand, err := cc.svc.WithContext(c).MergeBrands(id, in.SourceIDs)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, brand)
+}
+
+// =========================
+// CarModel (Manager)
+// POST /catalog/brands/:id/models, PUT/DELETE /catalog/models/:id, POST /catalog/models/:id/merge
+// =========================
+func (cc *CatalogController) CreateModel(c *gin.Context) {
+	brandID, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogNameInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	model, err := cc.svc.WithContext(c).CreateModel(brandID, in.Name)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, model)
+}
+
+func (cc *CatalogController) UpdateModel(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogNameInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	model, err := cc.svc.WithContext(c).RenameModel(id, in.Name)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model)
+}
+
+func (cc *CatalogController) DeleteModel(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	if err := cc.svc.WithContext(c).DeleteModel(id); err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (cc *CatalogController) MergeModels(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogMergeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	model, err := cc.svc.WithContext(c).MergeModels(id, in.SourceIDs)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model)
+}
+
+// =========================
+// SubModel (Manager)
+// POST /catalog/models/:id/sub-models, PUT/DELETE /catalog/sub-models/:id, POST /catalog/sub-models/:id/merge
+// =========================
+func (cc *CatalogController) CreateSubModel(c *gin.Context) {
+	modelID, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogNameInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := cc.svc.WithContext(c).CreateSubModel(modelID, in.Name)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+func (cc *CatalogController) UpdateSubModel(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogNameInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := cc.svc.WithContext(c).RenameSubModel(id, in.Name)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (cc *CatalogController) DeleteSubModel(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	if err := cc.svc.WithContext(c).DeleteSubModel(id); err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (cc *CatalogController) MergeSubModels(c *gin.Context) {
+	id, ok := catalogID(c)
+	if !ok {
+		return
+	}
+	var in catalogMergeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := cc.svc.WithContext(c).MergeSubModels(id, in.SourceIDs)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func respondCatalogError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Catalog entry not found"})
+	case errors.Is(err, services.ErrCatalogDuplicate), errors.Is(err, services.ErrCatalogInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCatalogNameRequired), errors.Is(err, services.ErrCatalogMergeSelf),
+		errors.Is(err, services.ErrCatalogParentNeeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
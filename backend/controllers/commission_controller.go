@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CommissionController struct {
+	svc *services.CommissionService
+}
+
+func NewCommissionController(db *gorm.DB) *CommissionController {
+	return &CommissionController{svc: services.NewCommissionService(db)}
+}
+
+func commissionPathID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /commissions/rules (Manager)
+// =========================
+func (cc *CommissionController) ListRules(c *gin.Context) {
+	rules, err := cc.svc.ListRules()
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// =========================
+// POST /commissions/rules (Manager)
+// =========================
+func (cc *CommissionController) CreateRule(c *gin.Context) {
+	var input services.CommissionRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule, err := cc.svc.WithContext(c).CreateRule(c.GetUint("managerID"), input)
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// =========================
+// PUT /commissions/rules/:id (Manager)
+// =========================
+func (cc *CommissionController) UpdateRule(c *gin.Context) {
+	id, ok := commissionPathID(c)
+	if !ok {
+		return
+	}
+	var input services.CommissionRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule, err := cc.svc.WithContext(c).UpdateRule(id, input)
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// =========================
+// DELETE /commissions/rules/:id (Manager)
+// =========================
+func (cc *CommissionController) DeleteRule(c *gin.Context) {
+	id, ok := commissionPathID(c)
+	if !ok {
+		return
+	}
+	if err := cc.svc.WithContext(c).DeleteRule(id); err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "commission rule deleted"})
+}
+
+// =========================
+// GET /commissions/statements?month=&employee_id=&status= (Manager)
+// =========================
+func (cc *CommissionController) ListStatements(c *gin.Context) {
+	var filter services.CommissionStatementFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	statements, err := cc.svc.ListStatements(filter)
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, statements)
+}
+
+// =========================
+// GET /commissions/statements/:id (Manager)
+// =========================
+func (cc *CommissionController) GetStatement(c *gin.Context) {
+	id, ok := commissionPathID(c)
+	if !ok {
+		return
+	}
+	st, err := cc.svc.GetStatement(id)
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// =========================
+// POST /commissions/statements/generate (Manager)
+// body: {"month": "YYYY-MM"} คำนวณใหม่ได้จนกว่าจะอนุมัติ
+// =========================
+func (cc *CommissionController) GenerateStatements(c *gin.Context) {
+	var body struct {
+		Month string `json:"month" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	statements, err := cc.svc.WithContext(c).Generate(body.Month)
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, statements)
+}
+
+// =========================
+// POST /commissions/statements/:id/approve (Manager)
+// =========================
+func (cc *CommissionController) ApproveStatement(c *gin.Context) {
+	id, ok := commissionPathID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	st, err := cc.svc.WithContext(c).Approve(id, c.GetUint("managerID"), body.Note)
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// =========================
+// GET /employees/me/commissions?month=&status= (Employee)
+// =========================
+func (cc *CommissionController) GetMyStatements(c *gin.Context) {
+	var filter services.CommissionStatementFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.EmployeeID = c.GetUint("employeeID")
+	if filter.EmployeeID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	statements, err := cc.svc.ListStatements(filter)
+	if err != nil {
+		respondCommissionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, statements)
+}
+
+func respondCommissionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCommissionRuleNotFound), errors.Is(err, services.ErrCommissionStatementNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCommissionStatementApproved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCommissionRule), errors.Is(err, services.ErrInvalidCommissionMonth),
+		errors.Is(err, services.ErrInvalidCommissionStatusFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -54,7 +54,7 @@ func (ctrl *CustomerController) RegisterCustomer(c *gin.Context) {
 	}
 	input.Password = string(hashPassword)
 
-	if err := ctrl.DB.Create(&input).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Create(&input).Error; err != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email is already registered"})
 		return
 	}
@@ -74,7 +74,7 @@ func (ctrl *CustomerController) RegisterCustomer(c *gin.Context) {
 func (ctrl *CustomerController) GetCustomerByID(c *gin.Context) {
 	id := c.Param("id")
 	var customer entity.Customer
-	if err := ctrl.DB.First(&customer, id).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).First(&customer, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
 		return
 	}
@@ -91,7 +91,7 @@ func (ctrl *CustomerController) GetCustomerByID(c *gin.Context) {
 // @Router /admin/customers [get]
 func (ctrl *CustomerController) GetAllCustomers(c *gin.Context) {
 	var customers []entity.Customer
-	if err := ctrl.DB.Find(&customers).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Find(&customers).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -115,7 +115,7 @@ func (ctrl *CustomerController) GetAllCustomers(c *gin.Context) {
 func (ctrl *CustomerController) UpdateCustomer(c *gin.Context) {
 	id := c.Param("id")
 	var customer entity.Customer
-	if err := ctrl.DB.First(&customer, id).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).First(&customer, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
 		return
 	}
@@ -132,7 +132,7 @@ func (ctrl *CustomerController) UpdateCustomer(c *gin.Context) {
 	customer.Phone = input.Phone
 	customer.Birthday = input.Birthday
 
-	if err := ctrl.DB.Save(&customer).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Save(&customer).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -152,7 +152,7 @@ func (ctrl *CustomerController) UpdateCustomer(c *gin.Context) {
 // @Router /admin/customers/{id} [delete]
 func (ctrl *CustomerController) DeleteCustomer(c *gin.Context) {
 	id := c.Param("id")
-	if err := ctrl.DB.Delete(&entity.Customer{}, id).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Delete(&entity.Customer{}, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
 		return
 	}
@@ -183,7 +183,7 @@ func (ctrl *CustomerController) LoginCustomer(c *gin.Context) {
 	}
 
 	var customer entity.Customer
-	if err := ctrl.DB.Where("email = ?", loginInfo.Email).First(&customer).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Where("email = ?", loginInfo.Email).First(&customer).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
@@ -230,7 +230,7 @@ func (ctrl *CustomerController) GetCurrentCustomer(c *gin.Context) {
 	}
 
 	var customer entity.Customer
-	if err := ctrl.DB.First(&customer, userID).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).First(&customer, userID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
 		return
 	}
@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -40,27 +42,39 @@ func (bc *BuyCarController) BuyCar(c *gin.Context) {
 
 	// 2. หา SaleList ของ car ที่ status = "Available"
 	var sale entity.SaleList
-	if err := bc.DB.Where("car_id = ? AND status = ?", carID, "Available").First(&sale).Error; err != nil {
+	if err := bc.DB.WithContext(c).Where("car_id = ? AND status = ?", carID, entity.SaleListAvailable).First(&sale).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่มีรถที่พร้อมขาย"})
 		return
 	}
 
+	// รถที่อยู่อู่ยังขายไม่ได้
+	blocked, err := services.CarInMaintenance(bc.DB.WithContext(c), sale.CarID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if blocked {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrCarInMaintenance.Error()})
+		return
+	}
+
 	// 3. สร้าง SalesContract
 	contract := entity.SalesContract{
 		SaleListID: sale.ID,
 		EmployeeID: *sale.EmployeeID, // ต้อง check pointer ด้วย
 		CustomerID: payload.CustomerID,
+		SalePrice:  sale.SalePrice,
 	}
 
-	if err := bc.DB.Create(&contract).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้างสัญญาซื้อขายไม่สำเร็จ"})
-		return
-	}
-
-	// 4. อัปเดต SaleList.Status
-	sale.Status = "Sold"
-	if err := bc.DB.Save(&sale).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "อัปเดตสถานะรถไม่สำเร็จ"})
+	// 4. เปลี่ยนสถานะรถเป็นขายแล้ว (ปิด SaleList ให้ด้วย) ใน transaction เดียวกับสัญญา
+	err = bc.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&contract).Error; err != nil {
+			return err
+		}
+		return services.TransitionCar(tx, sale.CarID, entity.CarSold, fmt.Sprintf("sales contract %d", contract.ID), nil)
+	})
+	if err != nil {
+		respondLifecycleError(c, err)
 		return
 	}
 
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DocumentController struct {
+	svc     *services.DocumentService
+	backend storage.Backend
+}
+
+func NewDocumentController(db *gorm.DB, backend storage.Backend) *DocumentController {
+	return &DocumentController{svc: services.NewDocumentService(db, backend), backend: backend}
+}
+
+// =========================
+// POST /documents/:kind/:id (Staff)
+// kind: sales-contracts | rent-contracts | receipts | car-expenses, multipart: file
+// =========================
+func (dc *DocumentController) UploadDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	key, err := dc.svc.WithContext(c).Attach(c.Param("kind"), uint(id), file)
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"document_key": key})
+}
+
+// =========================
+// GET /documents/:kind/:id/url (Staff)
+// คืนลิงก์ดาวน์โหลดที่หมดอายุใน 15 นาที
+// =========================
+func (dc *DocumentController) GetDocumentURL(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	signed, err := dc.svc.SignedURL(c.Param("kind"), uint(id))
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, signed)
+}
+
+// =========================
+// GET /files/*key?expires=&signature=
+// เสิร์ฟไฟล์จาก local storage ตามลิงก์ที่เซ็นแล้ว (S3 ใช้ presigned URL ของ bucket เอง)
+// =========================
+func (dc *DocumentController) ServeSignedFile(c *gin.Context) {
+	local, ok := dc.backend.(*storage.LocalBackend)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !local.VerifySignature(key, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "link is invalid or has expired"})
+		return
+	}
+
+	rc, info, err := local.Get(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rc.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, key, info.ModTime, rs)
+		return
+	}
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, rc, nil)
+}
+
+func respondDocumentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrDocumentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+	case errors.Is(err, services.ErrUnknownDocumentKind):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDocumentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnsupportedDocument):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
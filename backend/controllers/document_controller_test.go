@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeSignedFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	backend := storage.NewLocalBackend(t.TempDir(), "/files", []byte("signing-key"))
+	key := "rent-contracts/5/contract.pdf"
+	if err := backend.Put(ctx, key, strings.NewReader("%PDF-1.4 rent"), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/files/*key", NewDocumentController(nil, backend).ServeSignedFile)
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	signed, err := backend.SignedURL(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := get(signed)
+	if w.Code != http.StatusOK || w.Body.String() != "%PDF-1.4 rent" {
+		t.Fatalf("signed link: %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "private, no-store" {
+		t.Errorf("Cache-Control = %q", w.Header().Get("Cache-Control"))
+	}
+
+	u, _ := url.Parse(signed)
+	if w := get(u.Path); w.Code != http.StatusForbidden {
+		t.Errorf("unsigned link: got %d, want 403", w.Code)
+	}
+
+	q := u.Query()
+	q.Set("signature", strings.Repeat("a", len(q.Get("signature"))))
+	if w := get(u.Path + "?" + q.Encode()); w.Code != http.StatusForbidden {
+		t.Errorf("tampered signature: got %d, want 403", w.Code)
+	}
+
+	other := "/files/rent-contracts/6/contract.pdf?" + u.RawQuery
+	if w := get(other); w.Code != http.StatusForbidden {
+		t.Errorf("signature reused for another key: got %d, want 403", w.Code)
+	}
+
+	expired, err := backend.SignedURL(ctx, key, -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := get(expired); w.Code != http.StatusForbidden {
+		t.Errorf("expired link: got %d, want 403", w.Code)
+	}
+
+	if err := backend.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(signed); w.Code != http.StatusNotFound {
+		t.Errorf("deleted file: got %d, want 404", w.Code)
+	}
+}
@@ -28,9 +28,14 @@ func NewEmployeeController(db *gorm.DB) *EmployeeController {
 // 📌 Public Endpoints
 // ===========================
 
-// GET /employees
+// GET /employees?q=&position=&job_type=
 func (ctl *EmployeeController) GetEmployees(c *gin.Context) {
-	items, err := ctl.svc.List()
+	var filter services.EmployeeFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	items, err := ctl.svc.List(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch employees"})
 		return
@@ -69,7 +74,7 @@ func (ctl *EmployeeController) LoginEmployee(c *gin.Context) {
 		return
 	}
 
-	emp, err := ctl.svc.GetByEmail(body.Email)
+	emp, err := ctl.svc.WithContext(c).GetByEmail(body.Email)
 	if err != nil || bcrypt.CompareHashAndPassword([]byte(emp.Password), []byte(body.Password)) != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
 		return
@@ -131,7 +136,7 @@ func (ctl *EmployeeController) UpdateCurrentEmployee(c *gin.Context) {
 		return
 	}
 
-	updated, err := ctl.svc.Update(id, map[string]any{
+	updated, err := ctl.svc.WithContext(c).Update(id, map[string]any{
 		"profile_image": emp.ProfileImage,
 		"first_name":    emp.FirstName,
 		"last_name":     emp.LastName,
@@ -141,7 +146,6 @@ func (ctl *EmployeeController) UpdateCurrentEmployee(c *gin.Context) {
 		"sex":           emp.Sex,
 		"position":      emp.Position,
 		"job_type":      emp.JobType,
-		"total_sales":   emp.TotalSales,
 		"birthday":      emp.Birthday,
 	})
 	if err != nil {
@@ -169,7 +173,7 @@ func (ctl *EmployeeController) CreateEmployee(c *gin.Context) {
 		emp.Password = string(hash)
 	}
 
-	if err := ctl.svc.Create(&emp); err != nil {
+	if err := ctl.svc.WithContext(c).Create(&emp); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -191,7 +195,7 @@ func (ctl *EmployeeController) UpdateEmployeeByID(c *gin.Context) {
 		return
 	}
 
-	updated, err := ctl.svc.Update(uint(idInt), map[string]any{
+	updated, err := ctl.svc.WithContext(c).Update(uint(idInt), map[string]any{
 		"profile_image": emp.ProfileImage,
 		"first_name":    emp.FirstName,
 		"last_name":     emp.LastName,
@@ -201,7 +205,6 @@ func (ctl *EmployeeController) UpdateEmployeeByID(c *gin.Context) {
 		"sex":           emp.Sex,
 		"position":      emp.Position,
 		"job_type":      emp.JobType,
-		"total_sales":   emp.TotalSales,
 		"birthday":      emp.Birthday,
 	})
 	if err != nil {
@@ -219,7 +222,7 @@ func (ctl *EmployeeController) DeleteEmployeeByID(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	if err := ctl.svc.Delete(uint(idInt)); err != nil {
+	if err := ctl.svc.WithContext(c).Delete(uint(idInt)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete employee"})
 		return
 	}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/export"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ExportController struct {
+	db  *gorm.DB
+	svc *services.ExportService
+}
+
+func NewExportController(db *gorm.DB) *ExportController {
+	return &ExportController{db: db, svc: services.NewExportService(db)}
+}
+
+// =========================
+// GET /exports/cars?format=csv|xlsx&<filter เหมือน GET /cars> (Manager)
+// =========================
+func (ec *ExportController) ExportCars(c *gin.Context) {
+	var filter services.CarFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ec.stream(c, "cars", services.ValidateCarSort(filter.Sort), func(w export.Writer) error {
+		return ec.svc.Cars(filter, w)
+	})
+}
+
+// =========================
+// GET /exports/sales-contracts?format=&employee_id=&customer_id=&date_from=&date_to= (Manager)
+// =========================
+func (ec *ExportController) ExportSalesContracts(c *gin.Context) {
+	var filter services.SalesContractFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, err := filter.Apply(ec.db)
+	ec.stream(c, "sales-contracts", err, func(w export.Writer) error {
+		return ec.svc.SalesContracts(filter, w)
+	})
+}
+
+// =========================
+// GET /exports/rent-contracts?format=&customer_id=&car_id=&date_from=&date_to= (Manager)
+// =========================
+func (ec *ExportController) ExportRentContracts(c *gin.Context) {
+	var filter services.RentContractFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, err := filter.Apply(ec.db)
+	ec.stream(c, "rent-contracts", err, func(w export.Writer) error {
+		return ec.svc.RentContracts(filter, w)
+	})
+}
+
+// =========================
+// GET /exports/payments?format=&status=&customer_id=&employee_id=&contract=&date_from=&date_to= (Manager)
+// =========================
+func (ec *ExportController) ExportPayments(c *gin.Context) {
+	var filter services.PaymentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, err := filter.Apply(ec.db)
+	ec.stream(c, "payments", err, func(w export.Writer) error {
+		return ec.svc.Payments(filter, w)
+	})
+}
+
+// =========================
+// GET /exports/employees?format=&q=&position=&job_type= (Manager)
+// =========================
+func (ec *ExportController) ExportEmployees(c *gin.Context) {
+	var filter services.EmployeeFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ec.stream(c, "employees", nil, func(w export.Writer) error {
+		return ec.svc.Employees(filter, w)
+	})
+}
+
+// stream ตรวจ format และ filter ก่อนเริ่มส่งไฟล์ (หลังเริ่มส่งแล้วจะเปลี่ยน status เป็น error ไม่ได้)
+func (ec *ExportController) stream(c *gin.Context, name string, filterErr error, write func(export.Writer) error) {
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatCSV))
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": export.ErrUnsupportedFormat.Error()})
+		return
+	}
+	if errors.Is(filterErr, services.ErrInvalidDateFilter) || errors.Is(filterErr, services.ErrInvalidSort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": filterErr.Error()})
+		return
+	}
+	if filterErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": filterErr.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), export.FileExtension(format))
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, c.Writer, name)
+	if err == nil {
+		err = write(w)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		// ส่ง header ไปแล้ว แจ้ง error ได้เพียงใน log และตัดการเชื่อมต่อ
+		log.Printf("export %s failed: %v", name, err)
+		c.Abort()
+	}
+}
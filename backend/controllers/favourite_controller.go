@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FavouriteController struct {
+	svc *services.FavouriteService
+}
+
+func NewFavouriteController(db *gorm.DB) *FavouriteController {
+	return &FavouriteController{svc: services.NewFavouriteService(db)}
+}
+
+func favouritePathID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /customers/me/favourites (Customer)
+// รูปแบบเดียวกับ GET /cars เรียงตามที่ถูกใจล่าสุด
+// =========================
+func (fc *FavouriteController) ListFavourites(c *gin.Context) {
+	cars, err := fc.svc.Cars(c.GetUint("userID"))
+	if err != nil {
+		respondFavouriteError(c, err)
+		return
+	}
+	resp := make([]entity.CarResponse, 0, len(cars))
+	for _, car := range cars {
+		r := mapCarToResponse(car)
+		r.IsFavourite = true
+		resp = append(resp, r)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// =========================
+// PUT /customers/me/favourites/:carId (Customer)
+// =========================
+func (fc *FavouriteController) AddFavourite(c *gin.Context) {
+	carID, ok := favouritePathID(c, "carId")
+	if !ok {
+		return
+	}
+	if err := fc.svc.WithContext(c).Add(c.GetUint("userID"), carID); err != nil {
+		respondFavouriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"car_id": carID, "is_favourite": true})
+}
+
+// =========================
+// DELETE /customers/me/favourites/:carId (Customer)
+// =========================
+func (fc *FavouriteController) RemoveFavourite(c *gin.Context) {
+	carID, ok := favouritePathID(c, "carId")
+	if !ok {
+		return
+	}
+	if err := fc.svc.WithContext(c).Remove(c.GetUint("userID"), carID); err != nil {
+		respondFavouriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"car_id": carID, "is_favourite": false})
+}
+
+// =========================
+// GET /customers/me/saved-searches (Customer)
+// =========================
+func (fc *FavouriteController) ListSavedSearches(c *gin.Context) {
+	searches, err := fc.svc.Searches(c.GetUint("userID"))
+	if err != nil {
+		respondFavouriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, searches)
+}
+
+// =========================
+// POST /customers/me/saved-searches (Customer)
+// body: {"name": "SUV ไม่เกิน 8 แสน", "filter": {"price_max": 800000, "sort": "priceAsc"}}
+// =========================
+func (fc *FavouriteController) CreateSavedSearch(c *gin.Context) {
+	var input services.SavedSearchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	search, err := fc.svc.WithContext(c).CreateSearch(c.GetUint("userID"), input)
+	if err != nil {
+		respondFavouriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, search)
+}
+
+// =========================
+// PUT /customers/me/saved-searches/:id (Customer)
+// =========================
+func (fc *FavouriteController) UpdateSavedSearch(c *gin.Context) {
+	id, ok := favouritePathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.SavedSearchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	search, err := fc.svc.WithContext(c).UpdateSearch(c.GetUint("userID"), id, input)
+	if err != nil {
+		respondFavouriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, search)
+}
+
+// =========================
+// DELETE /customers/me/saved-searches/:id (Customer)
+// =========================
+func (fc *FavouriteController) DeleteSavedSearch(c *gin.Context) {
+	id, ok := favouritePathID(c, "id")
+	if !ok {
+		return
+	}
+	if err := fc.svc.WithContext(c).DeleteSearch(c.GetUint("userID"), id); err != nil {
+		respondFavouriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "saved search deleted"})
+}
+
+func respondFavouriteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrFavouriteCarNotFound), errors.Is(err, services.ErrFavouriteNotFound),
+		errors.Is(err, services.ErrSavedSearchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSavedSearchLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidSavedSearch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
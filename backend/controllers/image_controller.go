@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+	"github.com/gin-gonic/gin"
+)
+
+type ImageController struct {
+	store *storage.ImageStore
+}
+
+func NewImageController(backend storage.Backend) *ImageController {
+	return &ImageController{store: storage.NewImageStore(backend, storage.CarImagePrefix)}
+}
+
+// GET /images/cars/:name?size=web|thumb
+func (ctrl *ImageController) ServeCarImage(c *gin.Context) {
+	original := c.Param("name")
+	size := c.Query("size")
+	if size != storage.VariantOriginal && size != storage.VariantWeb && size != storage.VariantThumb {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "size must be web or thumb"})
+		return
+	}
+
+	name := storage.VariantName(original, size)
+	rc, info, err := ctrl.store.Open(name)
+	if errors.Is(err, storage.ErrImageNotFound) && name != original {
+		// ยังไม่มีขนาดย่อย ส่งต้นฉบับแทน
+		name = original
+		rc, info, err = ctrl.store.Open(name)
+	}
+	switch {
+	case errors.Is(err, storage.ErrInvalidImageName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, storage.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rc.Close()
+
+	// ชื่อที่มาจาก hash ของเนื้อไฟล์ไม่มีวันเปลี่ยนเนื้อหา จึงแคชได้ถาวร
+	if storage.IsContentAddressed(name) {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Header("ETag", `"`+name+`"`)
+	} else {
+		c.Header("Cache-Control", "public, max-age=86400")
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	// local ได้ไฟล์ที่ seek ได้ (รองรับ Range/If-Modified-Since) ส่วน S3 ส่งต่อเนื้อไฟล์ตรงๆ
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, name, info.ModTime, rs)
+		return
+	}
+	if etag := c.Writer.Header().Get("ETag"); etag != "" && c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	c.DataFromReader(http.StatusOK, info.Size, contentType, rc, nil)
+}
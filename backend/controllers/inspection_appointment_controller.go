@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -36,7 +37,7 @@ func (ctrl *InspectionAppointmentController) CreateInspectionAppointment(c *gin.
 	}
 
 	// เริ่ม Transaction
-	tx := ctrl.DB.Begin()
+	tx := ctrl.DB.WithContext(c).Begin()
 
 	// 1. สร้าง InspectionAppointment หลัก
 	appointment := entity.InspectionAppointment{
@@ -66,6 +67,22 @@ func (ctrl *InspectionAppointmentController) CreateInspectionAppointment(c *gin.
 		}
 	}
 
+	// 3. แจ้งลูกค้าว่านัดตรวจสภาพแล้ว
+	err := services.Notify(tx, services.NotificationInput{
+		Role:   entity.AuditRoleCustomer,
+		UserID: appointment.CustomerID,
+		Event:  entity.NotificationInspectionScheduled,
+		Data: map[string]interface{}{
+			"SalesContractID": appointment.SalesContractID,
+			"DateTime":        appointment.DateTime,
+		},
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification: " + err.Error()})
+		return
+	}
+
 	// ยืนยัน Transaction
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
@@ -75,7 +92,7 @@ func (ctrl *InspectionAppointmentController) CreateInspectionAppointment(c *gin.
 	
 	// ดึงข้อมูลที่สร้างเสร็จสมบูรณ์กลับไป (เพื่อให้มีข้อมูล InspectionSystem.CarSystem แสดงด้วย)
     var createdAppointment entity.InspectionAppointment
-    ctrl.DB.Preload("Customer").
+    ctrl.DB.WithContext(c).Preload("Customer").
 		Preload("SalesContract").
 		Preload("InspectionSystem.CarSystem").
 		First(&createdAppointment, appointment.ID)
@@ -132,7 +149,7 @@ func (ctrl *InspectionAppointmentController) GetInspectionAppointmentsByCustomer
 func (ctrl *InspectionAppointmentController) UpdateInspectionAppointment(c *gin.Context) {
 	id := c.Param("id")
 	var appointment entity.InspectionAppointment
-	if err := ctrl.DB.First(&appointment, id).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).First(&appointment, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Inspection appointment not found"})
 		return
 	}
@@ -143,7 +160,7 @@ func (ctrl *InspectionAppointmentController) UpdateInspectionAppointment(c *gin.
 		return
 	}
 
-	if err := ctrl.DB.Model(&appointment).Updates(input).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Model(&appointment).Updates(input).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -154,7 +171,7 @@ func (ctrl *InspectionAppointmentController) UpdateInspectionAppointment(c *gin.
 func (ctrl *InspectionAppointmentController) UpdateInspectionAppointmentStatus(c *gin.Context) {
 	id := c.Param("id")
 	var appointment entity.InspectionAppointment
-	if err := ctrl.DB.First(&appointment, id).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).First(&appointment, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Inspection appointment not found"})
 		return
 	}
@@ -167,7 +184,7 @@ func (ctrl *InspectionAppointmentController) UpdateInspectionAppointmentStatus(c
 		return
 	}
 
-	if err := ctrl.DB.Model(&appointment).Update("inspection_status", input.InspectionStatus).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Model(&appointment).Update("inspection_status", input.InspectionStatus).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -177,7 +194,7 @@ func (ctrl *InspectionAppointmentController) UpdateInspectionAppointmentStatus(c
 // DELETE /inspection-appointments/:id
 func (ctrl *InspectionAppointmentController) DeleteInspectionAppointment(c *gin.Context) {
 	id := c.Param("id")
-	if err := ctrl.DB.Delete(&entity.InspectionAppointment{}, id).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Delete(&entity.InspectionAppointment{}, id).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Delete failed"})
 		return
 	}
@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LeadController struct {
+	svc *services.LeadService
+}
+
+func NewLeadController(db *gorm.DB) *LeadController {
+	return &LeadController{svc: services.NewLeadService(db)}
+}
+
+// staffActor ผู้เรียกจาก StaffAuthMiddleware
+func staffActor(c *gin.Context) services.StaffActor {
+	return services.StaffActor{EmployeeID: c.GetUint("employeeID"), ManagerID: c.GetUint("managerID")}
+}
+
+func leadPathID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /leads?stage=&employee_id=&customer_id=&open=true (Staff)
+// พนักงานเห็นเฉพาะ lead ที่ตัวเองดูแล
+// =========================
+func (lc *LeadController) ListLeads(c *gin.Context) {
+	var filter services.LeadFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	leads, err := lc.svc.List(staffActor(c), filter)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, leads)
+}
+
+// =========================
+// POST /leads (Staff)
+// =========================
+func (lc *LeadController) CreateLead(c *gin.Context) {
+	var input services.LeadInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lead, err := lc.svc.WithContext(c).Create(staffActor(c), input)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, lead)
+}
+
+// =========================
+// GET /leads/:id (Staff)
+// =========================
+func (lc *LeadController) GetLead(c *gin.Context) {
+	id, ok := leadPathID(c, "id")
+	if !ok {
+		return
+	}
+	lead, err := lc.svc.Get(staffActor(c), id)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lead)
+}
+
+// =========================
+// PUT /leads/:id (Staff)
+// =========================
+func (lc *LeadController) UpdateLead(c *gin.Context) {
+	id, ok := leadPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.LeadInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lead, err := lc.svc.WithContext(c).Update(staffActor(c), id, input)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lead)
+}
+
+// =========================
+// PUT /leads/:id/stage (Staff)
+// body: {"stage": "contacted", "reason": "..."}
+// =========================
+func (lc *LeadController) ChangeStage(c *gin.Context) {
+	id, ok := leadPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.LeadStageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lead, err := lc.svc.WithContext(c).ChangeStage(staffActor(c), id, input)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lead)
+}
+
+// =========================
+// POST /leads/:id/activities (Staff)
+// =========================
+func (lc *LeadController) AddActivity(c *gin.Context) {
+	id, ok := leadPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.LeadActivityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	activity, err := lc.svc.WithContext(c).AddActivity(staffActor(c), id, input)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, activity)
+}
+
+// =========================
+// POST /leads/:id/tasks (Staff)
+// =========================
+func (lc *LeadController) AddTask(c *gin.Context) {
+	id, ok := leadPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.LeadTaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	task, err := lc.svc.WithContext(c).AddTask(staffActor(c), id, input)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, task)
+}
+
+// =========================
+// POST /leads/:id/tasks/:taskId/complete (Staff)
+// =========================
+func (lc *LeadController) CompleteTask(c *gin.Context) {
+	id, ok := leadPathID(c, "id")
+	if !ok {
+		return
+	}
+	taskID, ok := leadPathID(c, "taskId")
+	if !ok {
+		return
+	}
+	task, err := lc.svc.WithContext(c).CompleteTask(staffActor(c), id, taskID)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// =========================
+// GET /leads/tasks?employee_id=&open=true&due_before=YYYY-MM-DD (Staff)
+// =========================
+func (lc *LeadController) ListTasks(c *gin.Context) {
+	var filter services.LeadTaskFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tasks, err := lc.svc.Tasks(staffActor(c), filter)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// =========================
+// POST /leads/:id/convert (Staff)
+// body: {"sales_contract_id": 1} หรือ {"rent_contract_id": 1}
+// =========================
+func (lc *LeadController) ConvertLead(c *gin.Context) {
+	id, ok := leadPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.LeadConvertInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lead, err := lc.svc.WithContext(c).Convert(staffActor(c), id, input)
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lead)
+}
+
+// =========================
+// GET /reports/leads?date_from=&date_to= (Manager)
+// =========================
+func (lc *LeadController) GetLeadStats(c *gin.Context) {
+	stats, err := lc.svc.Stats(c.Query("date_from"), c.Query("date_to"))
+	if err != nil {
+		respondLeadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func respondLeadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrLeadNotFound), errors.Is(err, services.ErrLeadTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLeadClosed), errors.Is(err, services.ErrLeadTaskCompleted),
+		errors.Is(err, services.ErrLeadContractLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidLead), errors.Is(err, services.ErrInvalidLeadStage),
+		errors.Is(err, services.ErrLeadContractMismatch), errors.Is(err, services.ErrInvalidDateFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,11 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/PanuAutawo/CarTentManagement/backend/entity"
 	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"gorm.io/gorm"
 )
@@ -18,10 +19,10 @@ func NewLeaveController(db *gorm.DB) *LeaveController {
 	return &LeaveController{svc: services.NewLeaveService(db)}
 }
 
-// GET /api/leaves?status=pending
+// GET /api/leaves?status=pending (Staff) พนักงานเห็นเฉพาะคำขอของตัวเอง
 func (ctl *LeaveController) ListLeaves(c *gin.Context) {
 	status := c.Query("status")
-	items, err := ctl.svc.List(status)
+	items, err := ctl.svc.List(status, c.GetUint("employeeID"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -29,7 +30,7 @@ func (ctl *LeaveController) ListLeaves(c *gin.Context) {
 	c.JSON(http.StatusOK, items)
 }
 
-// GET /api/employees/:id/leaves
+// GET /api/employees/:id/leaves (Staff) พนักงานดูได้เฉพาะของตัวเอง
 func (ctl *LeaveController) ListLeavesByEmployee(c *gin.Context) {
 	idStr := c.Param("id")
 	idInt, err := strconv.Atoi(idStr)
@@ -37,6 +38,10 @@ func (ctl *LeaveController) ListLeavesByEmployee(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
 		return
 	}
+	if self, isEmployee := c.Get("employeeID"); isEmployee && self.(uint) != uint(idInt) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
 	items, err := ctl.svc.ListByEmployee(uint(idInt))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -45,33 +50,114 @@ func (ctl *LeaveController) ListLeavesByEmployee(c *gin.Context) {
 	c.JSON(http.StatusOK, items)
 }
 
-// POST /api/leaves
+// POST /api/leaves (Employee) ลาให้ตัวเองเท่านั้น
 func (ctl *LeaveController) CreateLeave(c *gin.Context) {
-	var body entity.LeaveRequest
+	var body services.LeaveInput
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := ctl.svc.Create(&body); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	leave, err := ctl.svc.WithContext(c).Create(c.GetUint("employeeID"), body)
+	if err != nil {
+		respondLeaveError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, body)
+	c.JSON(http.StatusCreated, leave)
 }
 
-// PUT /api/leaves/:id/status
+// PUT /api/leaves/:id/status (Manager)
+// body: {"status": "approved"|"denied", "comment": "..."} พิจารณาได้เฉพาะคำขอที่รออยู่
 func (ctl *LeaveController) UpdateLeaveStatus(c *gin.Context) {
-	id := c.Param("id")
-	var payload struct {
-		Status string `json:"status"`
-	}
-	if err := c.ShouldBindJSON(&payload); err != nil || payload.Status == "" {
+	var payload services.LeaveDecision
+	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "status is required"})
 		return
 	}
-	if err := ctl.svc.UpdateStatus(id, payload.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	leave, err := ctl.svc.WithContext(c).Decide(c.Param("id"), c.GetUint("managerID"), payload)
+	if err != nil {
+		respondLeaveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, leave)
+}
+
+// GET /api/employees/:id/leave-balances?year= (Staff) พนักงานดูได้เฉพาะของตัวเอง
+func (ctl *LeaveController) GetLeaveBalances(c *gin.Context) {
+	empID, year, ok := leaveBalanceParams(c, c.Query("year"))
+	if !ok {
+		return
+	}
+	if self, isEmployee := c.Get("employeeID"); isEmployee && self.(uint) != empID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+	// Balances สร้างแถวยอดวันลาของปีที่ยังไม่มี จึงต้องส่ง context ให้ audit log
+	balances, err := ctl.svc.WithContext(c).Balances(empID, year)
+	if err != nil {
+		respondLeaveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balances)
+}
+
+// PUT /api/employees/:id/leave-balances (Manager)
+// body: {"year": 2025, "type": "vacation", "entitled": 10}
+func (ctl *LeaveController) SetLeaveBalance(c *gin.Context) {
+	var body struct {
+		Year     int    `json:"year"`
+		Type     string `json:"type" binding:"required"`
+		Entitled int    `json:"entitled"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	empID, year, ok := leaveBalanceParams(c, strconv.Itoa(body.Year))
+	if !ok {
 		return
 	}
-	c.Status(http.StatusNoContent)
+	balance, err := ctl.svc.WithContext(c).SetEntitlement(empID, year, body.Type, body.Entitled)
+	if err != nil {
+		respondLeaveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+// อ่าน :id และปี (ไม่ระบุหรือ 0 = ปีปัจจุบัน)
+func leaveBalanceParams(c *gin.Context, yearStr string) (uint, int, bool) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil || idInt <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return 0, 0, false
+	}
+	year := time.Now().Year()
+	if yearStr != "" && yearStr != "0" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil || y < 2000 || y > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return 0, 0, false
+		}
+		year = y
+	}
+	return uint(idInt), year, true
+}
+
+func respondLeaveError(c *gin.Context, err error) {
+	var parseErr *time.ParseError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+	case errors.Is(err, services.ErrLeaveNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLeaveOverlap), errors.Is(err, services.ErrLeaveAlreadyDecided),
+		errors.Is(err, services.ErrInsufficientLeaveBalance):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidLeaveType), errors.Is(err, services.ErrInvalidLeaveRange),
+		errors.Is(err, services.ErrLeaveCrossesYear), errors.Is(err, services.ErrInvalidLeaveStatus),
+		errors.Is(err, services.ErrInvalidLeaveEntitlement), errors.As(err, &parseErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MaintenanceController struct {
+	svc *services.MaintenanceService
+}
+
+func NewMaintenanceController(db *gorm.DB) *MaintenanceController {
+	return &MaintenanceController{svc: services.NewMaintenanceService(db)}
+}
+
+func maintenancePathID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /cars/:id/maintenance (Manager)
+// =========================
+func (mc *MaintenanceController) ListCarMaintenance(c *gin.Context) {
+	carID, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	records, err := mc.svc.ListForCar(carID)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// =========================
+// POST /cars/:id/maintenance (Manager)
+// body: {"service_type": "oil_change", "garage": "...", "planned_start": "2025-01-10", "planned_end": "2025-01-11", "odometer": 52000, "cost": 2500}
+// =========================
+func (mc *MaintenanceController) CreateMaintenance(c *gin.Context) {
+	carID, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	var input services.MaintenanceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rec, err := mc.svc.WithContext(c).Create(carID, c.GetUint("managerID"), input)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rec)
+}
+
+// =========================
+// GET /maintenance?car_id=&status=&service_type=&date_from=&date_to= (Manager)
+// =========================
+func (mc *MaintenanceController) ListMaintenance(c *gin.Context) {
+	var filter services.MaintenanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	records, err := mc.svc.List(filter)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// =========================
+// GET /maintenance/:id (Manager)
+// =========================
+func (mc *MaintenanceController) GetMaintenance(c *gin.Context) {
+	id, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	rec, err := mc.svc.Get(id)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// =========================
+// PUT /maintenance/:id (Manager)
+// =========================
+func (mc *MaintenanceController) UpdateMaintenance(c *gin.Context) {
+	id, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	var input services.MaintenanceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rec, err := mc.svc.WithContext(c).Update(id, input)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// =========================
+// POST /maintenance/:id/start (Manager)
+// =========================
+func (mc *MaintenanceController) StartMaintenance(c *gin.Context) {
+	id, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	rec, err := mc.svc.WithContext(c).Start(id)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// =========================
+// POST /maintenance/:id/complete (Manager)
+// body (ไม่บังคับ): {"cost": 2800, "odometer": 52010, "completed_at": "2025-01-11", "note": "..."}
+// =========================
+func (mc *MaintenanceController) CompleteMaintenance(c *gin.Context) {
+	id, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	var input services.MaintenanceCompleteInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	rec, err := mc.svc.WithContext(c).Complete(id, c.GetUint("managerID"), input)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// =========================
+// POST /maintenance/:id/cancel (Manager)
+// body (ไม่บังคับ): {"reason": "..."}
+// =========================
+func (mc *MaintenanceController) CancelMaintenance(c *gin.Context) {
+	id, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	rec, err := mc.svc.WithContext(c).Cancel(id, body.Reason)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// =========================
+// GET /cars/:id/service-reminders (Manager)
+// =========================
+func (mc *MaintenanceController) ListCarReminders(c *gin.Context) {
+	carID, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	reminders, err := mc.svc.CarReminders(carID)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminders)
+}
+
+// =========================
+// POST /cars/:id/service-reminders (Manager)
+// body: {"service_type": "oil_change", "interval_km": 10000, "interval_months": 6, "last_service_at": "2025-01-11", "last_odometer": 52010}
+// =========================
+func (mc *MaintenanceController) CreateReminder(c *gin.Context) {
+	carID, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	var input services.ServiceReminderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reminder, err := mc.svc.WithContext(c).CreateReminder(carID, input)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// =========================
+// GET /service-reminders/due (Manager)
+// รอบบริการที่ใกล้ถึง (30 วัน / 1,000 กม.) หรือเลยกำหนดแล้ว
+// =========================
+func (mc *MaintenanceController) ListDueReminders(c *gin.Context) {
+	reminders, err := mc.svc.DueReminders()
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminders)
+}
+
+// =========================
+// PUT /service-reminders/:id (Manager)
+// =========================
+func (mc *MaintenanceController) UpdateReminder(c *gin.Context) {
+	id, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	var input services.ServiceReminderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reminder, err := mc.svc.WithContext(c).UpdateReminder(id, input)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminder)
+}
+
+// =========================
+// DELETE /service-reminders/:id (Manager)
+// =========================
+func (mc *MaintenanceController) DeleteReminder(c *gin.Context) {
+	id, ok := maintenancePathID(c)
+	if !ok {
+		return
+	}
+	if err := mc.svc.WithContext(c).DeleteReminder(id); err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "service reminder deleted"})
+}
+
+func respondMaintenanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMaintenanceNotFound), errors.Is(err, services.ErrMaintenanceCarNotFound),
+		errors.Is(err, services.ErrServiceReminderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMaintenanceState), errors.Is(err, services.ErrMaintenanceConflict),
+		errors.Is(err, services.ErrLifecycleTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidMaintenance), errors.Is(err, services.ErrInvalidServiceReminder),
+		errors.Is(err, services.ErrInvalidDateFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -32,7 +32,7 @@ func (ctrl *ManagerController) LoginManager(c *gin.Context) {
 	}
 
 	var manager entity.Manager
-	if err := ctrl.DB.Where("Email = ?", input.Email).First(&manager).Error; err != nil {
+	if err := ctrl.DB.WithContext(c).Where("Email = ?", input.Email).First(&manager).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
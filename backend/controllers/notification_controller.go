@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/notify"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationController struct {
+	svc *services.NotificationService
+}
+
+func NewNotificationController(db *gorm.DB, channels map[string]notify.Channel) *NotificationController {
+	return &NotificationController{svc: services.NewNotificationService(db, channels)}
+}
+
+// ผู้ใช้จาก AnyUserAuthMiddleware
+func notificationAccount(c *gin.Context) (string, uint) {
+	return c.GetString("accountRole"), c.GetUint("accountID")
+}
+
+func notificationPathID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /notifications?unread=true&event=&page=&limit= (Customer/Employee/Manager)
+// X-Total-Count = จำนวนตามตัวกรอง, X-Unread-Count = ยังไม่อ่านทั้งหมด
+// =========================
+func (nc *NotificationController) ListNotifications(c *gin.Context) {
+	var filter services.NotificationFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role, id := notificationAccount(c)
+	items, total, unread, err := nc.svc.Inbox(role, id, filter)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("X-Unread-Count", strconv.FormatInt(unread, 10))
+	c.JSON(http.StatusOK, items)
+}
+
+// =========================
+// POST /notifications/:id/read (Customer/Employee/Manager)
+// =========================
+func (nc *NotificationController) MarkNotificationRead(c *gin.Context) {
+	id, ok := notificationPathID(c)
+	if !ok {
+		return
+	}
+	role, userID := notificationAccount(c)
+	n, err := nc.svc.WithContext(c).MarkRead(role, userID, id)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, n)
+}
+
+// =========================
+// POST /notifications/read-all (Customer/Employee/Manager)
+// =========================
+func (nc *NotificationController) MarkAllNotificationsRead(c *gin.Context) {
+	role, id := notificationAccount(c)
+	n, err := nc.svc.WithContext(c).MarkAllRead(role, id)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": n})
+}
+
+// =========================
+// GET /notifications/preferences (Customer/Employee/Manager)
+// =========================
+func (nc *NotificationController) GetNotificationPreference(c *gin.Context) {
+	role, id := notificationAccount(c)
+	pref, err := nc.svc.Preference(role, id)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
+
+// =========================
+// PUT /notifications/preferences (Customer/Employee/Manager)
+// body: {"email": true, "line": true, "line_user_id": "U123...", "sms": false}
+// =========================
+func (nc *NotificationController) UpdateNotificationPreference(c *gin.Context) {
+	var input services.NotificationPreferenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role, id := notificationAccount(c)
+	pref, err := nc.svc.WithContext(c).SetPreference(role, id, input)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
+
+// =========================
+// GET /notification-deliveries?status=dead&channel=&page=&limit= (Manager)
+// =========================
+func (nc *NotificationController) ListDeliveries(c *gin.Context) {
+	var filter services.NotificationDeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	items, total, err := nc.svc.Deliveries(filter)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, items)
+}
+
+// =========================
+// POST /notification-deliveries/:id/retry (Manager)
+// =========================
+func (nc *NotificationController) RetryDelivery(c *gin.Context) {
+	id, ok := notificationPathID(c)
+	if !ok {
+		return
+	}
+	d, err := nc.svc.WithContext(c).RetryDelivery(id)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// =========================
+// POST /notification-deliveries/process (Manager)
+// ส่งรายการที่ถึงเวลาทันทีโดยไม่รอรอบของ worker
+// =========================
+func (nc *NotificationController) ProcessDeliveries(c *gin.Context) {
+	// ส่งต่อให้จบแม้ผู้เรียกตัดการเชื่อมต่อ แต่ยังบันทึก audit ด้วยผู้จัดการคนนี้
+	n, err := nc.svc.WithContext(context.WithoutCancel(c)).ProcessDue(context.WithoutCancel(c))
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"processed": n})
+}
+
+func respondNotificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound), errors.Is(err, services.ErrNotificationDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotificationDeliverySent):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidNotificationPref), errors.Is(err, services.ErrInvalidNotificationFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
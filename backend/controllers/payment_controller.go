@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PaymentController is the struct for handling payment operations.
+type PaymentController struct {
+	DB *gorm.DB
+}
+
+// NewPaymentController creates a new instance of PaymentController.
+func NewPaymentController(db *gorm.DB) *PaymentController {
+	return &PaymentController{DB: db}
+}
+
+// GET /payments?status=&customer_id=&employee_id=&contract=sale|rent&date_from=&date_to=
+// GetPayments retrieves payments matching the filter, newest first.
+func (controller *PaymentController) GetPayments(c *gin.Context) {
+	var filter services.PaymentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := filter.Apply(controller.DB)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payments []entity.Payment
+	if err := q.Preload("Customer").
+		Preload("Employee").
+		Preload("PaymentMethod").
+		Order("payments.payment_date DESC").
+		Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": payments})
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -41,7 +42,7 @@ func (controller *PickupDeliveryController) CreatePickupDelivery(c *gin.Context)
 	// --- vvvvv --- START: แก้ไขชื่อคอลัมน์ในการค้นหา --- vvvvv ---
 	if payload.Province != "" {
 		var province entity.Province
-		if err := controller.DB.Where("province_name = ?", payload.Province).First(&province).Error; err != nil {
+		if err := controller.DB.WithContext(c).Where("province_name = ?", payload.Province).First(&province).Error; err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Province not found"})
 			return
 		}
@@ -50,7 +51,7 @@ func (controller *PickupDeliveryController) CreatePickupDelivery(c *gin.Context)
 
 	if payload.District != "" && provinceID != nil {
 		var district entity.District
-		if err := controller.DB.Where("district_name = ? AND province_id = ?", payload.District, *provinceID).First(&district).Error; err != nil {
+		if err := controller.DB.WithContext(c).Where("district_name = ? AND province_id = ?", payload.District, *provinceID).First(&district).Error; err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "District not found"})
 			return
 		}
@@ -59,7 +60,7 @@ func (controller *PickupDeliveryController) CreatePickupDelivery(c *gin.Context)
 
 	if payload.Subdistrict != "" && districtID != nil {
 		var subDistrict entity.SubDistrict
-		if err := controller.DB.Where("sub_district_name = ? AND district_id = ?", payload.Subdistrict, *districtID).First(&subDistrict).Error; err != nil {
+		if err := controller.DB.WithContext(c).Where("sub_district_name = ? AND district_id = ?", payload.Subdistrict, *districtID).First(&subDistrict).Error; err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "SubDistrict not found"})
 			return
 		}
@@ -68,7 +69,7 @@ func (controller *PickupDeliveryController) CreatePickupDelivery(c *gin.Context)
 	// --- ^^^^^ --- END: จบส่วนที่แก้ไข --- ^^^^^ ---
 
 	var salesContract entity.SalesContract
-	if err := controller.DB.Where("id = ?", payload.SalesContractNumber).First(&salesContract).Error; err != nil {
+	if err := controller.DB.WithContext(c).Where("id = ?", payload.SalesContractNumber).First(&salesContract).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "SalesContract not found for the given contract ID"})
 		return
 	}
@@ -86,13 +87,38 @@ func (controller *PickupDeliveryController) CreatePickupDelivery(c *gin.Context)
 		Status:            "รอดำเนินการ",
 	}
 
-	if err := controller.DB.Create(&newPickupDelivery).Error; err != nil {
+	err := controller.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newPickupDelivery).Error; err != nil {
+			return err
+		}
+		return notifyDeliveryAssigned(tx, &newPickupDelivery)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"data": newPickupDelivery})
 }
 
+// notifyDeliveryAssigned แจ้งพนักงานที่ได้รับมอบหมายงานรับ/ส่งรถ
+func notifyDeliveryAssigned(tx *gorm.DB, pd *entity.PickupDelivery) error {
+	var typeInfo entity.TypeInformation
+	if err := tx.Limit(1).Find(&typeInfo, pd.TypeInformationID).Error; err != nil {
+		return err
+	}
+	return services.Notify(tx, services.NotificationInput{
+		Role:   entity.AuditRoleEmployee,
+		UserID: pd.EmployeeID,
+		Event:  entity.NotificationDeliveryAssigned,
+		Data: map[string]interface{}{
+			"SalesContractID": pd.SalesContractID,
+			"Type":            typeInfo.Type,
+			"DateTime":        pd.DateTime,
+			"Address":         pd.Address,
+		},
+	})
+}
+
 
 // GET /pickup-deliveries
 func (controller *PickupDeliveryController) GetPickupDeliveries(c *gin.Context) {
@@ -156,7 +182,7 @@ func (controller *PickupDeliveryController) GetPickupDeliveriesByCustomerID(c *g
 func (controller *PickupDeliveryController) UpdatePickupDelivery(c *gin.Context) {
 	id := c.Param("id")
 	var pickupDelivery entity.PickupDelivery
-	if err := controller.DB.First(&pickupDelivery, id).Error; err != nil {
+	if err := controller.DB.WithContext(c).First(&pickupDelivery, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "PickupDelivery not found"})
 		return
 	}
@@ -181,7 +207,7 @@ func (controller *PickupDeliveryController) UpdatePickupDelivery(c *gin.Context)
 	var provinceID, districtID, subDistrictID *uint
 	if payload.Province != "" {
 		var province entity.Province
-		if err := controller.DB.Where("province_name = ?", payload.Province).First(&province).Error; err != nil {
+		if err := controller.DB.WithContext(c).Where("province_name = ?", payload.Province).First(&province).Error; err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Province not found"})
 			return
 		}
@@ -189,7 +215,7 @@ func (controller *PickupDeliveryController) UpdatePickupDelivery(c *gin.Context)
 	}
 	if payload.District != "" && provinceID != nil {
 		var district entity.District
-		if err := controller.DB.Where("district_name = ? AND province_id = ?", payload.District, *provinceID).First(&district).Error; err != nil {
+		if err := controller.DB.WithContext(c).Where("district_name = ? AND province_id = ?", payload.District, *provinceID).First(&district).Error; err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "District not found"})
 			return
 		}
@@ -197,7 +223,7 @@ func (controller *PickupDeliveryController) UpdatePickupDelivery(c *gin.Context)
 	}
 	if payload.Subdistrict != "" && districtID != nil {
 		var subDistrict entity.SubDistrict
-		if err := controller.DB.Where("sub_district_name = ? AND district_id = ?", payload.Subdistrict, *districtID).First(&subDistrict).Error; err != nil {
+		if err := controller.DB.WithContext(c).Where("sub_district_name = ? AND district_id = ?", payload.Subdistrict, *districtID).First(&subDistrict).Error; err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "SubDistrict not found"})
 			return
 		}
@@ -207,12 +233,13 @@ func (controller *PickupDeliveryController) UpdatePickupDelivery(c *gin.Context)
 
 	// ค้นหาสัญญา
 	var salesContract entity.SalesContract
-	if err := controller.DB.Where("id = ?", payload.SalesContractNumber).First(&salesContract).Error; err != nil {
+	if err := controller.DB.WithContext(c).Where("id = ?", payload.SalesContractNumber).First(&salesContract).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "SalesContract not found for the given contract ID"})
 		return
 	}
 
 	// อัปเดตข้อมูลใน object ที่ดึงมา
+	reassigned := pickupDelivery.EmployeeID != payload.EmployeeID
 	pickupDelivery.EmployeeID = payload.EmployeeID
 	pickupDelivery.TypeInformationID = payload.TypeInformationID
 	pickupDelivery.SalesContractID = salesContract.ID
@@ -222,8 +249,17 @@ func (controller *PickupDeliveryController) UpdatePickupDelivery(c *gin.Context)
 	pickupDelivery.DistrictID = districtID
 	pickupDelivery.SubDistrictID = subDistrictID
 
-	// บันทึกข้อมูลที่อัปเดตลง DB
-	if err := controller.DB.Save(&pickupDelivery).Error; err != nil {
+	// บันทึกข้อมูลที่อัปเดตลง DB และแจ้งพนักงานคนใหม่ถ้าเปลี่ยนผู้รับผิดชอบ
+	err := controller.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&pickupDelivery).Error; err != nil {
+			return err
+		}
+		if !reassigned {
+			return nil
+		}
+		return notifyDeliveryAssigned(tx, &pickupDelivery)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -234,7 +270,7 @@ func (controller *PickupDeliveryController) UpdatePickupDelivery(c *gin.Context)
 func (controller *PickupDeliveryController) UpdatePickupDeliveryStatus(c *gin.Context) {
 	id := c.Param("id")
 	var pickupDelivery entity.PickupDelivery
-	if err := controller.DB.First(&pickupDelivery, id).Error; err != nil {
+	if err := controller.DB.WithContext(c).First(&pickupDelivery, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "PickupDelivery not found"})
 		return
 	}
@@ -248,13 +284,13 @@ func (controller *PickupDeliveryController) UpdatePickupDeliveryStatus(c *gin.Co
 	}
 
 	pickupDelivery.Status = statusUpdate.Status
-	if err := controller.DB.Save(&pickupDelivery).Error; err != nil {
+	if err := controller.DB.WithContext(c).Save(&pickupDelivery).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	
 	var updatedPickupDelivery entity.PickupDelivery
-	if err := controller.DB.Preload("Customer").
+	if err := controller.DB.WithContext(c).Preload("Customer").
 		Preload("Employee").
 		Preload("TypeInformation").
 		Preload("SalesContract").
@@ -271,7 +307,7 @@ func (controller *PickupDeliveryController) UpdatePickupDeliveryStatus(c *gin.Co
 // DELETE /pickup-deliveries/:id
 func (controller *PickupDeliveryController) DeletePickupDelivery(c *gin.Context) {
 	id := c.Param("id")
-	if err := controller.DB.Delete(&entity.PickupDelivery{}, id).Error; err != nil {
+	if err := controller.DB.WithContext(c).Delete(&entity.PickupDelivery{}, id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PriceAlertController struct {
+	svc *services.PriceAlertService
+}
+
+func NewPriceAlertController(db *gorm.DB) *PriceAlertController {
+	return &PriceAlertController{svc: services.NewPriceAlertService(db)}
+}
+
+// =========================
+// GET /customers/me/price-alerts (Customer)
+// =========================
+func (pc *PriceAlertController) ListMyPriceAlerts(c *gin.Context) {
+	alerts, err := pc.svc.List(c.GetUint("userID"))
+	if err != nil {
+		respondPriceAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, alerts)
+}
+
+// =========================
+// POST /customers/me/price-alerts (Customer)
+// body: {"car_id": 12} หรือ {"saved_search_id": 3} (ตัวกรองจาก /customers/me/saved-searches)
+// =========================
+func (pc *PriceAlertController) CreatePriceAlert(c *gin.Context) {
+	var input services.PriceAlertInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	alert, err := pc.svc.WithContext(c).Create(c.GetUint("userID"), input)
+	if err != nil {
+		respondPriceAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, alert)
+}
+
+// =========================
+// DELETE /customers/me/price-alerts/:id (Customer)
+// =========================
+func (pc *PriceAlertController) DeletePriceAlert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := pc.svc.WithContext(c).Delete(c.GetUint("userID"), uint(id)); err != nil {
+		respondPriceAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "price alert deleted"})
+}
+
+// =========================
+// GET /customers/me/price-alerts/hits (Customer)
+// ราคาลด / รถใหม่ตรงเงื่อนไข ล่าสุด 100 รายการ
+// =========================
+func (pc *PriceAlertController) ListMyPriceAlertHits(c *gin.Context) {
+	hits, err := pc.svc.Hits(c.GetUint("userID"))
+	if err != nil {
+		respondPriceAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
+
+func respondPriceAlertError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPriceAlertNotFound), errors.Is(err, services.ErrPriceAlertCarNotFound),
+		errors.Is(err, services.ErrSavedSearchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPriceAlertExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidPriceAlert):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -21,11 +24,10 @@ func NewRentContractController(db *gorm.DB) *RentContractController {
 
 // Payload สำหรับการสร้างสัญญาเช่า
 type createRentContractPayload struct {
-	CarID      uint    `json:"car_id"`
-	CustomerID uint    `json:"customer_id"`
-	StartDate  string  `json:"start_date"`
-	EndDate    string  `json:"end_date"`
-	TotalPrice float64 `json:"total_price"`
+	CarID      uint   `json:"car_id"`
+	CustomerID uint   `json:"customer_id"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
 }
 
 // POST /rent-contracts
@@ -49,33 +51,137 @@ func (controller *RentContractController) CreateRentContract(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
 		return
 	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be on or after start_date"})
+		return
+	}
 
 	// ตรวจสอบข้อมูล Foreign Key
 	var customer entity.Customer
-	if err := controller.DB.First(&customer, payload.CustomerID).Error; err != nil {
+	if err := controller.DB.WithContext(c).First(&customer, payload.CustomerID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
 		return
 	}
 
 	// ค้นหา RentList จาก CarID
 	var rentList entity.RentList
-	if err := controller.DB.Where("car_id = ?", payload.CarID).First(&rentList).Error; err != nil {
+	if err := controller.DB.WithContext(c).Where("car_id = ?", payload.CarID).First(&rentList).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "RentList for the given car not found"})
 		return
 	}
 
+	if err := services.RequireCarState(controller.DB.WithContext(c), payload.CarID, services.ErrCarNotForRent, entity.CarListedForRent, entity.CarRentedOut); err != nil {
+		respondLifecycleError(c, err)
+		return
+	}
+
+	block, err := services.MaintenanceBlock(controller.DB.WithContext(c), payload.CarID, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return
+	}
+	if block != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrCarInMaintenance.Error()})
+		return
+	}
+
+	// คิดราคาฝั่งเซิร์ฟเวอร์ ไม่ใช้ราคาที่หน้าเว็บส่งมา
+	quote, err := services.NewRentalPricingService(controller.DB).WithContext(c).Quote(payload.CarID, payload.StartDate, payload.EndDate)
+	if err != nil {
+		respondRentalQuoteError(c, err)
+		return
+	}
+
 	// สร้างสัญญาเช่าใหม่
 	newRentContract := entity.RentContract{
+		TotalPrice: quote.Total,
 		DateStart:  startDate,
 		DateEnd:    endDate,
 		RentListID: rentList.ID, // ใช้ ID ที่ค้นหาเจอ
 		CustomerID: payload.CustomerID,
 	}
 
-	if err := controller.DB.Create(&newRentContract).Error; err != nil {
+	// ตรวจช่วงทับซ้อนใน transaction เดียวกับการสร้าง กันจองซ้อนพร้อมกัน
+	// สัญญาที่เริ่มวันนี้ทำให้รถเป็น rented_out ทันที
+	err = controller.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		booked, err := services.RentalOverlap(tx, payload.CarID, startDate, endDate)
+		if err != nil {
+			return err
+		}
+		if booked {
+			return services.ErrRentalDatesBooked
+		}
+		if err := tx.Create(&newRentContract).Error; err != nil {
+			return err
+		}
+		return services.SyncCarRental(tx, payload.CarID)
+	})
+	if errors.Is(err, services.ErrRentalDatesBooked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": newRentContract})
-}
\ No newline at end of file
+}
+
+// ใบเสนอราคาไม่ผ่าน (ไม่เปิดให้เช่า ไม่มีราคา สั้นกว่าขั้นต่ำ) ถือว่าคำขอจองไม่ถูกต้อง
+func respondRentalQuoteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidQuoteRange), errors.Is(err, services.ErrRentalBelowMinimum),
+		errors.Is(err, services.ErrRentalPriceNotSet), errors.Is(err, services.ErrRentalNotOpen),
+		errors.Is(err, services.ErrRentalCarNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GET /rent-contracts?customer_id=&car_id=&date_from=&date_to=
+// GetRentContracts retrieves rent contracts whose rental period overlaps the given dates.
+func (controller *RentContractController) GetRentContracts(c *gin.Context) {
+	var filter services.RentContractFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := filter.Apply(controller.DB)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rentContracts []entity.RentContract
+	if err := q.Preload("RentList.Car").
+		Preload("Customer").
+		Preload("Payment").
+		Order("rent_contracts.date_start DESC").
+		Find(&rentContracts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rentContracts})
+}
+
+// GET /rent-contracts/:id/document
+// GetRentContractDocument returns the data to print on the contract, including the car's registration details.
+func (controller *RentContractController) GetRentContractDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	doc, err := services.NewContractDocumentService(controller.DB).RentContract(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "RentContract not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": doc})
+}
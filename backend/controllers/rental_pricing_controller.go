@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RentalPricingController struct {
+	svc      *services.RentalPricingService
+	calendar *services.RentalCalendarService
+}
+
+func NewRentalPricingController(db *gorm.DB) *RentalPricingController {
+	return &RentalPricingController{
+		svc:      services.NewRentalPricingService(db),
+		calendar: services.NewRentalCalendarService(db),
+	}
+}
+
+func rentalPricingPathID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /rentlists/:carId/quote?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD (Public)
+// =========================
+func (rc *RentalPricingController) GetQuote(c *gin.Context) {
+	carID, ok := rentalPricingPathID(c, "carId")
+	if !ok {
+		return
+	}
+	quote, err := rc.svc.Quote(carID, c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// =========================
+// GET /rentlists/calendar?start_date=&end_date=&car_id=1&car_id=2 (Public)
+// รับตัวกรองเดียวกับ GET /cars (brand, model, color ...) แทน car_id ได้
+// =========================
+func (rc *RentalPricingController) GetCalendar(c *gin.Context) {
+	var filter services.RentalCalendarFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cal, err := rc.calendar.Calendar(filter)
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cal)
+}
+
+// =========================
+// GET /rental-pricing/rules (Manager)
+// =========================
+func (rc *RentalPricingController) ListRules(c *gin.Context) {
+	rules, err := rc.svc.ListRules()
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// =========================
+// POST /rental-pricing/rules (Manager)
+// =========================
+func (rc *RentalPricingController) CreateRule(c *gin.Context) {
+	var input services.RentalRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule, err := rc.svc.WithContext(c).CreateRule(c.GetUint("managerID"), input)
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// =========================
+// PUT /rental-pricing/rules/:id (Manager)
+// =========================
+func (rc *RentalPricingController) UpdateRule(c *gin.Context) {
+	id, ok := rentalPricingPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.RentalRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule, err := rc.svc.WithContext(c).UpdateRule(id, input)
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// =========================
+// DELETE /rental-pricing/rules/:id (Manager)
+// =========================
+func (rc *RentalPricingController) DeleteRule(c *gin.Context) {
+	id, ok := rentalPricingPathID(c, "id")
+	if !ok {
+		return
+	}
+	if err := rc.svc.WithContext(c).DeleteRule(id); err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "rental price rule deleted"})
+}
+
+// =========================
+// GET /rental-pricing/adjustments (Manager)
+// =========================
+func (rc *RentalPricingController) ListAdjustments(c *gin.Context) {
+	adjs, err := rc.svc.ListAdjustments()
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, adjs)
+}
+
+// =========================
+// POST /rental-pricing/adjustments (Manager)
+// =========================
+func (rc *RentalPricingController) CreateAdjustment(c *gin.Context) {
+	var input services.RentalAdjustmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	adj, err := rc.svc.WithContext(c).CreateAdjustment(input)
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, adj)
+}
+
+// =========================
+// PUT /rental-pricing/adjustments/:id (Manager)
+// =========================
+func (rc *RentalPricingController) UpdateAdjustment(c *gin.Context) {
+	id, ok := rentalPricingPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.RentalAdjustmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	adj, err := rc.svc.WithContext(c).UpdateAdjustment(id, input)
+	if err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, adj)
+}
+
+// =========================
+// DELETE /rental-pricing/adjustments/:id (Manager)
+// =========================
+func (rc *RentalPricingController) DeleteAdjustment(c *gin.Context) {
+	id, ok := rentalPricingPathID(c, "id")
+	if !ok {
+		return
+	}
+	if err := rc.svc.WithContext(c).DeleteAdjustment(id); err != nil {
+		respondRentalPricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "rental price adjustment deleted"})
+}
+
+func respondRentalPricingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRentalRuleNotFound), errors.Is(err, services.ErrRentalAdjustmentNotFound),
+		errors.Is(err, services.ErrRentalCarNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRentalRuleConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRentalPriceNotSet), errors.Is(err, services.ErrRentalNotOpen):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidRentalRule), errors.Is(err, services.ErrInvalidRentalAdjustment),
+		errors.Is(err, services.ErrInvalidQuoteRange), errors.Is(err, services.ErrRentalBelowMinimum):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -3,9 +3,12 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -24,6 +27,7 @@ func (rc *RentListController) GetRentListsByCar(c *gin.Context) {
 
 	var car entity.Car
 	if err := rc.DB.Preload("Pictures").
+		Preload("RegistrationProvince").
 		First(&car, carId).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
 		return
@@ -57,6 +61,8 @@ func (rc *RentListController) GetRentListsByCar(c *gin.Context) {
 		Color:           car.Color,
 		Mileage:         car.Mileage,
 		Condition:       car.Condition,
+		Lifecycle:       car.Lifecycle,
+		Identity:        services.CarIdentityOf(car),
 		SaleList:        nil,
 		RentList:        rentPeriods,
 		Pictures:        car.Pictures,
@@ -87,58 +93,97 @@ func (rc *RentListController) CreateOrUpdateRentList(c *gin.Context) {
 		return
 	}
 
-	// หา RentList หรือสร้างใหม่
-	var rentList entity.RentList
-	err := rc.DB.Where("car_id = ?", input.CarID).First(&rentList).Error
-	if err == gorm.ErrRecordNotFound {
-		rentList = entity.RentList{
-			CarID:     input.CarID,
-			ManagerID: input.ManagerID,
-			Status:    "forRent", // สร้างใหม่ → forRent
+	type dateRange struct{ open, close time.Time }
+	ranges := make([]dateRange, len(input.Dates))
+	for i, d := range input.Dates {
+		open, err := time.Parse("2006-01-02", d.OpenDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "open_date must be YYYY-MM-DD"})
+			return
 		}
-		rc.DB.Create(&rentList)
-	} else {
-		// ถ้ามีช่วงเช่าใหม่ → status = forRent
-		if len(input.Dates) > 0 {
-			rentList.Status = "forRent"
-			rc.DB.Save(&rentList)
+		close, err := time.Parse("2006-01-02", d.CloseDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "close_date must be YYYY-MM-DD"})
+			return
 		}
+		ranges[i] = dateRange{open, close}
 	}
 
-	// จัดการแต่ละช่วงเช่า
-	for _, d := range input.Dates {
-		open, _ := time.Parse("2006-01-02", d.OpenDate)
-		close, _ := time.Parse("2006-01-02", d.CloseDate)
-
-		if d.ID != 0 {
-			// Update
-			var existing entity.DateforRent
-			if err := rc.DB.First(&existing, d.ID).Error; err == nil {
-				existing.OpenDate = open
-				existing.CloseDate = close
+	var rentList entity.RentList
+	err := rc.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// รถต้องอยู่ในสต็อกหรือเปิดให้เช่าอยู่แล้ว (รถที่เปิดขายต้องถอนประกาศก่อน)
+		if err := services.ListCarForRent(tx, input.CarID, "rent list updated"); err != nil {
+			return err
+		}
+
+		// หา RentList หรือสร้างใหม่
+		if err := tx.Where("car_id = ?", input.CarID).Limit(1).Find(&rentList).Error; err != nil {
+			return err
+		}
+		if rentList.ID == 0 {
+			rentList = entity.RentList{
+				CarID:     input.CarID,
+				ManagerID: input.ManagerID,
+				Status:    entity.RentListForRent, // สร้างใหม่ → forRent
+			}
+			if err := tx.Create(&rentList).Error; err != nil {
+				return err
+			}
+		} else if len(input.Dates) > 0 {
+			// ถ้ามีช่วงเช่าใหม่ → status = forRent
+			rentList.Status = entity.RentListForRent
+			if err := tx.Save(&rentList).Error; err != nil {
+				return err
+			}
+		}
+
+		// จัดการแต่ละช่วงเช่า
+		for i, d := range input.Dates {
+			if d.ID != 0 {
+				// Update (ช่วงที่ไม่พบจะถูกข้าม)
+				var existing entity.DateforRent
+				if err := tx.Limit(1).Find(&existing, d.ID).Error; err != nil {
+					return err
+				}
+				if existing.ID == 0 {
+					continue
+				}
+				existing.OpenDate = ranges[i].open
+				existing.CloseDate = ranges[i].close
 				existing.RentPrice = d.RentPrice
-				existing.Status = "available" // ตั้ง status อัตโนมัติ
-				rc.DB.Save(&existing)
+				existing.Status = entity.DateforRentAvailable // ตั้ง status อัตโนมัติ
+				if err := tx.Save(&existing).Error; err != nil {
+					return err
+				}
+				continue
 			}
-		} else {
+
 			// Create
 			date := entity.DateforRent{
-				OpenDate:  open,
-				CloseDate: close,
+				OpenDate:  ranges[i].open,
+				CloseDate: ranges[i].close,
 				RentPrice: d.RentPrice,
-				Status:    "available", // สร้างใหม่ → available
+				Status:    entity.DateforRentAvailable, // สร้างใหม่ → available
 			}
-			rc.DB.Create(&date)
-
-			rc.DB.Create(&entity.RentAbleDate{
+			if err := tx.Create(&date).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&entity.RentAbleDate{
 				RentListID:    rentList.ID,
 				DateforRentID: date.ID,
-			})
+			}).Error; err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		respondLifecycleError(c, err)
+		return
 	}
 
 	// Preload คืนค่า
-	rc.DB.Preload("RentAbleDates.DateforRent").First(&rentList, rentList.ID)
+	rc.DB.WithContext(c).Preload("RentAbleDates.DateforRent").First(&rentList, rentList.ID)
 	c.JSON(http.StatusOK, rentList)
 }
 
@@ -148,12 +193,12 @@ func (rc *RentListController) DeleteRentDate(c *gin.Context) {
 	var id uint
 	fmt.Sscanf(dateId, "%d", &id)
 
-	if err := rc.DB.Delete(&entity.RentAbleDate{}, "datefor_rent_id = ?", id).Error; err != nil {
+	if err := rc.DB.WithContext(c).Delete(&entity.RentAbleDate{}, "datefor_rent_id = ?", id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := rc.DB.Delete(&entity.DateforRent{}, id).Error; err != nil {
+	if err := rc.DB.WithContext(c).Delete(&entity.DateforRent{}, id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -176,7 +221,7 @@ func (rc *RentListController) BookCar(c *gin.Context) {
 	}
 
 	var bookedDates []entity.DateforRent
-	tx := rc.DB.Begin() // ใช้ transaction ป้องกัน race condition
+	tx := rc.DB.WithContext(c).Begin() // ใช้ transaction ป้องกัน race condition
 
 	for _, dateID := range input.DateIDs {
 		var date entity.DateforRent
@@ -186,14 +231,43 @@ func (rc *RentListController) BookCar(c *gin.Context) {
 			return
 		}
 
-		if date.Status != "available" {
+		if date.Status != entity.DateforRentAvailable {
 			tx.Rollback()
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("date %d is not available", dateID)})
 			return
 		}
 
+		// ช่วงที่รถเข้าอู่จองไม่ได้
+		var carIDs []uint
+		if err := tx.Model(&entity.RentAbleDate{}).
+			Joins("JOIN rent_lists ON rent_lists.id = rent_able_dates.rent_list_id").
+			Where("rent_able_dates.datefor_rent_id = ?", date.ID).
+			Pluck("rent_lists.car_id", &carIDs).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, carID := range carIDs {
+			if err := services.RequireCarState(tx, carID, services.ErrCarNotForRent, entity.CarListedForRent, entity.CarRentedOut); err != nil {
+				tx.Rollback()
+				respondLifecycleError(c, err)
+				return
+			}
+			block, err := services.MaintenanceBlock(tx, carID, date.OpenDate, date.CloseDate.AddDate(0, 0, 1))
+			if err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if block != nil {
+				tx.Rollback()
+				c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("date %d: %s", dateID, services.ErrCarInMaintenance.Error())})
+				return
+			}
+		}
+
 		// จองสำเร็จ
-		date.Status = "booked"
+		date.Status = entity.DateforRentBooked
 		date.BookedBy = input.UserID
 		if err := tx.Save(&date).Error; err != nil {
 			tx.Rollback()
@@ -204,6 +278,27 @@ func (rc *RentListController) BookCar(c *gin.Context) {
 		bookedDates = append(bookedDates, date)
 	}
 
+	// แจ้งลูกค้าว่าจองสำเร็จ
+	if len(bookedDates) > 0 {
+		carID, _ := strconv.Atoi(c.Param("carId"))
+		ranges := make([]string, len(bookedDates))
+		for i, d := range bookedDates {
+			ranges[i] = d.OpenDate.Format("02/01/2006") + " - " + d.CloseDate.Format("02/01/2006")
+		}
+		err := services.Notify(tx, services.NotificationInput{
+			Role:   entity.AuditRoleCustomer,
+			UserID: input.UserID,
+			Event:  entity.NotificationBookingConfirmed,
+			CarID:  uint(carID),
+			Data:   map[string]interface{}{"Dates": strings.Join(ranges, ", ")},
+		})
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	tx.Commit()
 	c.JSON(http.StatusOK, bookedDates)
 }
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReportController struct {
+	svc *services.ReportService
+}
+
+func NewReportController(db *gorm.DB) *ReportController {
+	return &ReportController{svc: services.NewReportService(db)}
+}
+
+// ทุก endpoint รับ ?date_from=&date_to= (YYYY-MM-DD) ไม่ระบุ = 12 เดือนล่าสุด
+
+// =========================
+// GET /reports/summary (Manager)
+// =========================
+func (rc *ReportController) GetSummary(c *gin.Context) {
+	rc.respond(c, func(f services.ReportFilter) (interface{}, error) { return rc.svc.Summary(f) })
+}
+
+// =========================
+// GET /reports/sales?period=day|week|month|year (Manager)
+// =========================
+func (rc *ReportController) GetSalesByPeriod(c *gin.Context) {
+	rc.respond(c, func(f services.ReportFilter) (interface{}, error) { return rc.svc.SalesByPeriod(f) })
+}
+
+// =========================
+// GET /reports/top-sellers?group=brand|model&limit= (Manager)
+// =========================
+func (rc *ReportController) GetTopSellers(c *gin.Context) {
+	rc.respond(c, func(f services.ReportFilter) (interface{}, error) { return rc.svc.TopSellers(f) })
+}
+
+// =========================
+// GET /reports/rental-utilization (Manager)
+// =========================
+func (rc *ReportController) GetRentalUtilization(c *gin.Context) {
+	rc.respond(c, func(f services.ReportFilter) (interface{}, error) { return rc.svc.RentalUtilization(f) })
+}
+
+// =========================
+// GET /reports/outstanding-payments?limit= (Manager)
+// =========================
+func (rc *ReportController) GetOutstandingPayments(c *gin.Context) {
+	rc.respond(c, func(f services.ReportFilter) (interface{}, error) { return rc.svc.OutstandingPayments(f) })
+}
+
+// =========================
+// GET /reports/employees (Manager)
+// =========================
+func (rc *ReportController) GetEmployeePerformance(c *gin.Context) {
+	rc.respond(c, func(f services.ReportFilter) (interface{}, error) { return rc.svc.EmployeePerformance(f) })
+}
+
+// =========================
+// GET /reports/popular-cars?limit= (Manager)
+// นับการถูกใจที่เกิดในช่วงวันที่ (total_favourites คือยอดสะสม)
+// =========================
+func (rc *ReportController) GetPopularCars(c *gin.Context) {
+	rc.respond(c, func(f services.ReportFilter) (interface{}, error) { return rc.svc.PopularCars(f) })
+}
+
+func (rc *ReportController) respond(c *gin.Context, run func(services.ReportFilter) (interface{}, error)) {
+	var filter services.ReportFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := run(filter)
+	switch {
+	case errors.Is(err, services.ErrInvalidDateFilter), errors.Is(err, services.ErrInvalidReportPeriod),
+		errors.Is(err, services.ErrInvalidReportRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, data)
+	}
+}
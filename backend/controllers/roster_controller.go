@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RosterController struct {
+	svc *services.RosterService
+}
+
+func NewRosterController(db *gorm.DB) *RosterController {
+	return &RosterController{svc: services.NewRosterService(db)}
+}
+
+func rosterPathID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /shift-templates (Manager)
+// =========================
+func (rc *RosterController) ListTemplates(c *gin.Context) {
+	templates, err := rc.svc.ListTemplates()
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// =========================
+// POST /shift-templates (Manager)
+// =========================
+func (rc *RosterController) CreateTemplate(c *gin.Context) {
+	var input services.ShiftTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tpl, err := rc.svc.WithContext(c).CreateTemplate(c.GetUint("managerID"), input)
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tpl)
+}
+
+// =========================
+// PUT /shift-templates/:id (Manager)
+// =========================
+func (rc *RosterController) UpdateTemplate(c *gin.Context) {
+	id, ok := rosterPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.ShiftTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tpl, err := rc.svc.WithContext(c).UpdateTemplate(id, input)
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tpl)
+}
+
+// =========================
+// DELETE /shift-templates/:id (Manager)
+// =========================
+func (rc *RosterController) DeleteTemplate(c *gin.Context) {
+	id, ok := rosterPathID(c, "id")
+	if !ok {
+		return
+	}
+	if err := rc.svc.WithContext(c).DeleteTemplate(id); err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "shift template deleted"})
+}
+
+// =========================
+// GET /rosters?week_start=YYYY-MM-DD (Manager)
+// =========================
+func (rc *RosterController) GetRosterByWeek(c *gin.Context) {
+	roster, err := rc.svc.GetRosterByWeek(c.Query("week_start"))
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roster)
+}
+
+// =========================
+// POST /rosters (Manager)
+// body: {"week_start": "YYYY-MM-DD"} ต้องเป็นวันจันทร์
+// =========================
+func (rc *RosterController) CreateRoster(c *gin.Context) {
+	var body struct {
+		WeekStart string `json:"week_start" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	roster, err := rc.svc.WithContext(c).CreateRoster(body.WeekStart, c.GetUint("managerID"))
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, roster)
+}
+
+// =========================
+// GET /rosters/:id (Manager)
+// =========================
+func (rc *RosterController) GetRoster(c *gin.Context) {
+	id, ok := rosterPathID(c, "id")
+	if !ok {
+		return
+	}
+	roster, err := rc.svc.GetRoster(id)
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roster)
+}
+
+// =========================
+// POST /rosters/:id/shifts (Manager)
+// =========================
+func (rc *RosterController) AddShift(c *gin.Context) {
+	id, ok := rosterPathID(c, "id")
+	if !ok {
+		return
+	}
+	var input services.RosterShiftInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	shift, err := rc.svc.WithContext(c).AddShift(id, input)
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, shift)
+}
+
+// =========================
+// DELETE /rosters/:id/shifts/:shiftId (Manager)
+// =========================
+func (rc *RosterController) DeleteShift(c *gin.Context) {
+	id, ok := rosterPathID(c, "id")
+	if !ok {
+		return
+	}
+	shiftID, ok := rosterPathID(c, "shiftId")
+	if !ok {
+		return
+	}
+	if err := rc.svc.WithContext(c).DeleteShift(id, shiftID); err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "shift deleted"})
+}
+
+// =========================
+// POST /rosters/:id/publish (Manager)
+// =========================
+func (rc *RosterController) PublishRoster(c *gin.Context) {
+	id, ok := rosterPathID(c, "id")
+	if !ok {
+		return
+	}
+	roster, err := rc.svc.WithContext(c).Publish(id, c.GetUint("managerID"))
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roster)
+}
+
+// =========================
+// GET /employees/me/shifts?date_from=&date_to= (Employee)
+// =========================
+func (rc *RosterController) GetMyShifts(c *gin.Context) {
+	shifts, err := rc.svc.EmployeeShifts(c.GetUint("employeeID"), c.Query("date_from"), c.Query("date_to"))
+	if err != nil {
+		respondRosterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shifts)
+}
+
+func respondRosterError(c *gin.Context, err error) {
+	var parseErr *time.ParseError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+	case errors.Is(err, services.ErrShiftTemplateNotFound), errors.Is(err, services.ErrRosterNotFound),
+		errors.Is(err, services.ErrRosterShiftNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRosterPublished), errors.Is(err, services.ErrShiftOverlap):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidShiftTemplate), errors.Is(err, services.ErrInvalidWeekStart),
+		errors.Is(err, services.ErrShiftOutsideWeek), errors.Is(err, services.ErrInvalidDateFilter),
+		errors.As(err, &parseErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,19 +1,22 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type SaleController struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	search *services.CarSearchService
 }
 
 func NewSaleController(db *gorm.DB) *SaleController {
-	return &SaleController{DB: db}
+	return &SaleController{DB: db, search: services.NewCarSearchService(db)}
 }
 
 // GET /sale/cars
@@ -60,7 +63,6 @@ func (sc *SaleController) CreateSale(c *gin.Context) {
 	var input struct {
 		CarID       uint    `json:"car_id" binding:"required"`
 		SalePrice   float64 `json:"sale_price" binding:"required"`
-		ManagerID   uint    `json:"manager_id" binding:"required"`
 		EmployeeID  uint    `json:"employee_id" binding:"required"`
 		Description string  `json:"description"`
 	}
@@ -70,21 +72,37 @@ func (sc *SaleController) CreateSale(c *gin.Context) {
 		return
 	}
 
+	// ผู้จัดการที่ตั้งราคาคือผู้ที่ล็อกอินอยู่
+	managerID := c.GetUint("managerID")
 	sale := entity.SaleList{
 		CarID:       input.CarID,
 		SalePrice:   input.SalePrice,
-		Status:      "available",
-		ManagerID:   &input.ManagerID,
+		Status:      entity.SaleListAvailable,
+		ManagerID:   &managerID,
 		EmployeeID:  &input.EmployeeID,
 		Description: input.Description,
 	}
 
-	if err := sc.DB.Create(&sale).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := sc.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := services.ListCarForSale(tx, input.CarID, "sale list created"); err != nil {
+			return err
+		}
+		if err := tx.Create(&sale).Error; err != nil {
+			return err
+		}
+		return services.RecordSalePrice(tx, &sale, 0, &managerID)
+	})
+	if err != nil {
+		respondLifecycleError(c, err)
 		return
 	}
 
-	sc.DB.Preload("Car").Preload("Employee").Preload("Manager").First(&sale, sale.ID)
+	// คำอธิบายการขายอยู่ใน search index ของรถ
+	if err := sc.search.IndexCar(sale.CarID); err != nil {
+		log.Println("failed to update car search index:", err)
+	}
+
+	sc.DB.WithContext(c).Preload("Car").Preload("Employee").Preload("Manager").First(&sale, sale.ID)
 
 	c.JSON(http.StatusOK, sale)
 }
@@ -95,7 +113,6 @@ func (sc *SaleController) UpdateSale(c *gin.Context) {
 
 	var input struct {
 		SalePrice   float64 `json:"sale_price" binding:"required"`
-		ManagerID   uint    `json:"manager_id" binding:"required"`
 		EmployeeID  uint    `json:"employee_id" binding:"required"`
 		Description string  `json:"description"`
 	}
@@ -106,22 +123,36 @@ func (sc *SaleController) UpdateSale(c *gin.Context) {
 	}
 
 	var sale entity.SaleList
-	if err := sc.DB.First(&sale, id).Error; err != nil {
+	if err := sc.DB.WithContext(c).First(&sale, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Sale not found"})
 		return
 	}
 
+	managerID := c.GetUint("managerID")
+	oldPrice := sale.SalePrice
 	sale.SalePrice = input.SalePrice
-	sale.ManagerID = &input.ManagerID
+	sale.ManagerID = &managerID
 	sale.EmployeeID = &input.EmployeeID
 	sale.Description = input.Description
 
-	if err := sc.DB.Save(&sale).Error; err != nil {
+	// เก็บประวัติราคาและแจ้งผู้ติดตามถ้าราคาลด
+	err := sc.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&sale).Error; err != nil {
+			return err
+		}
+		return services.RecordSalePrice(tx, &sale, oldPrice, &managerID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	sc.DB.Preload("Car").Preload("Employee").Preload("Manager").First(&sale, sale.ID)
+	// คำอธิบายการขายอยู่ใน search index ของรถ
+	if err := sc.search.IndexCar(sale.CarID); err != nil {
+		log.Println("failed to update car search index:", err)
+	}
+
+	sc.DB.WithContext(c).Preload("Car").Preload("Employee").Preload("Manager").First(&sale, sale.ID)
 
 	c.JSON(http.StatusOK, sale)
 }
\ No newline at end of file
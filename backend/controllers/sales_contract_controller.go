@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	
+	"strconv"
+
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -34,42 +38,72 @@ func (controller *SalesContractController) CreateSalesContract(c *gin.Context) {
 
 	// Validate foreign keys exist
 	var saleList entity.SaleList
-	if err := controller.DB.First(&saleList, payload.SaleListID).Error; err != nil {
+	if err := controller.DB.WithContext(c).First(&saleList, payload.SaleListID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "SaleList not found"})
 		return
 	}
 
 	var employee entity.Employee
-	if err := controller.DB.First(&employee, payload.EmployeeID).Error; err != nil {
+	if err := controller.DB.WithContext(c).First(&employee, payload.EmployeeID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Employee not found"})
 		return
 	}
 
 	var customer entity.Customer
-	if err := controller.DB.First(&customer, payload.CustomerID).Error; err != nil {
+	if err := controller.DB.WithContext(c).First(&customer, payload.CustomerID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Customer not found"})
 		return
 	}
 
+	// รถที่อยู่อู่ขายไม่ได้จนกว่าจะปิดงานซ่อม
+	inMaintenance, err := services.CarInMaintenance(controller.DB.WithContext(c), saleList.CarID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return
+	}
+	if inMaintenance {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrCarInMaintenance.Error()})
+		return
+	}
+
 	newSalesContract := entity.SalesContract{
 		SaleListID: payload.SaleListID,
 		EmployeeID: payload.EmployeeID,
 		CustomerID: payload.CustomerID,
+		SalePrice:  saleList.SalePrice,
 	}
 
-	if err := controller.DB.Create(&newSalesContract).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+	// สัญญาซื้อขาย = รถขายแล้ว (ห้ามถ้ารถยังมีสัญญาเช่าค้าง)
+	err = controller.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newSalesContract).Error; err != nil {
+			return err
+		}
+		return services.TransitionCar(tx, saleList.CarID, entity.CarSold, fmt.Sprintf("sales contract %d", newSalesContract.ID), nil)
+	})
+	if err != nil {
+		respondLifecycleError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": newSalesContract})
 }
 
-// GET /sales-contracts
+// GET /sales-contracts?employee_id=&customer_id=&date_from=&date_to=
 // GetSalesContracts retrieves all sales contracts with related data.
 func (controller *SalesContractController) GetSalesContracts(c *gin.Context) {
+	var filter services.SalesContractFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := filter.Apply(controller.DB)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var salesContracts []entity.SalesContract
-	if err := controller.DB.Preload("SaleList").
+	if err := q.Preload("SaleList").
 		Preload("Employee").
 		Preload("Customer").
 		Preload("InspectionAppointments").
@@ -86,7 +120,7 @@ func (controller *SalesContractController) GetSalesContracts(c *gin.Context) {
 func (controller *SalesContractController) GetSalesContractByID(c *gin.Context) {
 	id := c.Param("id")
 	var salesContract entity.SalesContract
-	if err := controller.DB.Preload("SaleList").
+	if err := controller.DB.Preload("SaleList.Car.RegistrationProvince").
 		Preload("Employee").
 		Preload("Customer").
 		Preload("InspectionAppointments").
@@ -140,7 +174,7 @@ func (controller *SalesContractController) GetSalesContractsByCustomerID(c *gin.
 func (controller *SalesContractController) UpdateSalesContract(c *gin.Context) {
 	id := c.Param("id")
 	var salesContract entity.SalesContract
-	if err := controller.DB.First(&salesContract, id).Error; err != nil {
+	if err := controller.DB.WithContext(c).First(&salesContract, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "SalesContract not found"})
 		return
 	}
@@ -160,7 +194,7 @@ func (controller *SalesContractController) UpdateSalesContract(c *gin.Context) {
 	salesContract.EmployeeID = payload.EmployeeID
 	salesContract.CustomerID = payload.CustomerID
 
-	if err := controller.DB.Save(&salesContract).Error; err != nil {
+	if err := controller.DB.WithContext(c).Save(&salesContract).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sales contract data"})
 		return
 	}
@@ -172,9 +206,57 @@ func (controller *SalesContractController) UpdateSalesContract(c *gin.Context) {
 // DeleteSalesContract deletes a sales contract by ID.
 func (controller *SalesContractController) DeleteSalesContract(c *gin.Context) {
 	id := c.Param("id")
-	if err := controller.DB.Delete(&entity.SalesContract{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var contract entity.SalesContract
+	if err := controller.DB.WithContext(c).Preload("SaleList").First(&contract, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SalesContract not found"})
+		return
+	}
+	err := controller.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&contract).Error; err != nil {
+			return err
+		}
+		if contract.SaleList == nil {
+			return nil
+		}
+		// ไม่มีสัญญาอื่นของรถคันนี้เหลือ → เปิดขายอีกครั้ง
+		var remaining int64
+		if err := tx.Model(&entity.SalesContract{}).
+			Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id").
+			Where("sale_lists.car_id = ?", contract.SaleList.CarID).
+			Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return nil
+		}
+		if err := tx.Model(contract.SaleList).Update("status", entity.SaleListAvailable).Error; err != nil {
+			return err
+		}
+		return services.ReopenCarSale(tx, contract.SaleList.CarID, fmt.Sprintf("sales contract %d deleted", contract.ID))
+	})
+	if err != nil {
+		respondLifecycleError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "SalesContract deleted successfully"})
-}
\ No newline at end of file
+}
+
+// GET /sales-contracts/:id/document
+// GetSalesContractDocument returns the data to print on the contract, including the car's registration details.
+func (controller *SalesContractController) GetSalesContractDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	doc, err := services.NewContractDocumentService(controller.DB).SalesContract(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SalesContract not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": doc})
+}
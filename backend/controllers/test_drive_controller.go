@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TestDriveController struct {
+	svc *services.TestDriveService
+}
+
+func NewTestDriveController(db *gorm.DB) *TestDriveController {
+	return &TestDriveController{svc: services.NewTestDriveService(db)}
+}
+
+func testDrivePathID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// GET /test-drives/slots?sale_list_id=&date=YYYY-MM-DD (Public)
+// =========================
+func (tc *TestDriveController) GetSlots(c *gin.Context) {
+	saleListID, err := strconv.Atoi(c.Query("sale_list_id"))
+	if err != nil || saleListID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sale_list_id is required"})
+		return
+	}
+	slots, err := tc.svc.Slots(uint(saleListID), c.Query("date"))
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+// =========================
+// POST /customers/me/test-drives (Customer)
+// =========================
+func (tc *TestDriveController) BookTestDrive(c *gin.Context) {
+	var input services.TestDriveInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	drive, err := tc.svc.WithContext(c).Book(c.GetUint("userID"), input)
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, drive)
+}
+
+// =========================
+// GET /customers/me/test-drives (Customer)
+// =========================
+func (tc *TestDriveController) GetMyTestDrives(c *gin.Context) {
+	drives, err := tc.svc.CustomerDrives(c.GetUint("userID"))
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, drives)
+}
+
+// =========================
+// POST /customers/me/test-drives/:id/cancel (Customer)
+// =========================
+func (tc *TestDriveController) CancelMyTestDrive(c *gin.Context) {
+	id, ok := testDrivePathID(c)
+	if !ok {
+		return
+	}
+	drive, err := tc.svc.WithContext(c).CancelByCustomer(c.GetUint("userID"), id)
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, drive)
+}
+
+// =========================
+// GET /test-drives?status=&employee_id=&sale_list_id=&date= (Staff)
+// =========================
+func (tc *TestDriveController) ListTestDrives(c *gin.Context) {
+	var filter services.TestDriveFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	drives, err := tc.svc.List(filter)
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, drives)
+}
+
+// =========================
+// GET /test-drives/:id (Staff)
+// =========================
+func (tc *TestDriveController) GetTestDrive(c *gin.Context) {
+	id, ok := testDrivePathID(c)
+	if !ok {
+		return
+	}
+	drive, err := tc.svc.Get(id)
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, drive)
+}
+
+// =========================
+// PUT /test-drives/:id/assign (Staff)
+// body: {"employee_id": 1} (พนักงานรับนัดให้ตัวเองได้โดยไม่ต้องส่ง)
+// =========================
+func (tc *TestDriveController) AssignTestDrive(c *gin.Context) {
+	id, ok := testDrivePathID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		EmployeeID uint `json:"employee_id"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	drive, err := tc.svc.WithContext(c).Assign(staffActor(c), id, body.EmployeeID)
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, drive)
+}
+
+// =========================
+// PUT /test-drives/:id/status (Staff)
+// body: {"status": "confirmed|completed|no_show|cancelled", "note": "..."}
+// =========================
+func (tc *TestDriveController) UpdateTestDriveStatus(c *gin.Context) {
+	id, ok := testDrivePathID(c)
+	if !ok {
+		return
+	}
+	var input services.TestDriveStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	drive, err := tc.svc.WithContext(c).ChangeStatus(id, input)
+	if err != nil {
+		respondTestDriveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, drive)
+}
+
+func respondTestDriveError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTestDriveNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTestDriveUnavailable), errors.Is(err, services.ErrTestDriveTransition),
+		errors.Is(err, services.ErrTestDriveListingClosed), errors.Is(err, services.ErrTestDriveEmployeeBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTestDrive), errors.Is(err, services.ErrInvalidDateFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
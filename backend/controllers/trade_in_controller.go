@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TradeInController struct {
+	svc    *services.TradeInService
+	images *storage.ImageStore
+}
+
+// รูปรถเทิร์นเก็บในที่เดียวกับรูปรถ เมื่อรับเข้าสต็อกจึงใช้ไฟล์เดิมได้เลย
+func NewTradeInController(db *gorm.DB, backend storage.Backend) *TradeInController {
+	return &TradeInController{
+		svc:    services.NewTradeInService(db),
+		images: storage.NewImageStore(backend, storage.CarImagePrefix),
+	}
+}
+
+func tradeInPathID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// =========================
+// POST /customers/me/trade-ins (Customer)
+// =========================
+func (tc *TradeInController) SubmitTradeIn(c *gin.Context) {
+	var input services.TradeInInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trade, err := tc.svc.WithContext(c).Submit(c.GetUint("userID"), input)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, trade)
+}
+
+// =========================
+// POST /customers/me/trade-ins/:id/photos (Customer)
+// multipart: photos (หลายไฟล์)
+// =========================
+func (tc *TradeInController) UploadTradeInPhotos(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	files := form.File["photos"]
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "photos is required"})
+		return
+	}
+	// ตรวจสิทธิ์ก่อนเขียนไฟล์
+	if _, err := tc.svc.WithContext(c).GetForCustomer(c.GetUint("userID"), id); err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		stored, err := tc.images.Save(file)
+		file.Close()
+		if err != nil {
+			respondImageError(c, header.Filename, err)
+			return
+		}
+		paths = append(paths, stored.Name)
+	}
+
+	photos, err := tc.svc.WithContext(c).AddPhotos(c.GetUint("userID"), id, paths)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, photos)
+}
+
+// =========================
+// GET /customers/me/trade-ins (Customer)
+// =========================
+func (tc *TradeInController) GetMyTradeIns(c *gin.Context) {
+	trades, err := tc.svc.List(services.TradeInFilter{CustomerID: c.GetUint("userID")})
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trades)
+}
+
+// =========================
+// GET /customers/me/trade-ins/:id (Customer)
+// =========================
+func (tc *TradeInController) GetMyTradeIn(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	trade, err := tc.svc.GetForCustomer(c.GetUint("userID"), id)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trade)
+}
+
+// =========================
+// POST /customers/me/trade-ins/:id/accept (Customer)
+// body: {"sales_contract_id": 1}
+// =========================
+func (tc *TradeInController) AcceptTradeIn(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		SalesContractID uint `json:"sales_contract_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trade, err := tc.svc.WithContext(c).Accept(c.GetUint("userID"), id, body.SalesContractID)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trade)
+}
+
+// =========================
+// POST /customers/me/trade-ins/:id/decline (Customer)
+// body (ไม่บังคับ): {"reason": "..."}
+// =========================
+func (tc *TradeInController) DeclineTradeIn(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	trade, err := tc.svc.WithContext(c).Decline(c.GetUint("userID"), id, body.Reason)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trade)
+}
+
+// =========================
+// GET /trade-ins?status=&customer_id= (Staff)
+// =========================
+func (tc *TradeInController) ListTradeIns(c *gin.Context) {
+	var filter services.TradeInFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trades, err := tc.svc.List(filter)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trades)
+}
+
+// =========================
+// GET /trade-ins/:id (Staff)
+// =========================
+func (tc *TradeInController) GetTradeIn(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	trade, err := tc.svc.Get(id)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trade)
+}
+
+// =========================
+// PUT /trade-ins/:id/appraisal (Staff)
+// body: {"appraised_value": 250000, "note": "...", "items": [{"car_system_id": 1, "condition": "good", "deduction": 0}]}
+// =========================
+func (tc *TradeInController) AppraiseTradeIn(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	var input services.TradeInAppraisalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trade, err := tc.svc.WithContext(c).Appraise(staffActor(c), id, input)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trade)
+}
+
+// =========================
+// POST /trade-ins/:id/offer (Manager)
+// body: {"offer_amount": 240000, "note": "..."}
+// =========================
+func (tc *TradeInController) OfferTradeIn(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	var input services.TradeInOfferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trade, err := tc.svc.WithContext(c).Offer(c.GetUint("managerID"), id, input)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trade)
+}
+
+// =========================
+// POST /trade-ins/:id/reject (Manager)
+// body (ไม่บังคับ): {"reason": "..."}
+// =========================
+func (tc *TradeInController) RejectTradeIn(c *gin.Context) {
+	id, ok := tradeInPathID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	trade, err := tc.svc.WithContext(c).Reject(id, body.Reason)
+	if err != nil {
+		respondTradeInError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trade)
+}
+
+func respondTradeInError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTradeInNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTradeInState), errors.Is(err, services.ErrTradeInOfferExpired),
+		errors.Is(err, services.ErrTradeInExceedsBalance), errors.Is(err, services.ErrDuplicateVIN),
+		errors.Is(err, services.ErrDuplicatePlate), errors.Is(err, services.ErrDuplicateEngineNumber):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTradeIn), errors.Is(err, services.ErrTradeInContractInvalid),
+		errors.Is(err, services.ErrInvalidVIN):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// ผู้กระทำใน AuditLog
+const (
+	AuditRoleCustomer  = "customer"
+	AuditRoleEmployee  = "employee"
+	AuditRoleManager   = "manager"
+	AuditRoleAnonymous = "anonymous" // เรียก API โดยไม่มี token
+	AuditRoleSystem    = "system"    // งานเบื้องหลัง/สคริปต์ที่ไม่ได้มาจาก request
+)
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditLog บันทึกการเขียนข้อมูลทุกครั้ง (สร้างจาก GORM callback) ห้ามแก้/ลบ จึงไม่มี DeletedAt
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	ActorID   *uint  `json:"actor_id" gorm:"index"` // nil = anonymous/system
+	ActorRole string `json:"actor_role" gorm:"index"`
+	Action    string `json:"action"`
+	Entity    string `json:"entity" gorm:"index:idx_audit_record"` // ชื่อตาราง เช่น sale_lists
+	RecordID  string `json:"record_id" gorm:"index:idx_audit_record"`
+	Changes   string `json:"changes"` // JSON {"column": {"before": ..., "after": ...}}
+
+	IP     string `json:"ip"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
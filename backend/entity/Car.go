@@ -16,6 +16,22 @@ type Car struct {
 	Mileage         int       `json:"mileage"`
 	Condition       string    `json:"condition"`
 
+	// สถานะหลักของรถ (in_stock, listed_for_sale, listed_for_rent, reserved, rented_out, in_maintenance, sold)
+	Lifecycle string `json:"lifecycle" gorm:"index"`
+
+	// ข้อมูลประจำตัวรถตามเล่มทะเบียน (ต้องระบุในสัญญาซื้อขาย)
+	// ค่าว่างคือยังไม่ได้กรอก จึงไม่นับในเงื่อนไขห้ามซ้ำ
+	VIN          string `json:"vin" gorm:"uniqueIndex:idx_cars_vin_unique,where:vin <> '' AND deleted_at IS NULL"`
+	EngineNumber string `json:"engine_number" gorm:"uniqueIndex:idx_cars_engine_number_unique,where:engine_number <> '' AND deleted_at IS NULL"`
+	LicensePlate string `json:"license_plate" gorm:"uniqueIndex:idx_cars_plate_unique,priority:1,where:license_plate <> '' AND deleted_at IS NULL"` // เก็บแบบ NormalizePlate
+
+	// ทะเบียนเดียวกันซ้ำได้ถ้าต่างจังหวัด
+	RegistrationProvinceID uint      `json:"registration_province_id" gorm:"uniqueIndex:idx_cars_plate_unique,priority:2"`
+	RegistrationProvince   *Province `gorm:"foreignKey:RegistrationProvinceID" json:"registration_province"`
+
+	RegistrationExpiry *time.Time `json:"registration_expiry"` // วันสิ้นอายุทะเบียน
+	TaxExpiry          *time.Time `json:"tax_expiry"`          // วันครบกำหนดเสียภาษีประจำปี
+
 	Pictures []CarPicture `gorm:"foreignKey:CarID" json:"pictures"`
 
 	ProvinceID uint      `gorm:"column:province_id" json:"province_id"`
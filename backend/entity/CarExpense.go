@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// หมวดค่าใช้จ่ายของรถ
+const (
+	ExpenseRefurbishment = "refurbishment" // ซ่อม/ทำสี/เตรียมรถ
+	ExpenseInspection    = "inspection"    // ตรวจสภาพ
+	ExpenseTransport     = "transport"     // ขนส่ง/ลากรถ
+	ExpenseRegistration  = "registration"  // โอน/ต่อทะเบียน/ภาษี
+	ExpenseMaintenance   = "maintenance"   // ซ่อมบำรุงตามรอบ (บันทึกจากงานซ่อมบำรุง)
+	ExpenseOther         = "other"
+)
+
+// CarExpense ค่าใช้จ่ายที่เกิดกับรถแต่ละคันหลังซื้อเข้า (ใช้คำนวณต้นทุนรวม)
+type CarExpense struct {
+	gorm.Model
+	Category    string    `json:"category" gorm:"index"`
+	Amount      float64   `json:"amount"`
+	ExpenseDate time.Time `json:"expense_date"`
+	Vendor      string    `json:"vendor"`
+	Description string    `json:"description"`
+	ReceiptKey  string    `json:"receipt_key"` // key ของไฟล์ใบเสร็จใน storage
+
+	CarID uint `json:"car_id" gorm:"index"`
+	Car   *Car `gorm:"foreignKey:CarID" json:"-"`
+
+	ManagerID uint     `json:"manager_id"`
+	Manager   *Manager `gorm:"foreignKey:ManagerID" json:"manager,omitempty"`
+}
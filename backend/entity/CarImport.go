@@ -0,0 +1,19 @@
+package entity
+
+import "gorm.io/gorm"
+
+// CarImport ประวัติการนำเข้ารถจากไฟล์ CSV พร้อมผลรายแถว
+type CarImport struct {
+	gorm.Model
+	FileName  string `json:"file_name"`
+	DryRun    bool   `json:"dry_run"`
+	Committed bool   `json:"committed"`
+	TotalRows int    `json:"total_rows"`
+	Created   int    `json:"created"`
+	Skipped   int    `json:"skipped"`
+	Failed    int    `json:"failed"`
+	ResultCSV string `json:"-"` // row,status,car_id,message
+
+	ManagerID uint     `json:"manager_id"`
+	Manager   *Manager `gorm:"foreignKey:ManagerID" json:"manager,omitempty"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "gorm.io/gorm"
+
+// สถานะหลักของรถ (Car.Lifecycle) เปลี่ยนผ่าน CarLifecycleService เท่านั้น
+const (
+	CarInStock       = "in_stock"
+	CarListedForSale = "listed_for_sale"
+	CarListedForRent = "listed_for_rent"
+	CarReserved      = "reserved"
+	CarRentedOut     = "rented_out"
+	CarInMaintenance = "in_maintenance"
+	CarSold          = "sold"
+)
+
+// สถานะเดิมของประกาศ ยังเก็บไว้ให้หน้าเว็บเดิมอ่าน (สะกดตามข้อมูลที่มีอยู่)
+const (
+	SaleListAvailable = "Available"
+	SaleListSold      = "Sold"
+
+	RentListDraft   = "draft"
+	RentListForRent = "forRent"
+
+	DateforRentAvailable = "available"
+	DateforRentBooked    = "booked"
+)
+
+// CarLifecycleEvent ประวัติการเปลี่ยนสถานะของรถ
+type CarLifecycleEvent struct {
+	gorm.Model
+	CarID     uint   `json:"car_id" gorm:"index"`
+	FromState string `json:"from_state"`
+	ToState   string `json:"to_state"`
+	Reason    string `json:"reason"`
+	ManagerID *uint  `json:"manager_id"` // nil = ระบบเปลี่ยนให้จากสัญญา/งานซ่อม
+}
@@ -13,11 +13,25 @@ type CarResponse struct {
 	PurchaseDate    time.Time    `json:"purchase_date"`
 	Mileage         int          `json:"mileage"`
 	Condition       string       `json:"condition"`
+	Lifecycle       string       `json:"lifecycle"`
+	Identity        CarIdentity  `json:"identity"`
 	SaleList        []SaleEntry  `json:"sale_list"` // Employee ที่ Manager เลือก
 	RentList        []RentPeriod `json:"rent_list"`
 	Pictures        []CarPicture `json:"pictures"`
 	Detail          DetailFilter `json:"cardetail"`
 	Manager         *ManagerInfo `json:"manager_add_car"` 
+	IsFavourite     bool         `json:"is_favourite"` // ลูกค้าที่ login อยู่ถูกใจรถคันนี้
+}
+
+// CarIdentity ข้อมูลตามเล่มทะเบียนรถ
+type CarIdentity struct {
+	VIN                    string     `json:"vin"`
+	EngineNumber           string     `json:"engine_number"`
+	LicensePlate           string     `json:"license_plate"`
+	RegistrationProvinceID uint       `json:"registration_province_id"`
+	RegistrationProvince   string     `json:"registration_province"`
+	RegistrationExpiry     *time.Time `json:"registration_expiry"`
+	TaxExpiry              *time.Time `json:"tax_expiry"`
 }
 
 type SaleEntry struct {
@@ -28,6 +42,8 @@ type SaleEntry struct {
 	EmployeeID    uint    `json:"employee_id"` // เพิ่มตรงนี้
 	EmployeeName  string  `json:"employee_name"`
 	EmployeePhone string  `json:"employee_phone"`
+
+	PriceHistory []SalePriceChange `json:"price_history,omitempty"` // เฉพาะ GET /cars/:id
 }
 
 // สร้าง struct แยกสำหรับ Manager
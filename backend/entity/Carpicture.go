@@ -6,8 +6,9 @@ import (
 
 type CarPicture struct {
 	gorm.Model
-	Title string `json:"title"`
-	Path  string `json:"path"`                        // URL หรือ path ของรูป
-	CarID uint   `json:"car_id"`                      // foreign key
-	Car   *Car   `gorm:"foreignKey:CarID" json:"car"` // ความสัมพันธ์กับ Car
+	Title     string `json:"title"`
+	Path      string `json:"path"`                        // URL หรือ path ของรูป
+	SortOrder int    `json:"sort_order"`                  // ลำดับการแสดงผล (น้อยแสดงก่อน)
+	CarID     uint   `json:"car_id"`                      // foreign key
+	Car       *Car   `gorm:"foreignKey:CarID" json:"car"` // ความสัมพันธ์กับ Car
 }
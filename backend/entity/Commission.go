@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ชนิดของกฎค่าคอมมิชชั่น
+const (
+	CommissionSaleTier   = "sale_tier"   // % ของยอดขายทั้งเดือน ใช้ขั้นสูงสุดที่ยอดขายถึง MinAmount
+	CommissionBrandBonus = "brand_bonus" // โบนัสต่อคันของยี่ห้อ BrandID: FixedAmount + % ของราคาขาย
+	CommissionRental     = "rental"      // % ของค่าเช่าที่พนักงานรับชำระในเดือน
+)
+
+// สถานะของใบสรุปค่าคอมมิชชั่น
+const (
+	CommissionStatementDraft    = "draft"
+	CommissionStatementApproved = "approved"
+)
+
+// CommissionRule กฎการคำนวณค่าคอมมิชชั่นที่ผู้จัดการตั้งค่า
+type CommissionRule struct {
+	gorm.Model
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind" gorm:"index"`
+	MinAmount   float64 `json:"min_amount"` // sale_tier: ยอดขายขั้นต่ำของขั้นนี้
+	Rate        float64 `json:"rate"`       // เปอร์เซ็นต์
+	FixedAmount float64 `json:"fixed_amount"`
+	Active      bool    `json:"active"`
+
+	BrandID *uint  `json:"brand_id"` // brand_bonus เท่านั้น
+	Brand   *Brand `gorm:"foreignKey:BrandID" json:"brand,omitempty"`
+
+	ManagerID uint `json:"manager_id"`
+}
+
+// CommissionStatement ใบสรุปค่าคอมมิชชั่นรายเดือนของพนักงาน ต้องให้ผู้จัดการอนุมัติก่อนจ่ายพร้อมเงินเดือน
+type CommissionStatement struct {
+	gorm.Model
+	Month      string    `json:"month" gorm:"uniqueIndex:idx_commission_statement_month"` // YYYY-MM
+	EmployeeID uint      `json:"employee_id" gorm:"uniqueIndex:idx_commission_statement_month"`
+	Employee   *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+
+	SalesCount       int64   `json:"sales_count"`
+	SalesRevenue     float64 `json:"sales_revenue"`
+	RentalRevenue    float64 `json:"rental_revenue"`
+	TierCommission   float64 `json:"tier_commission"`
+	BrandBonus       float64 `json:"brand_bonus"`
+	RentalCommission float64 `json:"rental_commission"`
+	Total            float64 `json:"total"`
+
+	Status       string     `json:"status" gorm:"default:'draft'"`
+	ApprovedByID *uint      `json:"approved_by_id"`
+	ApprovedBy   *Manager   `gorm:"foreignKey:ApprovedByID" json:"approved_by,omitempty"`
+	ApprovedAt   *time.Time `json:"approved_at"`
+	Note         string     `json:"note"`
+
+	Lines []CommissionStatementLine `gorm:"foreignKey:StatementID" json:"lines,omitempty"`
+}
+
+// CommissionStatementLine รายการที่ประกอบเป็นยอดในใบสรุป
+type CommissionStatementLine struct {
+	gorm.Model
+	StatementID     uint    `json:"statement_id" gorm:"index"`
+	Kind            string  `json:"kind"`
+	RuleID          uint    `json:"rule_id"`
+	SalesContractID *uint   `json:"sales_contract_id"`
+	PaymentID       *uint   `json:"payment_id"`
+	Description     string  `json:"description"`
+	BaseAmount      float64 `json:"base_amount"`
+	Rate            float64 `json:"rate"`
+	Amount          float64 `json:"amount"`
+}
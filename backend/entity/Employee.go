@@ -16,7 +16,10 @@ type Employee struct {
 	Sex          string    `json:"sex"`
 	Position     string    `json:"position"`
 	JobType      string    `json:"jobType"`
-	TotalSales   string    `json:"totalSales"`
+
+	// คำนวณจากสัญญาซื้อขาย ไม่ได้เก็บในตาราง
+	TotalSales   float64   `json:"totalSales" gorm:"-"`
+	SalesCount   int64     `json:"salesCount" gorm:"-"`
 
 	LeaveRequests  []LeaveRequest   `json:"leaves" gorm:"-"`
 	PickupDelivery []PickupDelivery `gorm:"foreignKey:EmployeeID"`
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Favourite รถที่ลูกค้ากดถูกใจ (ลบจริงเมื่อเลิกถูกใจ จึงไม่มี DeletedAt)
+type Favourite struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	CreatedAt  time.Time `json:"created_at"`
+	CustomerID uint      `json:"customer_id" gorm:"uniqueIndex:idx_favourite_customer_car"`
+	CarID      uint      `json:"car_id" gorm:"uniqueIndex:idx_favourite_customer_car;index"`
+}
+
+// SavedSearch ชุดตัวกรองที่ลูกค้าบันทึกไว้ใช้ค้นหา /cars ซ้ำ
+type SavedSearch struct {
+	gorm.Model
+	CustomerID uint   `json:"customer_id" gorm:"index"`
+	Name       string `json:"name"`
+	Filter     string `json:"-"` // JSON ของ services.CarFilter
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ขั้นของลูกค้าที่สนใจ (won ได้จากการผูกสัญญาเท่านั้น)
+const (
+	LeadStageNew         = "new"
+	LeadStageContacted   = "contacted"
+	LeadStageQualified   = "qualified"
+	LeadStageNegotiating = "negotiating"
+	LeadStageWon         = "won"
+	LeadStageLost        = "lost"
+)
+
+// ชนิดของบันทึกกิจกรรม
+const (
+	LeadActivityNote        = "note"
+	LeadActivityCall        = "call"
+	LeadActivityVisit       = "visit"
+	LeadActivityStageChange = "stage_change"
+	LeadActivityTask        = "task"
+	LeadActivityConversion  = "conversion"
+)
+
+// Lead ลูกค้าที่สนใจรถคันใดคันหนึ่ง (ประกาศขายหรือประกาศเช่า) และพนักงานที่ดูแล
+type Lead struct {
+	gorm.Model
+
+	// ลูกค้าในระบบ หรือผู้ติดต่อ walk-in ที่ยังไม่มีบัญชี
+	CustomerID   *uint     `json:"customer_id" gorm:"index"`
+	Customer     *Customer `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	ContactName  string    `json:"contact_name"`
+	ContactPhone string    `json:"contact_phone"`
+	ContactEmail string    `json:"contact_email"`
+	Source       string    `json:"source"` // walk_in, phone, web, referral ...
+
+	SaleListID *uint     `json:"sale_list_id" gorm:"index"`
+	SaleList   *SaleList `gorm:"foreignKey:SaleListID" json:"sale_list,omitempty"`
+	RentListID *uint     `json:"rent_list_id" gorm:"index"`
+	RentList   *RentList `gorm:"foreignKey:RentListID" json:"rent_list,omitempty"`
+
+	EmployeeID uint      `json:"employee_id" gorm:"index"`
+	Employee   *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+
+	Stage      string `json:"stage" gorm:"index;default:'new'"`
+	LostReason string `json:"lost_reason"`
+
+	// สัญญาที่เกิดจาก lead นี้
+	SalesContractID *uint      `json:"sales_contract_id"`
+	RentContractID  *uint      `json:"rent_contract_id"`
+	ConvertedAt     *time.Time `json:"converted_at"`
+
+	Tasks      []LeadTask     `gorm:"foreignKey:LeadID" json:"tasks,omitempty"`
+	Activities []LeadActivity `gorm:"foreignKey:LeadID" json:"activities,omitempty"`
+}
+
+// LeadTask งานติดตามลูกค้าที่มีกำหนดส่ง
+type LeadTask struct {
+	gorm.Model
+	LeadID      uint       `json:"lead_id" gorm:"index"`
+	Title       string     `json:"title"`
+	DueAt       time.Time  `json:"due_at" gorm:"index"`
+	CompletedAt *time.Time `json:"completed_at"`
+	EmployeeID  uint       `json:"employee_id" gorm:"index"` // ผู้รับผิดชอบ
+}
+
+// LeadActivity บันทึกการติดต่อและการเปลี่ยนแปลงของ lead
+type LeadActivity struct {
+	gorm.Model
+	LeadID     uint   `json:"lead_id" gorm:"index"`
+	Kind       string `json:"kind"`
+	Note       string `json:"note"`
+	EmployeeID *uint  `json:"employee_id"`
+	ManagerID  *uint  `json:"manager_id"`
+}
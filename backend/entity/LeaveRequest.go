@@ -1,14 +1,53 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ประเภทการลา
+const (
+	LeaveTypeSick     = "sick"
+	LeaveTypePersonal = "personal"
+	LeaveTypeVacation = "vacation"
+)
+
+// สถานะคำขอลา
+const (
+	LeaveStatusPending  = "pending"
+	LeaveStatusApproved = "approved"
+	LeaveStatusDenied   = "denied"
+)
 
 type LeaveRequest struct {
 	gorm.Model
 	LeaveID    string    `json:"leaveID" gorm:"uniqueIndex"`
-	StartDate  string    `json:"startDate"`
-	EndDate    string    `json:"endDate"`
+	StartDate  time.Time `json:"startDate" gorm:"index"`
+	EndDate    time.Time `json:"endDate" gorm:"index"`
+	Days       int       `json:"days"` // นับรวมวันเริ่มและวันสิ้นสุด
 	Type       string    `json:"type"`
-	Status     string    `json:"status"`
-	EmployeeID uint      `json:"employeeID"`
+	Reason     string    `json:"reason"`
+	Status     string    `json:"status" gorm:"index"`
+	EmployeeID uint      `json:"employeeID" gorm:"index"`
 	Employee   *Employee `json:"employee,omitempty" gorm:"foreignKey:EmployeeID;references:EmployeeID"`
+
+	// ผู้จัดการที่อนุมัติ/ปฏิเสธ
+	DecidedByID    *uint      `json:"decidedByID"`
+	DecidedBy      *Manager   `json:"decidedBy,omitempty" gorm:"foreignKey:DecidedByID"`
+	DecidedAt      *time.Time `json:"decidedAt"`
+	ManagerComment string     `json:"managerComment"`
+}
+
+// LeaveBalance สิทธิ์วันลาต่อปีของพนักงานแยกตามประเภท
+type LeaveBalance struct {
+	gorm.Model
+	EmployeeID uint   `json:"employeeID" gorm:"uniqueIndex:idx_leave_balance"`
+	Year       int    `json:"year" gorm:"uniqueIndex:idx_leave_balance"`
+	Type       string `json:"type" gorm:"uniqueIndex:idx_leave_balance"`
+	Entitled   int    `json:"entitled"`
+	Used       int    `json:"used"` // หักเมื่ออนุมัติ
+
+	Pending   int `json:"pending" gorm:"-"`   // วันลาที่รออนุมัติ
+	Remaining int `json:"remaining" gorm:"-"` // Entitled - Used - Pending
 }
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สถานะงานซ่อมบำรุง (planned และ in_progress ถือว่ารถติดซ่อม)
+const (
+	MaintenancePlanned    = "planned"
+	MaintenanceInProgress = "in_progress"
+	MaintenanceCompleted  = "completed"
+	MaintenanceCancelled  = "cancelled"
+)
+
+// ประเภทงานบริการ
+const (
+	ServiceOilChange  = "oil_change"
+	ServiceTires      = "tires"
+	ServiceBrakes     = "brakes"
+	ServiceBattery    = "battery"
+	ServiceInspection = "inspection" // เช็กระยะ
+	ServiceRepair     = "repair"
+	ServiceOther      = "other"
+)
+
+// MaintenanceRecord งานซ่อมบำรุงของรถหนึ่งคัน
+// ระหว่าง PlannedStart ถึง PlannedEnd (หรือจนกว่าจะปิดงานถ้ายังซ่อมไม่เสร็จ) รถจะไม่เปิดขาย/ให้เช่า
+type MaintenanceRecord struct {
+	gorm.Model
+	CarID uint `json:"car_id" gorm:"index"`
+	Car   *Car `gorm:"foreignKey:CarID" json:"-"`
+
+	ServiceType  string    `json:"service_type" gorm:"index"`
+	Description  string    `json:"description"`
+	Garage       string    `json:"garage"`
+	PlannedStart time.Time `json:"planned_start" gorm:"index"` // วันที่ (เวลา 00:00)
+	PlannedEnd   time.Time `json:"planned_end" gorm:"index"`   // วันสุดท้ายที่รถอยู่อู่ (รวมวันนั้น)
+	Status       string    `json:"status" gorm:"index"`
+
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	Odometer    int        `json:"odometer"` // เลขไมล์ตอนเข้าอู่
+	Cost        float64    `json:"cost"`
+	Note        string     `json:"note"`
+
+	ManagerID    uint  `json:"manager_id"`
+	CarExpenseID *uint `json:"car_expense_id"` // ค่าใช้จ่ายที่บันทึกให้อัตโนมัติเมื่อปิดงาน
+}
+
+// ServiceReminder รอบบริการตามระยะทางและ/หรือระยะเวลา เช่น เปลี่ยนน้ำมันเครื่องทุก 10,000 กม. หรือ 6 เดือน
+type ServiceReminder struct {
+	gorm.Model
+	CarID uint `json:"car_id" gorm:"index"`
+	Car   *Car `gorm:"foreignKey:CarID" json:"-"`
+
+	ServiceType    string    `json:"service_type" gorm:"index"`
+	IntervalKm     int       `json:"interval_km"`     // 0 = ไม่นับตามระยะทาง
+	IntervalMonths int       `json:"interval_months"` // 0 = ไม่นับตามเวลา
+	LastServiceAt  time.Time `json:"last_service_at"`
+	LastOdometer   int       `json:"last_odometer"`
+	Active         bool      `json:"active"`
+	Note           string    `json:"note"`
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// เหตุการณ์ที่แจ้งผู้ใช้ (ข้อความตาม template ใน services/notification_service.go)
+const (
+	NotificationBookingConfirmed    = "booking_confirmed"
+	NotificationTestDriveConfirmed  = "test_drive_confirmed"
+	NotificationInspectionScheduled = "inspection_scheduled"
+	NotificationDeliveryAssigned    = "delivery_assigned"
+	NotificationLeaveApproved       = "leave_approved"
+	NotificationLeaveDenied         = "leave_denied"
+	NotificationPriceDrop           = "price_drop"
+	NotificationNewListing          = "new_listing"
+)
+
+// ช่องทางส่งออกนอกระบบ (กล่องข้อความในระบบได้เสมอ)
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelLine  = "line"
+	NotificationChannelSMS   = "sms"
+)
+
+// สถานะการส่งของแต่ละช่องทาง: dead = ส่งไม่สำเร็จจนเลิกลองแล้ว รอผู้จัดการสั่งส่งใหม่
+const (
+	NotificationDeliveryPending = "pending"
+	NotificationDeliverySent    = "sent"
+	NotificationDeliveryDead    = "dead"
+)
+
+// Notification ข้อความในกล่องแจ้งเตือนของผู้ใช้
+// RecipientRole ใช้ค่าเดียวกับ AuditRole* (customer/employee/manager)
+type Notification struct {
+	gorm.Model
+	RecipientRole string     `json:"recipient_role" gorm:"index:idx_notification_recipient"`
+	RecipientID   uint       `json:"recipient_id" gorm:"index:idx_notification_recipient"`
+	Event         string     `json:"event" gorm:"index"`
+	Title         string     `json:"title"`
+	Body          string     `json:"body"`
+	ReadAt        *time.Time `json:"read_at"`
+}
+
+// NotificationPreference ช่องทางที่ผู้ใช้ต้องการรับนอกเหนือจากกล่องข้อความ (ไม่มีแถว = ในระบบอย่างเดียว)
+type NotificationPreference struct {
+	ID         uint      `json:"-" gorm:"primarykey"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Role       string    `json:"-" gorm:"uniqueIndex:idx_notification_pref_user"`
+	UserID     uint      `json:"-" gorm:"uniqueIndex:idx_notification_pref_user"`
+	Email      bool      `json:"email"`
+	Line       bool      `json:"line"`
+	SMS        bool      `json:"sms"`
+	LineUserID string    `json:"line_user_id"`
+}
+
+// NotificationDelivery การส่งข้อความหนึ่งฉบับทางช่องทางหนึ่ง (outbox ที่ worker ส่งซ้ำจนสำเร็จหรือ dead)
+type NotificationDelivery struct {
+	ID             uint          `json:"id" gorm:"primarykey"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	NotificationID uint          `json:"notification_id" gorm:"index"`
+	Notification   *Notification `json:"notification,omitempty" gorm:"foreignKey:NotificationID"`
+	Channel        string        `json:"channel"`
+	Address        string        `json:"address"`
+	Status         string        `json:"status" gorm:"index:idx_notification_delivery_due"`
+	Attempts       int           `json:"attempts"`
+	NextAttemptAt  time.Time     `json:"next_attempt_at" gorm:"index:idx_notification_delivery_due"`
+	LastError      string        `json:"last_error"`
+	SentAt         *time.Time    `json:"sent_at"`
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SalePriceChange ประวัติราคาของประกาศขาย (บันทึกตอนเปิดขายและทุกครั้งที่เปลี่ยนราคา)
+type SalePriceChange struct {
+	gorm.Model
+	SaleListID uint    `json:"sale_list_id" gorm:"index"`
+	CarID      uint    `json:"car_id" gorm:"index"`
+	OldPrice   float64 `json:"old_price"` // 0 = ราคาตอนเปิดขาย
+	NewPrice   float64 `json:"new_price"`
+	ManagerID  *uint   `json:"manager_id"`
+}
+
+// ประเภทการติดตามราคา
+const (
+	PriceAlertCar    = "car"    // ติดตามรถคันเดียว แจ้งเมื่อราคาลด
+	PriceAlertSearch = "search" // ติดตาม SavedSearch แจ้งเมื่อมีรถตรงเงื่อนไขเปิดขาย
+)
+
+// เหตุการณ์ที่แจ้งลูกค้า
+const (
+	PriceAlertPriceDrop  = "price_drop"
+	PriceAlertNewListing = "new_listing"
+)
+
+// PriceAlert การติดตามราคาของลูกค้า
+type PriceAlert struct {
+	gorm.Model
+	CustomerID    uint   `json:"customer_id" gorm:"index"`
+	Kind          string `json:"kind"`
+	CarID         *uint  `json:"car_id" gorm:"index"`
+	SavedSearchID *uint  `json:"saved_search_id" gorm:"index"` // kind = search ใช้ตัวกรองของ SavedSearch
+
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+}
+
+// PriceAlertHit การแจ้งเตือนที่เกิดขึ้นจาก PriceAlert
+type PriceAlertHit struct {
+	gorm.Model
+	PriceAlertID uint    `json:"price_alert_id" gorm:"index"`
+	CustomerID   uint    `json:"customer_id" gorm:"index"`
+	Event        string  `json:"event"`
+	CarID        uint    `json:"car_id" gorm:"index"`
+	SaleListID   uint    `json:"sale_list_id"`
+	OldPrice     float64 `json:"old_price"`
+	NewPrice     float64 `json:"new_price"`
+}
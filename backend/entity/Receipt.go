@@ -11,7 +11,7 @@ type Receipt struct {
 
 	ReceiptNumber string    `json:"receiptnumber" gorm:"uniqueIndex"`
 	IssueDate     time.Time `json:"issuedate"`
-	Link          string    `json:"link"`
+	Link          string    `json:"link"` // key ของไฟล์ใบเสร็จใน storage
 	Status        string    `json:"status"`
 
 	PaymentID uint     `json:"PaymentID"`
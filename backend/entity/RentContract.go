@@ -9,9 +9,11 @@ import (
 type RentContract struct {
 	gorm.Model
 
-	// PriceAgree float64   `json:"price_agree"`
-	DateStart time.Time `json:date_start`
-	DateEnd   time.Time `json:date_end`
+	DateStart time.Time `json:"date_start"`
+	DateEnd   time.Time `json:"date_end"`
+
+	// ราคารวมตามใบเสนอราคาตอนจอง
+	TotalPrice float64 `json:"total_price"`
 
 	RentListID uint      `json:"rent_list_id"`
 	RentList   *RentList `gorm:"foreignKey:RentListID" json:"rent_list"`
@@ -21,4 +23,7 @@ type RentContract struct {
 	Customer   *Customer `gorm:"foreignKey:CustomerID" json:"customer"`
 
 	Payment []*Payment `gorm:"foreignKey:RentContractID" json:"payments"` // plural
+
+	// key ของไฟล์สัญญาที่สแกนไว้ใน storage (ดาวน์โหลดผ่านลิงก์ที่เซ็นแล้วเท่านั้น)
+	DocumentKey string `json:"document_key"`
 }
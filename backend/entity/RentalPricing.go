@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ชนิดของตัวคูณราคาเช่าตามช่วงวัน
+const (
+	RentalAdjustmentHoliday = "holiday"
+	RentalAdjustmentSeason  = "season"
+)
+
+// RentalPriceRule อัตราค่าเช่าต่อวันของรถคันหนึ่ง รุ่น หรือยี่ห้อ
+// ไม่ระบุทั้ง car/model/brand = กฎตั้งต้นของทั้งร้าน
+type RentalPriceRule struct {
+	gorm.Model
+	Name       string `json:"name"`
+	CarID      *uint  `json:"car_id" gorm:"index"`
+	CarModelID *uint  `json:"car_model_id" gorm:"index"`
+	BrandID    *uint  `json:"brand_id" gorm:"index"`
+
+	WeekdayRate float64 `json:"weekday_rate"`
+	WeekendRate float64 `json:"weekend_rate"` // เสาร์-อาทิตย์
+	MinDays     int     `json:"min_days"`     // จำนวนวันเช่าขั้นต่ำ (0 = ไม่กำหนด)
+	Active      bool    `json:"active"`
+
+	ManagerID uint                 `json:"manager_id"`
+	Discounts []RentalDiscountTier `gorm:"foreignKey:RuleID" json:"discounts"`
+}
+
+// RentalDiscountTier ส่วนลดเมื่อเช่าตั้งแต่ MinDays วันขึ้นไป
+type RentalDiscountTier struct {
+	gorm.Model
+	RuleID  uint    `json:"rule_id" gorm:"index"`
+	MinDays int     `json:"min_days"`
+	Percent float64 `json:"percent"`
+}
+
+// RentalPriceAdjustment ตัวคูณราคาในวันหยุดหรือช่วงฤดูกาล (ใช้กับทุกคัน)
+type RentalPriceAdjustment struct {
+	gorm.Model
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"` // holiday, season
+	StartDate  time.Time `json:"start_date" gorm:"index"`
+	EndDate    time.Time `json:"end_date" gorm:"index"`
+	Multiplier float64   `json:"multiplier"`
+}
@@ -19,5 +19,6 @@ type SaleList struct {
 	EmployeeID *uint     `json:"employeeID"` // foreign key -> Employee
 	Employee   *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee"`
 
-	SalesContract []SalesContract `gorm:"foreignKey:SaleListID" json:"sales_contract"`
+	SalesContract []SalesContract   `gorm:"foreignKey:SaleListID" json:"sales_contract"`
+	PriceHistory  []SalePriceChange `gorm:"foreignKey:SaleListID" json:"price_history,omitempty"`
 }
@@ -16,8 +16,14 @@ type SalesContract struct {
 	CustomerID uint
 	Customer   *Customer `gorm:"foreignKey:CustomerID"`
 
+	// ราคาขายตอนทำสัญญา (ราคาในประกาศขายยังแก้ได้ภายหลัง)
+	SalePrice float64 `json:"sale_price"`
+
 	// เปลี่ยนเป็น pointer slice และระบุ foreignKey
 	InspectionAppointments []*InspectionAppointment `gorm:"foreignKey:SalesContractID"`
 
 	Payment []*Payment `gorm:"foreignKey:SalesContractID"`
+
+	// key ของไฟล์สัญญาที่สแกนไว้ใน storage (ดาวน์โหลดผ่านลิงก์ที่เซ็นแล้วเท่านั้น)
+	DocumentKey string `json:"document_key"`
 }
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สถานะตารางกะ
+const (
+	RosterStatusDraft     = "draft"
+	RosterStatusPublished = "published"
+)
+
+// ShiftTemplate รูปแบบกะที่ใช้ซ้ำ เวลาเป็น HH:MM ตามเวลาท้องถิ่น (EndTime น้อยกว่า StartTime = ข้ามเที่ยงคืน)
+type ShiftTemplate struct {
+	gorm.Model
+	Name         string `json:"name"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	BreakMinutes int    `json:"break_minutes"` // หักออกจากชั่วโมงทำงาน
+	GraceMinutes int    `json:"grace_minutes"` // เข้างานช้ากว่านี้นับว่าสาย
+	Active       bool   `json:"active"`
+	ManagerID    uint   `json:"manager_id"`
+}
+
+// Roster ตารางกะรายสัปดาห์ (เริ่มวันจันทร์) พนักงานเห็นเมื่อเผยแพร่แล้วเท่านั้น
+type Roster struct {
+	gorm.Model
+	WeekStart     time.Time  `json:"week_start" gorm:"uniqueIndex"`
+	Status        string     `json:"status" gorm:"default:'draft'"`
+	PublishedAt   *time.Time `json:"published_at"`
+	PublishedByID *uint      `json:"published_by_id"`
+	ManagerID     uint       `json:"manager_id"`
+
+	Shifts []RosterShift `gorm:"foreignKey:RosterID" json:"shifts,omitempty"`
+}
+
+// RosterShift กะของพนักงานหนึ่งคนในหนึ่งวัน StartAt/EndAt คำนวณจาก template ตอนจัดกะ
+type RosterShift struct {
+	gorm.Model
+	RosterID        uint           `json:"roster_id" gorm:"index"`
+	EmployeeID      uint           `json:"employee_id" gorm:"index"`
+	Employee        *Employee      `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+	ShiftTemplateID uint           `json:"shift_template_id"`
+	ShiftTemplate   *ShiftTemplate `gorm:"foreignKey:ShiftTemplateID" json:"shift_template,omitempty"`
+	Date            time.Time      `json:"date" gorm:"index"`
+	StartAt         time.Time      `json:"start_at"`
+	EndAt           time.Time      `json:"end_at"`
+
+	// ตรงกับวันลาที่อนุมัติแล้ว (คำนวณตอนอ่าน)
+	OnLeave bool   `json:"on_leave" gorm:"-"`
+	LeaveID string `json:"leave_id,omitempty" gorm:"-"`
+}
+
+// Attendance การลงเวลาเข้า-ออกงาน
+type Attendance struct {
+	gorm.Model
+	EmployeeID    uint         `json:"employee_id" gorm:"index"`
+	Employee      *Employee    `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+	RosterShiftID *uint        `json:"roster_shift_id"` // nil = เข้างานนอกตาราง
+	RosterShift   *RosterShift `gorm:"foreignKey:RosterShiftID" json:"roster_shift,omitempty"`
+	ClockIn       time.Time    `json:"clock_in" gorm:"index"`
+	ClockOut      *time.Time   `json:"clock_out"`
+	LateMinutes   int          `json:"late_minutes"`
+	WorkedMinutes int          `json:"worked_minutes"`
+	Note          string       `json:"note"`
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สถานะการนัดทดลองขับ
+const (
+	TestDriveRequested = "requested"
+	TestDriveConfirmed = "confirmed"
+	TestDriveCompleted = "completed"
+	TestDriveNoShow    = "no_show"
+	TestDriveCancelled = "cancelled"
+)
+
+// TestDrive นัดทดลองขับรถที่ประกาศขาย
+type TestDrive struct {
+	gorm.Model
+
+	SaleListID uint      `json:"sale_list_id" gorm:"index"`
+	SaleList   *SaleList `gorm:"foreignKey:SaleListID" json:"sale_list,omitempty"`
+	CarID      uint      `json:"car_id" gorm:"index"` // เก็บไว้ตรวจการชนกับการเช่า/ส่งมอบรถคันเดียวกัน
+
+	CustomerID uint      `json:"customer_id" gorm:"index"`
+	Customer   *Customer `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+
+	// พนักงานที่พาทดลองขับ (ต้องมีก่อนยืนยันนัด)
+	EmployeeID *uint     `json:"employee_id" gorm:"index"`
+	Employee   *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+
+	StartAt time.Time `json:"start_at" gorm:"index"`
+	EndAt   time.Time `json:"end_at"`
+
+	// ข้อมูลใบขับขี่
+	LicenseNumber string    `json:"license_number"`
+	LicenseExpiry time.Time `json:"license_expiry"`
+
+	Status     string `json:"status" gorm:"index;default:'requested'"`
+	Note       string `json:"note"`
+	StatusNote string `json:"status_note"`
+}
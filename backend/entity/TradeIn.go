@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สถานะรถเทิร์น
+const (
+	TradeInSubmitted = "submitted" // ลูกค้าส่งข้อมูลแล้ว รอประเมิน
+	TradeInAppraised = "appraised" // พนักงานประเมินแล้ว รอผู้จัดการอนุมัติราคา
+	TradeInOffered   = "offered"   // ผู้จัดการอนุมัติราคาเสนอ รอลูกค้าตอบรับ
+	TradeInAccepted  = "accepted"  // ลูกค้ารับข้อเสนอ รถเข้าสต็อกแล้ว
+	TradeInDeclined  = "declined"  // ลูกค้าปฏิเสธข้อเสนอ
+	TradeInRejected  = "rejected"  // ร้านไม่รับซื้อ
+	TradeInWithdrawn = "withdrawn" // ลูกค้าถอนคำขอ
+)
+
+// ผลตรวจของแต่ละระบบ
+const (
+	TradeInConditionGood = "good"
+	TradeInConditionFair = "fair"
+	TradeInConditionPoor = "poor"
+)
+
+// TradeIn รถเก่าที่ลูกค้านำมาเทิร์นเป็นส่วนหนึ่งของการซื้อรถ
+type TradeIn struct {
+	gorm.Model
+
+	CustomerID uint      `json:"customer_id" gorm:"index"`
+	Customer   *Customer `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+
+	// ข้อมูลรถที่ลูกค้ากรอก
+	BrandName              string  `json:"brand_name"`
+	ModelName              string  `json:"model_name"`
+	SubModelName           string  `json:"sub_model_name"`
+	YearManufacture        int     `json:"year_manufacture"`
+	Color                  string  `json:"color"`
+	Mileage                int     `json:"mileage"`
+	VIN                    string  `json:"vin"`
+	EngineNumber           string  `json:"engine_number"`
+	LicensePlate           string  `json:"license_plate"`
+	RegistrationProvinceID uint    `json:"registration_province_id"`
+	Description            string  `json:"description"`
+	AskingPrice            float64 `json:"asking_price"`
+
+	Photos []TradeInPhoto `gorm:"foreignKey:TradeInID" json:"photos"`
+
+	// การประเมินของพนักงาน
+	EmployeeID     *uint                  `json:"employee_id"`
+	Employee       *Employee              `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+	AppraisedValue float64                `json:"appraised_value"`
+	AppraisalNote  string                 `json:"appraisal_note"`
+	AppraisedAt    *time.Time             `json:"appraised_at"`
+	Items          []TradeInAppraisalItem `gorm:"foreignKey:TradeInID" json:"items"`
+
+	// ราคาที่ผู้จัดการอนุมัติ
+	ManagerID      *uint      `json:"manager_id"`
+	OfferAmount    float64    `json:"offer_amount"`
+	OfferNote      string     `json:"offer_note"`
+	OfferedAt      *time.Time `json:"offered_at"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+
+	Status       string `json:"status" gorm:"index;default:'submitted'"`
+	ReasonClosed string `json:"reason_closed"` // เหตุผลเมื่อปฏิเสธ/ถอน
+
+	// ผลเมื่อรับข้อเสนอ: รถคันใหม่ในสต็อก และยอดเครดิตในสัญญาซื้อขาย
+	SalesContractID *uint      `json:"sales_contract_id"`
+	PaymentID       *uint      `json:"payment_id"`
+	CarID           *uint      `json:"car_id"`
+	AcceptedAt      *time.Time `json:"accepted_at"`
+}
+
+// TradeInPhoto รูปรถเทิร์น (เก็บในที่เดียวกับรูปรถ จึงใช้เป็นรูปของรถได้ทันทีเมื่อรับเข้าสต็อก)
+type TradeInPhoto struct {
+	gorm.Model
+	TradeInID uint   `json:"trade_in_id" gorm:"index"`
+	Path      string `json:"path"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// TradeInAppraisalItem ผลตรวจรถเทิร์นตามรายการ CarSystem
+type TradeInAppraisalItem struct {
+	gorm.Model
+	TradeInID   uint       `json:"trade_in_id" gorm:"index"`
+	CarSystemID uint       `json:"car_system_id"`
+	CarSystem   *CarSystem `gorm:"foreignKey:CarSystemID" json:"car_system,omitempty"`
+	Condition   string     `json:"condition"` // good, fair, poor
+	Deduction   float64    `json:"deduction"` // หักราคาจากระบบนี้ (บาท)
+	Note        string     `json:"note"`
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// BOM ให้ Excel อ่านไฟล์เป็น UTF-8 (ภาษาไทยไม่เพี้ยน)
+const utf8BOM = "\ufeff"
+
+// จำนวนแถวก่อน flush ออกไปหา client ระหว่าง stream
+const csvFlushEvery = 200
+
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func NewCSVWriter(w io.Writer) (Writer, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatText(v)
+		if _, ok := v.(string); ok {
+			record[i] = neutralizeFormula(record[i])
+		}
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	c.rows++
+	if c.rows%csvFlushEvery == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// neutralizeFormula เติม ' หน้าข้อความที่ Excel จะตีความเป็นสูตร (CSV formula injection)
+// ใช้กับข้อความเท่านั้น ตัวเลขติดลบยังเป็นตัวเลขตามเดิม
+func neutralizeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriterNeutralizesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSVWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := []interface{}{
+		"=HYPERLINK(\"http://evil\",\"x\")", "+66 81 234 5678", "-2+3", "@SUM(A1)", "\tcmd", "สมหญิง", "", -1500.5, 42,
+	}
+	if err := w.WriteRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), utf8BOM))).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"'=HYPERLINK(\"http://evil\",\"x\")", "'+66 81 234 5678", "'-2+3", "'@SUM(A1)", "'\tcmd", "สมหญิง", "", "-1500.5", "42",
+	}
+	for i := range want {
+		if records[i] != want[i] {
+			t.Errorf("column %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
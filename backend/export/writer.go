@@ -0,0 +1,82 @@
+// Package export เขียนตารางข้อมูลเป็น CSV หรือ XLSX แบบ stream (ไม่ต้องเก็บทั้งไฟล์ในหน่วยความจำ)
+package export
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ชนิดไฟล์ที่รองรับ (ใช้เป็น ?format=)
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("format must be csv or xlsx")
+
+// Writer เขียนหัวตารางหนึ่งครั้งแล้วตามด้วยข้อมูลทีละแถว
+// ค่าในแถวเป็น string, int, int64, uint, float64, bool, time.Time หรือ nil
+// (XLSX เก็บตัวเลขและวันที่เป็นชนิดจริงเพื่อให้ Excel คำนวณได้)
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// NewWriter สร้าง Writer ตาม format
+func NewWriter(format string, w io.Writer, sheetName string) (Writer, error) {
+	switch format {
+	case "", FormatCSV:
+		return NewCSVWriter(w)
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheetName)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ContentType ของแต่ละ format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileExtension ของแต่ละ format
+func FileExtension(format string) string {
+	if format == FormatXLSX {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// formatText แปลงค่าเป็นข้อความสำหรับ CSV
+func formatText(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case uint:
+		return strconv.FormatUint(uint64(x), 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 {
+			return x.Format("2006-01-02")
+		}
+		return x.Format("2006-01-02 15:04:05")
+	}
+	return ""
+}
@@ -1,20 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/configs"
 	"github.com/PanuAutawo/CarTentManagement/backend/controllers"
 	"github.com/PanuAutawo/CarTentManagement/backend/middleware"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
 	"github.com/PanuAutawo/CarTentManagement/backend/setupdata"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// 1. Connect DB
+	// 1. Connect DB, file storage and notification channels
 	configs.ConnectDatabase("car_full_data.db")
+	configs.ConnectStorage()
+	configs.ConnectNotify()
 
 	// 2. Insert mock data
 	setupdata.InsertMockManagers(configs.DB)
@@ -31,19 +36,56 @@ func main() {
 	setupdata.CreateSalesContracts(configs.DB)
 	setupdata.CreatePayments(configs.DB)
 
-	// 3. Create router
+	// 3. Audit log: บันทึกการเขียนข้อมูลหลังใส่ข้อมูลตัวอย่างแล้ว
+	if err := services.RegisterAuditCallbacks(configs.DB); err != nil {
+		log.Fatal("Failed to register audit log:", err)
+	}
+
+	// 4. Build car search index (ควร build ด้วย -tags sqlite_fts5 ดู Makefile)
+	// ถ้าไม่มี FTS5 ค้นหารถด้วย LIKE แทน
+	if err := services.NewCarSearchService(configs.DB).Rebuild(); errors.Is(err, services.ErrFTS5Unavailable) {
+		log.Println("WARNING: car search falls back to LIKE:", err)
+	} else if err != nil {
+		log.Fatal("Failed to build car search index:", err)
+	}
+
+	// 5. Car lifecycle: ตั้งสถานะให้รถเดิม และปรับสถานะรถเช่าตามวันที่ทุกชั่วโมง
+	lifecycleService := services.NewCarLifecycleService(configs.DB)
+	if err := lifecycleService.SyncAll(); err != nil {
+		log.Println("Failed to sync car lifecycle:", err)
+	}
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := lifecycleService.SyncAll(); err != nil {
+				log.Println("Failed to sync car lifecycle:", err)
+			}
+		}
+	}()
+
+	// 6. Notifications: ส่งอีเมล/LINE/SMS ที่ค้างในคิวทุก 30 วินาที
+	notificationService := services.NewNotificationService(configs.DB, configs.Channels)
+	go func() {
+		for range time.Tick(30 * time.Second) {
+			if _, err := notificationService.ProcessDue(context.Background()); err != nil {
+				log.Println("Failed to send notifications:", err)
+			}
+		}
+	}()
+
+	// 7. Create router
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:5174"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count", "X-Page", "X-Limit", "X-Next-Cursor", "X-Unread-Count"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	r.Use(middleware.AuditActor())
 
 	// --- Controllers Setup ---
-	carController := controllers.NewCarController(configs.DB)
+	carController := controllers.NewCarController(configs.DB, configs.Storage)
 	inspectionAppointmentController := controllers.NewInspectionAppointmentController(configs.DB)
 	carSystemController := controllers.NewCarSystemController(configs.DB)
 	pickupDeliveryController := controllers.NewPickupDeliveryController(configs.DB)
@@ -60,6 +102,27 @@ func main() {
 	rentContractController := controllers.NewRentContractController(configs.DB)
 	saleController := controllers.NewSaleController(configs.DB)
 	buyCarController := controllers.NewBuyCarController(configs.DB)
+	imageController := controllers.NewImageController(configs.Storage)
+	documentController := controllers.NewDocumentController(configs.DB, configs.Storage)
+	catalogController := controllers.NewCatalogController(configs.DB)
+	carImportController := controllers.NewCarImportController(configs.DB)
+	paymentController := controllers.NewPaymentController(configs.DB)
+	carExpenseController := controllers.NewCarExpenseController(configs.DB)
+	reportController := controllers.NewReportController(configs.DB)
+	exportController := controllers.NewExportController(configs.DB)
+	commissionController := controllers.NewCommissionController(configs.DB)
+	rosterController := controllers.NewRosterController(configs.DB)
+	attendanceController := controllers.NewAttendanceController(configs.DB)
+	leadController := controllers.NewLeadController(configs.DB)
+	testDriveController := controllers.NewTestDriveController(configs.DB)
+	tradeInController := controllers.NewTradeInController(configs.DB, configs.Storage)
+	rentalPricingController := controllers.NewRentalPricingController(configs.DB)
+	maintenanceController := controllers.NewMaintenanceController(configs.DB)
+	carLifecycleController := controllers.NewCarLifecycleController(configs.DB)
+	auditController := controllers.NewAuditController(configs.DB)
+	priceAlertController := controllers.NewPriceAlertController(configs.DB)
+	favouriteController := controllers.NewFavouriteController(configs.DB)
+	notificationController := controllers.NewNotificationController(configs.DB, configs.Channels)
 	// --- Routes ---
 
 	// Public Routes
@@ -69,10 +132,230 @@ func main() {
 	r.POST("/manager/login", managerController.LoginManager)
 	r.GET("/employees", employeeController.GetEmployees) // 👈 เพิ่มบรรทัดนี้
 
-	r.Static("/images/cars", "./public/images/cars")
+	r.GET("/images/cars/:name", imageController.ServeCarImage)
+	r.GET("/files/*key", documentController.ServeSignedFile)
 	// Car Routes
-	r.GET("/cars", carController.GetAllCars)
-	r.GET("/cars/:id", carController.GetCarByID)
+	r.GET("/cars", middleware.OptionalCustomerMiddleware(), carController.GetAllCars)
+	r.GET("/cars/search", middleware.OptionalCustomerMiddleware(), carController.SearchCars)
+	r.GET("/cars/:id", middleware.OptionalCustomerMiddleware(), carController.GetCarByID)
+
+	// Car Inventory Routes (Manager)
+	carManagerRoutes := r.Group("/cars")
+	carManagerRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		carManagerRoutes.POST("", carController.CreateCar)
+		carManagerRoutes.POST("/import", carImportController.ImportCars)
+		carManagerRoutes.GET("/imports", carImportController.ListImports)
+		carManagerRoutes.GET("/imports/:id/result", carImportController.DownloadImportResult)
+		carManagerRoutes.PUT("/:id", carController.UpdateCar)
+		carManagerRoutes.DELETE("/:id", carController.DeleteCar)
+		carManagerRoutes.POST("/:id/pictures", carController.UploadCarPictures)
+		carManagerRoutes.PUT("/:id/pictures/order", carController.ReorderCarPictures)
+		carManagerRoutes.DELETE("/:id/pictures/:pictureId", carController.DeleteCarPicture)
+		carManagerRoutes.GET("/:id/expenses", carExpenseController.ListExpenses)
+		carManagerRoutes.POST("/:id/expenses", carExpenseController.CreateExpense)
+		carManagerRoutes.PUT("/:id/expenses/:expenseId", carExpenseController.UpdateExpense)
+		carManagerRoutes.DELETE("/:id/expenses/:expenseId", carExpenseController.DeleteExpense)
+		carManagerRoutes.GET("/:id/profit", carExpenseController.GetProfit)
+		carManagerRoutes.GET("/:id/lifecycle", carLifecycleController.GetLifecycle)
+		carManagerRoutes.PUT("/:id/lifecycle", carLifecycleController.SetLifecycle)
+		carManagerRoutes.GET("/:id/maintenance", maintenanceController.ListCarMaintenance)
+		carManagerRoutes.POST("/:id/maintenance", maintenanceController.CreateMaintenance)
+		carManagerRoutes.GET("/:id/service-reminders", maintenanceController.ListCarReminders)
+		carManagerRoutes.POST("/:id/service-reminders", maintenanceController.CreateReminder)
+	}
+	// Vehicle Catalog Routes (Brand → Model → SubModel)
+	catalogRoutes := r.Group("/catalog")
+	{
+		catalogRoutes.GET("/brands", catalogController.ListBrands)
+		catalogRoutes.GET("/brands/:id/models", catalogController.ListModels)
+		catalogRoutes.GET("/models/:id/sub-models", catalogController.ListSubModels)
+	}
+	catalogManagerRoutes := r.Group("/catalog")
+	catalogManagerRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		catalogManagerRoutes.GET("/duplicates", catalogController.ListDuplicates)
+		catalogManagerRoutes.POST("/brands", catalogController.CreateBrand)
+		catalogManagerRoutes.PUT("/brands/:id", catalogController.UpdateBrand)
+		catalogManagerRoutes.DELETE("/brands/:id", catalogController.DeleteBrand)
+		catalogManagerRoutes.POST("/brands/:id/merge", catalogController.MergeBrands)
+		catalogManagerRoutes.POST("/brands/:id/models", catalogController.CreateModel)
+		catalogManagerRoutes.PUT("/models/:id", catalogController.UpdateModel)
+		catalogManagerRoutes.DELETE("/models/:id", catalogController.DeleteModel)
+		catalogManagerRoutes.POST("/models/:id/merge", catalogController.MergeModels)
+		catalogManagerRoutes.POST("/models/:id/sub-models", catalogController.CreateSubModel)
+		catalogManagerRoutes.PUT("/sub-models/:id", catalogController.UpdateSubModel)
+		catalogManagerRoutes.DELETE("/sub-models/:id", catalogController.DeleteSubModel)
+		catalogManagerRoutes.POST("/sub-models/:id/merge", catalogController.MergeSubModels)
+	}
+
+	// Private Document Routes (สัญญา/ใบเสร็จ)
+	documentRoutes := r.Group("/documents")
+	documentRoutes.Use(middleware.StaffAuthMiddleware())
+	{
+		documentRoutes.POST("/:kind/:id", documentController.UploadDocument)
+		documentRoutes.GET("/:kind/:id/url", documentController.GetDocumentURL)
+	}
+
+	// Report Routes (Manager) ?date_from=&date_to=
+	reportRoutes := r.Group("/reports")
+	reportRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		reportRoutes.GET("/summary", reportController.GetSummary)
+		reportRoutes.GET("/sales", reportController.GetSalesByPeriod)
+		reportRoutes.GET("/top-sellers", reportController.GetTopSellers)
+		reportRoutes.GET("/rental-utilization", reportController.GetRentalUtilization)
+		reportRoutes.GET("/outstanding-payments", reportController.GetOutstandingPayments)
+		reportRoutes.GET("/employees", reportController.GetEmployeePerformance)
+		reportRoutes.GET("/popular-cars", reportController.GetPopularCars)
+		reportRoutes.GET("/attendance", attendanceController.GetAttendanceReport)
+		reportRoutes.GET("/lateness", attendanceController.GetLatenessReport)
+		reportRoutes.GET("/leads", leadController.GetLeadStats)
+	}
+
+	// Commission Routes (Manager)
+	commissionRoutes := r.Group("/commissions")
+	commissionRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		commissionRoutes.GET("/rules", commissionController.ListRules)
+		commissionRoutes.POST("/rules", commissionController.CreateRule)
+		commissionRoutes.PUT("/rules/:id", commissionController.UpdateRule)
+		commissionRoutes.DELETE("/rules/:id", commissionController.DeleteRule)
+		commissionRoutes.GET("/statements", commissionController.ListStatements)
+		commissionRoutes.POST("/statements/generate", commissionController.GenerateStatements)
+		commissionRoutes.GET("/statements/:id", commissionController.GetStatement)
+		commissionRoutes.POST("/statements/:id/approve", commissionController.ApproveStatement)
+	}
+
+	// Rental Pricing Routes (Manager)
+	rentalPricingRoutes := r.Group("/rental-pricing")
+	rentalPricingRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		rentalPricingRoutes.GET("/rules", rentalPricingController.ListRules)
+		rentalPricingRoutes.POST("/rules", rentalPricingController.CreateRule)
+		rentalPricingRoutes.PUT("/rules/:id", rentalPricingController.UpdateRule)
+		rentalPricingRoutes.DELETE("/rules/:id", rentalPricingController.DeleteRule)
+		rentalPricingRoutes.GET("/adjustments", rentalPricingController.ListAdjustments)
+		rentalPricingRoutes.POST("/adjustments", rentalPricingController.CreateAdjustment)
+		rentalPricingRoutes.PUT("/adjustments/:id", rentalPricingController.UpdateAdjustment)
+		rentalPricingRoutes.DELETE("/adjustments/:id", rentalPricingController.DeleteAdjustment)
+	}
+
+	// Maintenance Routes (Manager) รถที่ติดซ่อมจะไม่เปิดขาย/ให้เช่า
+	maintenanceRoutes := r.Group("/maintenance")
+	maintenanceRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		maintenanceRoutes.GET("", maintenanceController.ListMaintenance)
+		maintenanceRoutes.GET("/:id", maintenanceController.GetMaintenance)
+		maintenanceRoutes.PUT("/:id", maintenanceController.UpdateMaintenance)
+		maintenanceRoutes.POST("/:id/start", maintenanceController.StartMaintenance)
+		maintenanceRoutes.POST("/:id/complete", maintenanceController.CompleteMaintenance)
+		maintenanceRoutes.POST("/:id/cancel", maintenanceController.CancelMaintenance)
+	}
+	serviceReminderRoutes := r.Group("/service-reminders")
+	serviceReminderRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		serviceReminderRoutes.GET("/due", maintenanceController.ListDueReminders)
+		serviceReminderRoutes.PUT("/:id", maintenanceController.UpdateReminder)
+		serviceReminderRoutes.DELETE("/:id", maintenanceController.DeleteReminder)
+	}
+
+	// Audit Log Routes (Manager)
+	auditRoutes := r.Group("/audit-logs")
+	auditRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		auditRoutes.GET("", auditController.ListAuditLogs)
+		auditRoutes.GET("/entities", auditController.ListAuditEntities)
+	}
+
+	// Notification Routes (ผู้ใช้ทุกประเภทเห็นเฉพาะกล่องของตัวเอง)
+	notificationRoutes := r.Group("/notifications")
+	notificationRoutes.Use(middleware.AnyUserAuthMiddleware())
+	{
+		notificationRoutes.GET("", notificationController.ListNotifications)
+		notificationRoutes.POST("/read-all", notificationController.MarkAllNotificationsRead)
+		notificationRoutes.POST("/:id/read", notificationController.MarkNotificationRead)
+		notificationRoutes.GET("/preferences", notificationController.GetNotificationPreference)
+		notificationRoutes.PUT("/preferences", notificationController.UpdateNotificationPreference)
+	}
+	// Notification Delivery Routes (Manager) ดู/ส่งใหม่รายการที่ส่งไม่สำเร็จ
+	deliveryRoutes := r.Group("/notification-deliveries")
+	deliveryRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		deliveryRoutes.GET("", notificationController.ListDeliveries)
+		deliveryRoutes.POST("/process", notificationController.ProcessDeliveries)
+		deliveryRoutes.POST("/:id/retry", notificationController.RetryDelivery)
+	}
+
+	// Shift & Roster Routes (Manager)
+	shiftTemplateRoutes := r.Group("/shift-templates")
+	shiftTemplateRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		shiftTemplateRoutes.GET("", rosterController.ListTemplates)
+		shiftTemplateRoutes.POST("", rosterController.CreateTemplate)
+		shiftTemplateRoutes.PUT("/:id", rosterController.UpdateTemplate)
+		shiftTemplateRoutes.DELETE("/:id", rosterController.DeleteTemplate)
+	}
+	rosterRoutes := r.Group("/rosters")
+	rosterRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		rosterRoutes.GET("", rosterController.GetRosterByWeek)
+		rosterRoutes.POST("", rosterController.CreateRoster)
+		rosterRoutes.GET("/:id", rosterController.GetRoster)
+		rosterRoutes.POST("/:id/shifts", rosterController.AddShift)
+		rosterRoutes.DELETE("/:id/shifts/:shiftId", rosterController.DeleteShift)
+		rosterRoutes.POST("/:id/publish", rosterController.PublishRoster)
+	}
+	r.GET("/attendances", middleware.ManagerAuthMiddleware(), attendanceController.ListAttendances)
+
+	// Lead CRM Routes (Staff)
+	leadRoutes := r.Group("/leads")
+	leadRoutes.Use(middleware.StaffAuthMiddleware())
+	{
+		leadRoutes.GET("", leadController.ListLeads)
+		leadRoutes.POST("", leadController.CreateLead)
+		leadRoutes.GET("/tasks", leadController.ListTasks)
+		leadRoutes.GET("/:id", leadController.GetLead)
+		leadRoutes.PUT("/:id", leadController.UpdateLead)
+		leadRoutes.PUT("/:id/stage", leadController.ChangeStage)
+		leadRoutes.POST("/:id/activities", leadController.AddActivity)
+		leadRoutes.POST("/:id/tasks", leadController.AddTask)
+		leadRoutes.POST("/:id/tasks/:taskId/complete", leadController.CompleteTask)
+		leadRoutes.POST("/:id/convert", leadController.ConvertLead)
+	}
+
+	// Test Drive Routes (slots เปิดสาธารณะ ที่เหลือเป็นของฝั่งร้าน)
+	r.GET("/test-drives/slots", testDriveController.GetSlots)
+	testDriveRoutes := r.Group("/test-drives")
+	testDriveRoutes.Use(middleware.StaffAuthMiddleware())
+	{
+		testDriveRoutes.GET("", testDriveController.ListTestDrives)
+		testDriveRoutes.GET("/:id", testDriveController.GetTestDrive)
+		testDriveRoutes.PUT("/:id/assign", testDriveController.AssignTestDrive)
+		testDriveRoutes.PUT("/:id/status", testDriveController.UpdateTestDriveStatus)
+	}
+
+	// Trade-in Routes (ประเมินโดยพนักงาน อนุมัติราคาโดยผู้จัดการ)
+	tradeInRoutes := r.Group("/trade-ins")
+	tradeInRoutes.Use(middleware.StaffAuthMiddleware())
+	{
+		tradeInRoutes.GET("", tradeInController.ListTradeIns)
+		tradeInRoutes.GET("/:id", tradeInController.GetTradeIn)
+		tradeInRoutes.PUT("/:id/appraisal", tradeInController.AppraiseTradeIn)
+		tradeInRoutes.POST("/:id/offer", middleware.ManagerAuthMiddleware(), tradeInController.OfferTradeIn)
+		tradeInRoutes.POST("/:id/reject", middleware.ManagerAuthMiddleware(), tradeInController.RejectTradeIn)
+	}
+
+	// Export Routes (Manager) ?format=csv|xlsx
+	exportRoutes := r.Group("/exports")
+	exportRoutes.Use(middleware.ManagerAuthMiddleware())
+	{
+		exportRoutes.GET("/cars", exportController.ExportCars)
+		exportRoutes.GET("/sales-contracts", exportController.ExportSalesContracts)
+		exportRoutes.GET("/rent-contracts", exportController.ExportRentContracts)
+		exportRoutes.GET("/payments", exportController.ExportPayments)
+		exportRoutes.GET("/employees", exportController.ExportEmployees)
+	}
 	// Address Routes
 	provinceRoutes := r.Group("/provinces")
 	{
@@ -104,6 +387,26 @@ func main() {
 	customerRoutes.Use(middleware.CustomerAuthMiddleware())
 	{
 		customerRoutes.GET("/me", customerController.GetCurrentCustomer)
+		customerRoutes.GET("/me/test-drives", testDriveController.GetMyTestDrives)
+		customerRoutes.POST("/me/test-drives", testDriveController.BookTestDrive)
+		customerRoutes.POST("/me/test-drives/:id/cancel", testDriveController.CancelMyTestDrive)
+		customerRoutes.GET("/me/trade-ins", tradeInController.GetMyTradeIns)
+		customerRoutes.POST("/me/trade-ins", tradeInController.SubmitTradeIn)
+		customerRoutes.GET("/me/trade-ins/:id", tradeInController.GetMyTradeIn)
+		customerRoutes.POST("/me/trade-ins/:id/photos", tradeInController.UploadTradeInPhotos)
+		customerRoutes.POST("/me/trade-ins/:id/accept", tradeInController.AcceptTradeIn)
+		customerRoutes.POST("/me/trade-ins/:id/decline", tradeInController.DeclineTradeIn)
+		customerRoutes.GET("/me/price-alerts", priceAlertController.ListMyPriceAlerts)
+		customerRoutes.POST("/me/price-alerts", priceAlertController.CreatePriceAlert)
+		customerRoutes.GET("/me/price-alerts/hits", priceAlertController.ListMyPriceAlertHits)
+		customerRoutes.DELETE("/me/price-alerts/:id", priceAlertController.DeletePriceAlert)
+		customerRoutes.GET("/me/favourites", favouriteController.ListFavourites)
+		customerRoutes.PUT("/me/favourites/:carId", favouriteController.AddFavourite)
+		customerRoutes.DELETE("/me/favourites/:carId", favouriteController.RemoveFavourite)
+		customerRoutes.GET("/me/saved-searches", favouriteController.ListSavedSearches)
+		customerRoutes.POST("/me/saved-searches", favouriteController.CreateSavedSearch)
+		customerRoutes.PUT("/me/saved-searches/:id", favouriteController.UpdateSavedSearch)
+		customerRoutes.DELETE("/me/saved-searches/:id", favouriteController.DeleteSavedSearch)
 	}
 
 	// Protected Employee Routes
@@ -112,12 +415,25 @@ func main() {
 	{
 		employeeProtectedRoutes.GET("/me", employeeController.GetCurrentEmployee)
 		employeeProtectedRoutes.PUT("/me", employeeController.UpdateCurrentEmployee)
+		employeeProtectedRoutes.GET("/me/commissions", commissionController.GetMyStatements)
+		employeeProtectedRoutes.GET("/me/shifts", rosterController.GetMyShifts)
+		employeeProtectedRoutes.GET("/me/attendance", attendanceController.GetMyAttendance)
+		employeeProtectedRoutes.POST("/me/clock-in", attendanceController.ClockIn)
+		employeeProtectedRoutes.POST("/me/clock-out", attendanceController.ClockOut)
 	}
 
 	// RentContract Routes
 	rentContractRoutes := r.Group("/rent-contracts")
 	{
 		rentContractRoutes.POST("", rentContractController.CreateRentContract)
+		rentContractRoutes.GET("", middleware.StaffAuthMiddleware(), rentContractController.GetRentContracts)
+		rentContractRoutes.GET("/:id/document", middleware.StaffAuthMiddleware(), rentContractController.GetRentContractDocument)
+	}
+	// Payment Routes (Staff)
+	paymentRoutes := r.Group("/payments")
+	paymentRoutes.Use(middleware.StaffAuthMiddleware())
+	{
+		paymentRoutes.GET("", paymentController.GetPayments)
 	}
 	// SalesContract Routes
 	salesContractRoutes := r.Group("/sales-contracts")
@@ -126,6 +442,7 @@ func main() {
 		salesContractRoutes.POST("", salesContractController.CreateSalesContract)
 		salesContractRoutes.GET("", salesContractController.GetSalesContracts)
 		salesContractRoutes.GET("/:id", salesContractController.GetSalesContractByID)
+		salesContractRoutes.GET("/:id/document", middleware.StaffAuthMiddleware(), salesContractController.GetSalesContractDocument)
 		salesContractRoutes.PUT("/:id", salesContractController.UpdateSalesContract)
 		salesContractRoutes.DELETE("/:id", salesContractController.DeleteSalesContract)
 		salesContractRoutes.GET("/employee/:employeeID", salesContractController.GetSalesContractsByEmployeeID)
@@ -175,10 +492,12 @@ func main() {
 	{
 
 		// Leave Routes
-		api.GET("/leaves", leaveController.ListLeaves)
-		api.GET("/employees/:id/leaves", leaveController.ListLeavesByEmployee)
-		api.POST("/leaves", leaveController.CreateLeave)
-		api.PUT("/leaves/:id/status", leaveController.UpdateLeaveStatus)
+		api.GET("/leaves", middleware.StaffAuthMiddleware(), leaveController.ListLeaves)
+		api.GET("/employees/:id/leaves", middleware.StaffAuthMiddleware(), leaveController.ListLeavesByEmployee)
+		api.POST("/leaves", middleware.EmployeeAuthMiddleware(), leaveController.CreateLeave)
+		api.PUT("/leaves/:id/status", middleware.ManagerAuthMiddleware(), leaveController.UpdateLeaveStatus)
+		api.GET("/employees/:id/leave-balances", middleware.StaffAuthMiddleware(), leaveController.GetLeaveBalances)
+		api.PUT("/employees/:id/leave-balances", middleware.ManagerAuthMiddleware(), leaveController.SetLeaveBalance)
 
 		// Employee CRUD (Manager)
 		api.GET("/employees", employeeController.GetEmployees)
@@ -197,17 +516,19 @@ func main() {
 	}
 	rentListRoutes := r.Group("/rentlists")
 	{
+		rentListRoutes.GET("/calendar", rentalPricingController.GetCalendar)
 		rentListRoutes.GET("/:carId", rentListController.GetRentListsByCar)
+		rentListRoutes.GET("/:carId/quote", rentalPricingController.GetQuote)
 		rentListRoutes.PUT("", rentListController.CreateOrUpdateRentList)
 		rentListRoutes.DELETE("/date/:dateId", rentListController.DeleteRentDate)
 		rentListRoutes.POST("/book/:carId", rentListController.BookCar) // เพิ่ม BookCar
 	}
 	saleControllerRoutes := r.Group("/sale")
 	{
-		saleControllerRoutes.GET("/cars", saleController.GetCarsWithSale) // GET /sale/cars
-		saleControllerRoutes.GET("/:id", saleController.GetSaleByID)      // GET /sale/:id
-		saleControllerRoutes.POST("", saleController.CreateSale)          // POST /sale
-		saleControllerRoutes.PUT("/:id", saleController.UpdateSale)       // PUT /sale/:id
+		saleControllerRoutes.GET("/cars", saleController.GetCarsWithSale)                               // GET /sale/cars
+		saleControllerRoutes.GET("/:id", saleController.GetSaleByID)                                    // GET /sale/:id
+		saleControllerRoutes.POST("", middleware.ManagerAuthMiddleware(), saleController.CreateSale)    // POST /sale
+		saleControllerRoutes.PUT("/:id", middleware.ManagerAuthMiddleware(), saleController.UpdateSale) // PUT /sale/:id
 	}
 	r.POST("/bycar/buy/:carID", buyCarController.BuyCar)
 	// Start server
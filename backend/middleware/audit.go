@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// =============================
+// ✅ Middleware ระบุผู้เรียก API สำหรับ audit log (ใช้กับทุก route)
+// ไม่ตรวจสิทธิ์ token ผิด/ไม่มี token = anonymous
+// =============================
+func AuditActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := services.AuditActor{
+			Role:   entity.AuditRoleAnonymous,
+			IP:     c.ClientIP(),
+			Method: c.Request.Method,
+			Path:   c.Request.URL.Path,
+		}
+		if id, role, ok := tokenActor(c.GetHeader("Authorization")); ok {
+			actor.ID = &id
+			actor.Role = role
+		}
+		c.Set(services.AuditContextKey, actor)
+		c.Next()
+	}
+}
+
+func tokenActor(authHeader string) (uint, string, bool) {
+	if authHeader == "" {
+		return 0, "", false
+	}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return hmacSampleSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, "", false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, "", false
+	}
+
+	// token ลูกค้าเก็บ id ไว้ใน "id" ส่วนพนักงาน/manager เก็บใน "employeeID"
+	role, _ := claims["role"].(string)
+	key := "employeeID"
+	switch role {
+	case entity.AuditRoleCustomer:
+		key = "id"
+	case entity.AuditRoleEmployee, entity.AuditRoleManager:
+	default:
+		return 0, "", false
+	}
+	id, ok := claims[key].(float64)
+	if !ok || id <= 0 {
+		return 0, "", false
+	}
+	return uint(id), role, true
+}
@@ -1,10 +1,8 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/configs"
@@ -36,37 +34,73 @@ func GenerateToken(id uint, role string) (string, error) {
 	return token.SignedString(hmacSampleSecret)
 }
 
+// =============================
+// ✅ ตรวจ token และ role ที่อนุญาต: token ผิด/ไม่มี id = 401, role ไม่ตรง = 403
+// =============================
+func authorize(c *gin.Context, roles ...string) (uint, string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+		return 0, "", false
+	}
+	id, role, ok := tokenActor(authHeader)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return 0, "", false
+	}
+	for _, r := range roles {
+		if r == role {
+			return id, role, true
+		}
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	return 0, "", false
+}
+
 // =============================
 // ✅ Middleware ตรวจสอบ Customer
 // =============================
 func CustomerAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+		id, _, ok := authorize(c, "customer")
+		if !ok {
 			return
 		}
+		c.Set("userID", id) // 👈 ถ้าเป็น customer ใช้ userID
+		c.Next()
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return hmacSampleSecret, nil
-		})
+// =============================
+// ✅ Middleware ระบุลูกค้าถ้ามี token (หน้าสาธารณะที่แสดงข้อมูลเฉพาะคนได้ เช่น is_favourite)
+// ไม่มี token หรือไม่ใช่ลูกค้า = ผ่านโดยไม่ตั้ง userID
+// =============================
+func OptionalCustomerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id, role, ok := tokenActor(c.GetHeader("Authorization")); ok && role == "customer" {
+			c.Set("userID", id)
+		}
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+// =============================
+// ✅ Middleware ผู้ใช้ที่ login แล้วทุกประเภท (กล่องแจ้งเตือนที่ใช้ร่วมกัน)
+// ตั้ง "accountRole" (customer/employee/manager) และ "accountID"
+// =============================
+func AnyUserAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
 			return
 		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["role"] != "customer" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		id, role, ok := tokenActor(c.GetHeader("Authorization"))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
-
-		c.Set("userID", uint(claims["employeeID"].(float64))) // 👈 ถ้าเป็น customer ใช้ userID
+		c.Set("accountRole", role)
+		c.Set("accountID", id)
 		c.Next()
 	}
 }
@@ -76,32 +110,44 @@ func CustomerAuthMiddleware() gin.HandlerFunc {
 // =============================
 func EmployeeAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+		id, _, ok := authorize(c, "employee")
+		if !ok {
 			return
 		}
+		c.Set("employeeID", id) // 👈 ตรงนี้สำคัญ
+		c.Next()
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return hmacSampleSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+// =============================
+// ✅ Middleware ตรวจสอบ Manager
+// =============================
+func ManagerAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, _, ok := authorize(c, "manager")
+		if !ok {
 			return
 		}
+		c.Set("managerID", id) // GenerateToken เก็บ id ไว้ใน employeeID
+		c.Next()
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["role"] != "employee" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+// =============================
+// ✅ Middleware ตรวจสอบพนักงานหรือ Manager (เอกสารภายในร้าน)
+// =============================
+func StaffAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, role, ok := authorize(c, "employee", "manager")
+		if !ok {
 			return
 		}
-
-		c.Set("employeeID", uint(claims["employeeID"].(float64))) // 👈 ตรงนี้สำคัญ
+		if role == "employee" {
+			c.Set("employeeID", id)
+		} else {
+			c.Set("managerID", id)
+		}
+		c.Set("role", role)
 		c.Next()
 	}
 }
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// LineDefaultEndpoint push message API ของ LINE Messaging
+const LineDefaultEndpoint = "https://api.line.me/v2/bot/message/push"
+
+// LineConfig ค่าการเชื่อมต่อ LINE (Endpoint เปลี่ยนเป็น stub ในเครื่องได้)
+type LineConfig struct {
+	Endpoint string
+	Token    string // channel access token
+}
+
+// LineChannel ส่งข้อความแบบ push ถึง LINE user ID
+type LineChannel struct {
+	cfg    LineConfig
+	client *http.Client
+}
+
+func NewLineChannel(cfg LineConfig) (*LineChannel, error) {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = LineDefaultEndpoint
+	}
+	if err := validEndpoint(cfg.Endpoint); err != nil {
+		return nil, err
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("LINE channel access token is required")
+	}
+	return &LineChannel{cfg: cfg, client: defaultHTTPClient}, nil
+}
+
+func (l *LineChannel) Send(ctx context.Context, msg Message) error {
+	text := msg.Body
+	if msg.Subject != "" {
+		text = msg.Subject + "\n" + msg.Body
+	}
+	payload := map[string]interface{}{
+		"to":       msg.To,
+		"messages": []map[string]string{{"type": "text", "text": text}},
+	}
+	return postJSON(ctx, l.client, l.cfg.Endpoint, l.cfg.Token, payload)
+}
+
+// SMSConfig ค่าการเชื่อมต่อ SMS gateway ที่รับ JSON {"to","from","message"}
+type SMSConfig struct {
+	Endpoint string
+	Token    string
+	Sender   string
+}
+
+// SMSChannel ส่ง SMS ผ่าน HTTP gateway
+type SMSChannel struct {
+	cfg    SMSConfig
+	client *http.Client
+}
+
+func NewSMSChannel(cfg SMSConfig) (*SMSChannel, error) {
+	if err := validEndpoint(cfg.Endpoint); err != nil {
+		return nil, err
+	}
+	return &SMSChannel{cfg: cfg, client: defaultHTTPClient}, nil
+}
+
+func (s *SMSChannel) Send(ctx context.Context, msg Message) error {
+	payload := map[string]string{
+		"to":      msg.To,
+		"from":    s.cfg.Sender,
+		"message": msg.Body,
+	}
+	return postJSON(ctx, s.client, s.cfg.Endpoint, s.cfg.Token, payload)
+}
+
+func validEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// gatewayStub ตอบด้วย status ที่ตั้งไว้ และเก็บ request ล่าสุดไว้ตรวจ
+type gatewayStub struct {
+	status int
+	auth   string
+	body   map[string]interface{}
+}
+
+func newGatewayStub(t *testing.T) (*gatewayStub, *httptest.Server) {
+	t.Helper()
+	g := &gatewayStub{status: http.StatusOK}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.auth = r.Header.Get("Authorization")
+		g.body = nil
+		json.NewDecoder(r.Body).Decode(&g.body)
+		w.WriteHeader(g.status)
+		w.Write([]byte(`{"message":"stub"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return g, srv
+}
+
+func TestLineChannelSend(t *testing.T) {
+	g, srv := newGatewayStub(t)
+	line, err := NewLineChannel(LineConfig{Endpoint: srv.URL, Token: "line-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := Message{To: "U1234", Subject: "ยืนยันการจองรถเช่า", Body: "การจองได้รับการยืนยันแล้ว"}
+	if err := line.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if g.auth != "Bearer line-token" {
+		t.Errorf("Authorization = %q", g.auth)
+	}
+	if g.body["to"] != "U1234" {
+		t.Errorf("to = %v", g.body["to"])
+	}
+	messages, _ := g.body["messages"].([]interface{})
+	if len(messages) != 1 {
+		t.Fatalf("messages = %v", g.body["messages"])
+	}
+	text := messages[0].(map[string]interface{})["text"]
+	if text != msg.Subject+"\n"+msg.Body {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestSMSChannelSend(t *testing.T) {
+	g, srv := newGatewayStub(t)
+	sms, err := NewSMSChannel(SMSConfig{Endpoint: srv.URL, Token: "sms-token", Sender: "CarTent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sms.Send(context.Background(), Message{To: "0812345678", Subject: "ignored", Body: "รถลดราคา"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	want := map[string]interface{}{"to": "0812345678", "from": "CarTent", "message": "รถลดราคา"}
+	for k, v := range want {
+		if g.body[k] != v {
+			t.Errorf("%s = %v, want %v", k, g.body[k], v)
+		}
+	}
+	if g.auth != "Bearer sms-token" {
+		t.Errorf("Authorization = %q", g.auth)
+	}
+}
+
+func TestGatewayStatusClassification(t *testing.T) {
+	g, srv := newGatewayStub(t)
+	line, err := NewLineChannel(LineConfig{Endpoint: srv.URL, Token: "line-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sms, err := NewSMSChannel(SMSConfig{Endpoint: srv.URL, Sender: "CarTent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channels := map[string]Channel{"line": line, "sms": sms}
+
+	cases := []struct {
+		status    int
+		wantErr   bool
+		permanent bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusAccepted, false, false},
+		{http.StatusBadRequest, true, true},
+		{http.StatusUnauthorized, true, true},
+		{http.StatusNotFound, true, true},
+		{http.StatusRequestTimeout, true, false},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusInternalServerError, true, false},
+		{http.StatusBadGateway, true, false},
+		{http.StatusServiceUnavailable, true, false},
+	}
+	for name, ch := range channels {
+		for _, tc := range cases {
+			g.status = tc.status
+			err := ch.Send(context.Background(), Message{To: "x", Body: "hello"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("%s %d: err = %v, want error %v", name, tc.status, err, tc.wantErr)
+				continue
+			}
+			if err != nil && IsPermanent(err) != tc.permanent {
+				t.Errorf("%s %d: IsPermanent = %v, want %v", name, tc.status, IsPermanent(err), tc.permanent)
+			}
+		}
+	}
+}
+
+func TestGatewayUnreachableIsRetryable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	endpoint := srv.URL
+	srv.Close()
+
+	sms, err := NewSMSChannel(SMSConfig{Endpoint: endpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sms.Send(context.Background(), Message{To: "0812345678", Body: "hello"})
+	if err == nil || IsPermanent(err) {
+		t.Fatalf("closed gateway: got %v, want retryable error", err)
+	}
+}
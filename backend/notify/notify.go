@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Message ข้อความหนึ่งฉบับถึงผู้รับหนึ่งคน
+// To คืออีเมล, LINE user ID หรือเบอร์โทร ตามช่องทาง
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Channel ช่องทางส่งข้อความออกนอกระบบ (email, LINE, SMS)
+type Channel interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// permanentError ส่งซ้ำไปก็ไม่สำเร็จ เช่น ที่อยู่ผิดหรือ token ไม่ถูกต้อง
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent ระบุว่า err ไม่ควรส่งซ้ำ
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent err ที่ไม่ควรส่งซ้ำ (ไม่ใช่ = ลองใหม่ภายหลังได้)
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+var defaultHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// postJSON ส่ง JSON ไปยัง gateway: 4xx ถือว่าถาวร ยกเว้น 408/429 และ 5xx/เครือข่ายล่มให้ลองใหม่
+func postJSON(ctx context.Context, client *http.Client, url, token string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s responded %d: %s", url, resp.StatusCode, bytes.TrimSpace(detail))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTP รับอีเมลแบบไม่มี TLS/AUTH ผู้รับที่อยู่ใน reject จะได้ code ที่กำหนด
+type fakeSMTP struct {
+	mu       sync.Mutex
+	reject   map[string]string // อีเมล -> บรรทัดตอบกลับ เช่น "550 no such user"
+	received []string          // เนื้อหา DATA ของแต่ละฉบับ
+	from, to []string
+}
+
+func newFakeSMTP(t *testing.T) (*fakeSMTP, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeSMTP{reject: map[string]string{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 fake.smtp ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-fake.smtp")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			f.mu.Lock()
+			f.from = append(f.from, envelopeAddr(line[len("MAIL FROM:"):]))
+			f.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			addr := envelopeAddr(line[len("RCPT TO:"):])
+			f.mu.Lock()
+			answer, rejected := f.reject[addr]
+			if !rejected {
+				f.to = append(f.to, addr)
+			}
+			f.mu.Unlock()
+			if rejected {
+				reply(answer)
+			} else {
+				reply("250 OK")
+			}
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			f.mu.Lock()
+			f.received = append(f.received, data.String())
+			f.mu.Unlock()
+			reply("250 OK queued")
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// envelopeAddr "<a@b.c> BODY=8BITMIME" -> "a@b.c"
+func envelopeAddr(arg string) string {
+	start, end := strings.Index(arg, "<"), strings.Index(arg, ">")
+	if start < 0 || end < start {
+		return strings.TrimSpace(arg)
+	}
+	return arg[start+1 : end]
+}
+
+func TestSMTPChannelSend(t *testing.T) {
+	f, addr := newFakeSMTP(t)
+	ch, err := NewSMTPChannel(SMTPConfig{Addr: addr, From: "noreply@cartent.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := Message{To: "somying@example.com", Subject: "ยืนยันนัดทดลองขับ", Body: "นัดทดลองขับ Toyota Camry ได้รับการยืนยันแล้ว"}
+	if err := ch.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(f.received))
+	}
+	if f.from[0] != "noreply@cartent.example" || f.to[0] != "somying@example.com" {
+		t.Errorf("envelope from=%v to=%v", f.from, f.to)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(f.received[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+	if ct := parsed.Header.Get("Content-Type"); ct != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(readAll(t, parsed), "\r\n", ""))
+	if err != nil || string(body) != msg.Body {
+		t.Errorf("body = %q (%v)", body, err)
+	}
+}
+
+func TestSMTPChannelErrors(t *testing.T) {
+	f, addr := newFakeSMTP(t)
+	f.reject["nobody@example.com"] = "550 5.1.1 no such user"
+	f.reject["busy@example.com"] = "451 4.3.0 try again later"
+	ch, err := NewSMTPChannel(SMTPConfig{Addr: addr, From: "noreply@cartent.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ch.Send(context.Background(), Message{To: "nobody@example.com", Subject: "s", Body: "b"})
+	if err == nil || !IsPermanent(err) {
+		t.Errorf("550 recipient: got %v, want permanent error", err)
+	}
+	err = ch.Send(context.Background(), Message{To: "busy@example.com", Subject: "s", Body: "b"})
+	if err == nil || IsPermanent(err) {
+		t.Errorf("451 recipient: got %v, want retryable error", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := ln.Addr().String()
+	ln.Close()
+	down, _ := NewSMTPChannel(SMTPConfig{Addr: closed, From: "noreply@cartent.example"})
+	if err := down.Send(context.Background(), Message{To: "a@example.com"}); err == nil || IsPermanent(err) {
+		t.Errorf("server down: got %v, want retryable error", err)
+	}
+}
+
+func readAll(t *testing.T, m *mail.Message) string {
+	t.Helper()
+	var b strings.Builder
+	if _, err := bufio.NewReader(m.Body).WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAlreadyClockedIn = errors.New("already clocked in; clock out first")
+	ErrNotClockedIn     = errors.New("not clocked in")
+)
+
+// ลงเวลาเข้างานได้ก่อนเริ่มกะไม่เกินช่วงนี้
+const clockInEarlyWindow = 2 * time.Hour
+
+// AttendanceFilter ?employee_id=&date_from=&date_to= (ไม่ระบุวันที่ = ตั้งแต่ต้นเดือนถึงวันนี้)
+type AttendanceFilter struct {
+	EmployeeID uint   `form:"employee_id"`
+	DateFrom   string `form:"date_from"`
+	DateTo     string `form:"date_to"`
+}
+
+// dates คืนช่วงวันที่ YYYY-MM-DD ที่ใช้จริง
+func (f AttendanceFilter) dates() (string, string, error) {
+	y, m, d := time.Now().Date()
+	from := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	to := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	if f.DateFrom != "" {
+		from = f.DateFrom
+	}
+	if f.DateTo != "" {
+		to = f.DateTo
+	}
+	a, errA := time.Parse("2006-01-02", from)
+	b, errB := time.Parse("2006-01-02", to)
+	if errA != nil || errB != nil {
+		return "", "", ErrInvalidDateFilter
+	}
+	if a.After(b) {
+		return "", "", ErrInvalidReportRange
+	}
+	return from, to, nil
+}
+
+// AttendanceSummary ชั่วโมงทำงานและการมาสายของพนักงานในช่วงวันที่
+type AttendanceSummary struct {
+	EmployeeID      uint    `json:"employee_id"`
+	EmployeeName    string  `json:"employee_name"`
+	ScheduledShifts int     `json:"scheduled_shifts"`
+	LeaveShifts     int     `json:"leave_shifts"`    // กะที่ตรงกับวันลาที่อนุมัติ
+	AttendedShifts  int     `json:"attended_shifts"` // กะที่มีการลงเวลา
+	AbsentShifts    int     `json:"absent_shifts"`   // กะที่ผ่านไปแล้ว ไม่ได้ลา และไม่ได้ลงเวลา
+	Unscheduled     int     `json:"unscheduled"`     // ลงเวลานอกตาราง
+	LateCount       int     `json:"late_count"`
+	LateMinutes     int     `json:"late_minutes"`
+	WorkedHours     float64 `json:"worked_hours"`
+	ScheduledHours  float64 `json:"scheduled_hours"` // ไม่รวมกะที่ลาและเวลาพัก
+}
+
+// LatenessEntry การเข้างานสายหนึ่งครั้ง
+type LatenessEntry struct {
+	AttendanceID uint      `json:"attendance_id"`
+	EmployeeID   uint      `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	ShiftName    string    `json:"shift_name"`
+	ShiftStart   time.Time `json:"shift_start"`
+	ClockIn      time.Time `json:"clock_in"`
+	LateMinutes  int       `json:"late_minutes"`
+}
+
+// AttendanceService การลงเวลาเข้า-ออกงานและรายงาน
+type AttendanceService struct {
+	db *gorm.DB
+}
+
+func NewAttendanceService(db *gorm.DB) *AttendanceService {
+	return &AttendanceService{db: db}
+}
+
+func (s *AttendanceService) WithContext(ctx context.Context) *AttendanceService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// ClockIn ลงเวลาเข้างาน ผูกกับกะที่เผยแพร่แล้วซึ่งครอบคลุมเวลาปัจจุบัน (ถ้ามี) และคำนวณนาทีที่สาย
+func (s *AttendanceService) ClockIn(empID uint, note string) (*entity.Attendance, error) {
+	now := time.Now()
+	var att entity.Attendance
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var open int64
+		if err := tx.Model(&entity.Attendance{}).
+			Where("employee_id = ? AND clock_out IS NULL", empID).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrAlreadyClockedIn
+		}
+
+		att = entity.Attendance{EmployeeID: empID, ClockIn: now, Note: strings.TrimSpace(note)}
+		var shifts []entity.RosterShift
+		if err := tx.Model(&entity.RosterShift{}).
+			Joins("JOIN rosters ON rosters.id = roster_shifts.roster_id AND rosters.deleted_at IS NULL").
+			Where("roster_shifts.employee_id = ? AND rosters.status = ?", empID, entity.RosterStatusPublished).
+			Where("roster_shifts.start_at <= ? AND roster_shifts.end_at > ?", now.Add(clockInEarlyWindow), now).
+			Where("NOT EXISTS (SELECT 1 FROM attendances a WHERE a.roster_shift_id = roster_shifts.id AND a.deleted_at IS NULL)").
+			Preload("ShiftTemplate").
+			Order("roster_shifts.start_at ASC").
+			Limit(1).
+			Find(&shifts).Error; err != nil {
+			return err
+		}
+		if len(shifts) > 0 {
+			shift := shifts[0]
+			att.RosterShiftID = &shift.ID
+			att.RosterShift = &shift
+			grace := 0
+			if shift.ShiftTemplate != nil {
+				grace = shift.ShiftTemplate.GraceMinutes
+			}
+			if late := int(now.Sub(shift.StartAt).Minutes()); late > grace {
+				att.LateMinutes = late
+			}
+		}
+		return tx.Omit("RosterShift").Create(&att).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &att, nil
+}
+
+// ClockOut ลงเวลาออกงาน ชั่วโมงทำงานหักเวลาพักของกะ
+func (s *AttendanceService) ClockOut(empID uint) (*entity.Attendance, error) {
+	now := time.Now()
+	var att entity.Attendance
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("RosterShift.ShiftTemplate").
+			Where("employee_id = ? AND clock_out IS NULL", empID).
+			Order("clock_in DESC").
+			First(&att).Error; err != nil {
+			return notFoundAs(err, ErrNotClockedIn)
+		}
+		worked := int(now.Sub(att.ClockIn).Minutes())
+		if att.RosterShift != nil && att.RosterShift.ShiftTemplate != nil {
+			worked -= att.RosterShift.ShiftTemplate.BreakMinutes
+		}
+		if worked < 0 {
+			worked = 0
+		}
+		att.ClockOut = &now
+		att.WorkedMinutes = worked
+		return tx.Model(&att).Updates(map[string]interface{}{
+			"clock_out":      now,
+			"worked_minutes": worked,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &att, nil
+}
+
+// List การลงเวลาตาม filter เรียงล่าสุดก่อน
+func (s *AttendanceService) List(f AttendanceFilter) ([]entity.Attendance, error) {
+	from, to, err := f.dates()
+	if err != nil {
+		return nil, err
+	}
+	q := s.db.Preload("RosterShift.ShiftTemplate")
+	if f.EmployeeID != 0 {
+		q = q.Where("employee_id = ?", f.EmployeeID)
+	}
+	if q, err = applyDateRange(q, "clock_in", from, to); err != nil {
+		return nil, err
+	}
+	items := []entity.Attendance{}
+	err = q.Order("clock_in DESC").Find(&items).Error
+	return items, err
+}
+
+// Summary ชั่วโมงทำงาน การมาสาย การขาด และกะที่ตรงกับวันลา แยกรายพนักงาน
+func (s *AttendanceService) Summary(f AttendanceFilter) ([]AttendanceSummary, error) {
+	from, to, err := f.dates()
+	if err != nil {
+		return nil, err
+	}
+
+	shiftQ := s.db.Model(&entity.RosterShift{}).
+		Joins("JOIN rosters ON rosters.id = roster_shifts.roster_id AND rosters.deleted_at IS NULL").
+		Where("rosters.status = ?", entity.RosterStatusPublished)
+	attQ := s.db.Model(&entity.Attendance{})
+	if f.EmployeeID != 0 {
+		shiftQ = shiftQ.Where("roster_shifts.employee_id = ?", f.EmployeeID)
+		attQ = attQ.Where("employee_id = ?", f.EmployeeID)
+	}
+	if shiftQ, err = applyDateRange(shiftQ, "roster_shifts.date", from, to); err != nil {
+		return nil, err
+	}
+	if attQ, err = applyDateRange(attQ, "clock_in", from, to); err != nil {
+		return nil, err
+	}
+
+	var shifts []entity.RosterShift
+	if err := shiftQ.Preload("ShiftTemplate").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	if err := flagLeaveShifts(s.db, shifts); err != nil {
+		return nil, err
+	}
+	var attendances []entity.Attendance
+	if err := attQ.Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+
+	byEmp := map[uint]*AttendanceSummary{}
+	get := func(id uint) *AttendanceSummary {
+		if byEmp[id] == nil {
+			byEmp[id] = &AttendanceSummary{EmployeeID: id}
+		}
+		return byEmp[id]
+	}
+	attended := map[uint]bool{}
+	for _, a := range attendances {
+		sum := get(a.EmployeeID)
+		sum.WorkedHours += float64(a.WorkedMinutes) / 60
+		if a.RosterShiftID == nil {
+			sum.Unscheduled++
+		} else {
+			attended[*a.RosterShiftID] = true
+		}
+		if a.LateMinutes > 0 {
+			sum.LateCount++
+			sum.LateMinutes += a.LateMinutes
+		}
+	}
+	now := time.Now()
+	for _, sh := range shifts {
+		sum := get(sh.EmployeeID)
+		sum.ScheduledShifts++
+		switch {
+		case attended[sh.ID]:
+			sum.AttendedShifts++
+		case sh.OnLeave:
+		case sh.EndAt.Before(now):
+			sum.AbsentShifts++
+		}
+		if sh.OnLeave {
+			sum.LeaveShifts++
+			continue
+		}
+		minutes := sh.EndAt.Sub(sh.StartAt).Minutes()
+		if sh.ShiftTemplate != nil {
+			minutes -= float64(sh.ShiftTemplate.BreakMinutes)
+		}
+		sum.ScheduledHours += minutes / 60
+	}
+
+	ids := make([]uint, 0, len(byEmp))
+	for id := range byEmp {
+		ids = append(ids, id)
+	}
+	names, err := employeeNames(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]AttendanceSummary, 0, len(byEmp))
+	for id, sum := range byEmp {
+		sum.EmployeeName = names[id]
+		sum.WorkedHours = math.Round(sum.WorkedHours*100) / 100
+		sum.ScheduledHours = math.Round(sum.ScheduledHours*100) / 100
+		out = append(out, *sum)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EmployeeID < out[j].EmployeeID })
+	return out, nil
+}
+
+// Lateness รายการเข้างานสาย เรียงจากสายมากไปน้อย
+func (s *AttendanceService) Lateness(f AttendanceFilter) ([]LatenessEntry, error) {
+	from, to, err := f.dates()
+	if err != nil {
+		return nil, err
+	}
+	q := s.db.Preload("Employee").Preload("RosterShift.ShiftTemplate").Where("late_minutes > 0")
+	if f.EmployeeID != 0 {
+		q = q.Where("employee_id = ?", f.EmployeeID)
+	}
+	if q, err = applyDateRange(q, "clock_in", from, to); err != nil {
+		return nil, err
+	}
+	var attendances []entity.Attendance
+	if err := q.Order("late_minutes DESC, clock_in ASC").Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+	entries := make([]LatenessEntry, 0, len(attendances))
+	for _, a := range attendances {
+		e := LatenessEntry{
+			AttendanceID: a.ID,
+			EmployeeID:   a.EmployeeID,
+			EmployeeName: employeeName(a.Employee),
+			ClockIn:      a.ClockIn,
+			LateMinutes:  a.LateMinutes,
+		}
+		if a.RosterShift != nil {
+			e.ShiftStart = a.RosterShift.StartAt
+			if a.RosterShift.ShiftTemplate != nil {
+				e.ShiftName = a.RosterShift.ShiftTemplate.Name
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func employeeNames(db *gorm.DB, ids []uint) (map[uint]string, error) {
+	names := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var emps []entity.Employee
+	if err := db.Where("employee_id IN ?", ids).Find(&emps).Error; err != nil {
+		return nil, err
+	}
+	for i := range emps {
+		names[emps[i].EmployeeID] = employeeName(&emps[i])
+	}
+	return names, nil
+}
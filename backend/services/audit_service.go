@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+// AuditContextKey key ของ AuditActor ใน context (middleware.AuditActor ใส่ไว้ใน gin.Context)
+const AuditContextKey = "auditActor"
+
+// จำนวนแถวสูงสุดที่เก็บค่าเดิมต่อคำสั่ง update/delete หนึ่งครั้ง
+const auditSnapshotLimit = 500
+
+// ตารางที่ไม่บันทึก
+var auditSkipTables = map[string]bool{
+	"audit_logs": true,
+}
+
+// AuditActor ผู้เรียก API ที่ทำให้เกิดการเขียนข้อมูล
+type AuditActor struct {
+	ID     *uint
+	Role   string
+	IP     string
+	Method string
+	Path   string
+}
+
+// AuditChange ค่าก่อน/หลังของคอลัมน์ที่เปลี่ยน
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+func auditActorFrom(ctx context.Context) AuditActor {
+	if ctx != nil {
+		if actor, ok := ctx.Value(AuditContextKey).(AuditActor); ok {
+			return actor
+		}
+	}
+	return AuditActor{Role: entity.AuditRoleSystem}
+}
+
+// RegisterAuditCallbacks ผูก callback กับ create/update/delete ทุก entity
+// การเขียนด้วย SQL ดิบ (db.Exec) จะไม่ถูกบันทึก
+func RegisterAuditCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_create", auditAfterWrite(entity.AuditCreate)); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").After("gorm:begin_transaction").
+		Register("audit:before_update", auditSnapshot); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_update", auditAfterWrite(entity.AuditUpdate)); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").After("gorm:begin_transaction").
+		Register("audit:before_delete", auditSnapshot); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_delete", auditAfterWrite(entity.AuditDelete))
+}
+
+func auditable(db *gorm.DB) bool {
+	stmt := db.Statement
+	return db.Error == nil && !stmt.DryRun && stmt.Schema != nil &&
+		stmt.Schema.PrioritizedPrimaryField != nil && !auditSkipTables[stmt.Table]
+}
+
+// session ใหม่บน connection/transaction เดิม
+func auditSession(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true})
+}
+
+// auditQuery อ่านตารางเดียวกับคำสั่ง ใช้ model เปล่าเพื่อให้เงื่อนไขแบบ primary key และ soft delete ทำงาน
+func auditQuery(db *gorm.DB) *gorm.DB {
+	stmt := db.Statement
+	q := auditSession(db).Model(reflect.New(stmt.Schema.ModelType).Interface()).Table(stmt.Table)
+	if stmt.Unscoped {
+		q = q.Unscoped()
+	}
+	return q
+}
+
+// auditPrimaryKeys ค่า primary key ของ struct/slice ที่ส่งเข้ามา (ข้าม key ที่เป็นค่าว่าง)
+func auditPrimaryKeys(db *gorm.DB) []interface{} {
+	stmt := db.Statement
+	field := stmt.Schema.PrioritizedPrimaryField
+	var keys []interface{}
+	collect := func(v reflect.Value) {
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return
+		}
+		if key, zero := field.ValueOf(stmt.Context, v); !zero {
+			keys = append(keys, key)
+		}
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			collect(stmt.ReflectValue.Index(i))
+		}
+	case reflect.Struct:
+		collect(stmt.ReflectValue)
+	}
+	return keys
+}
+
+// auditSnapshot เก็บค่าเดิมของแถวที่จะถูก update/delete ตามเงื่อนไขเดียวกับคำสั่งจริง
+func auditSnapshot(db *gorm.DB) {
+	if !auditable(db) {
+		return
+	}
+	stmt := db.Statement
+	pk := stmt.Schema.PrioritizedPrimaryField.DBName
+
+	q := auditQuery(db)
+	where, hasWhere := stmt.Clauses["WHERE"].Expression.(clause.Where)
+	if hasWhere {
+		q = q.Clauses(where)
+	}
+	keys := auditPrimaryKeys(db)
+	if len(keys) > 0 {
+		q = q.Where(clause.IN{Column: clause.Column{Table: stmt.Table, Name: pk}, Values: keys})
+	} else if !hasWhere {
+		return // ไม่มีเงื่อนไข GORM จะปฏิเสธคำสั่งเอง
+	}
+
+	var rows []map[string]interface{}
+	if err := q.Limit(auditSnapshotLimit).Find(&rows).Error; err != nil {
+		db.AddError(fmt.Errorf("audit snapshot %s: %w", stmt.Table, err))
+		return
+	}
+	db.InstanceSet("audit:before", rows)
+}
+
+func auditAfterWrite(action string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if !auditable(db) {
+			return
+		}
+		stmt := db.Statement
+		pk := stmt.Schema.PrioritizedPrimaryField.DBName
+
+		before := map[string]map[string]interface{}{}
+		var keys []interface{}
+		if action == entity.AuditCreate {
+			keys = auditPrimaryKeys(db)
+		} else {
+			v, _ := db.InstanceGet("audit:before")
+			rows, _ := v.([]map[string]interface{})
+			for _, row := range rows {
+				before[fmt.Sprint(row[pk])] = row
+				keys = append(keys, row[pk])
+			}
+		}
+		if len(keys) == 0 {
+			return
+		}
+
+		after := map[string]map[string]interface{}{}
+		if action != entity.AuditDelete {
+			var rows []map[string]interface{}
+			err := auditQuery(db).Unscoped().Where(clause.IN{Column: clause.Column{Name: pk}, Values: keys}).
+				Find(&rows).Error
+			if err != nil {
+				db.AddError(fmt.Errorf("audit reload %s: %w", stmt.Table, err))
+				return
+			}
+			for _, row := range rows {
+				after[fmt.Sprint(row[pk])] = row
+			}
+		}
+
+		actor := auditActorFrom(stmt.Context)
+		logs := make([]entity.AuditLog, 0, len(keys))
+		for _, key := range keys {
+			id := fmt.Sprint(key)
+			changes := auditDiff(before[id], after[id], action == entity.AuditUpdate)
+			if len(changes) == 0 {
+				continue // update ที่ไม่ได้เปลี่ยนค่าจริง
+			}
+			data, err := json.Marshal(changes)
+			if err != nil {
+				db.AddError(err)
+				return
+			}
+			logs = append(logs, entity.AuditLog{
+				ActorID:   actor.ID,
+				ActorRole: actor.Role,
+				Action:    action,
+				Entity:    stmt.Table,
+				RecordID:  id,
+				Changes:   string(data),
+				IP:        actor.IP,
+				Method:    actor.Method,
+				Path:      actor.Path,
+			})
+		}
+		if len(logs) == 0 {
+			return
+		}
+		if err := auditSession(db).Create(&logs).Error; err != nil {
+			db.AddError(fmt.Errorf("write audit log: %w", err))
+		}
+	}
+}
+
+// auditDiff คอลัมน์ที่ค่าต่างกัน (update ไม่นับ updated_at) รหัสผ่านไม่เก็บค่าจริง
+func auditDiff(before, after map[string]interface{}, skipTimestamps bool) map[string]AuditChange {
+	changes := map[string]AuditChange{}
+	columns := map[string]bool{}
+	for col := range before {
+		columns[col] = true
+	}
+	for col := range after {
+		columns[col] = true
+	}
+	for col := range columns {
+		if skipTimestamps && col == "updated_at" {
+			continue
+		}
+		b, a := auditValue(before[col]), auditValue(after[col])
+		if b == nil && a == nil {
+			continue
+		}
+		if before != nil && after != nil && reflect.DeepEqual(b, a) {
+			continue
+		}
+		if strings.Contains(col, "password") {
+			b, a = auditRedact(b), auditRedact(a)
+		}
+		changes[col] = AuditChange{Before: b, After: a}
+	}
+	return changes
+}
+
+func auditValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func auditRedact(v interface{}) interface{} {
+	if v == nil || v == "" {
+		return v
+	}
+	return "[redacted]"
+}
+
+// AuditFilter ?entity=&record_id=&actor_id=&role=&action=&date_from=&date_to=&page=&limit=
+type AuditFilter struct {
+	Entity   string `form:"entity"`
+	RecordID string `form:"record_id"`
+	ActorID  uint   `form:"actor_id"`
+	Role     string `form:"role"`
+	Action   string `form:"action"`
+	DateFrom string `form:"date_from"`
+	DateTo   string `form:"date_to"`
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit"`
+}
+
+// AuditLogView changes เป็น JSON object แทน string
+type AuditLogView struct {
+	entity.AuditLog
+	Changes json.RawMessage `json:"changes"`
+}
+
+const (
+	DefaultAuditPageLimit = 50
+	MaxAuditPageLimit     = 200
+)
+
+// AuditService ค้นประวัติการแก้ไขข้อมูล
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// List ประวัติใหม่สุดก่อน คืนจำนวนทั้งหมดสำหรับแบ่งหน้า
+func (s *AuditService) List(f AuditFilter) ([]AuditLogView, int64, error) {
+	if f.RecordID != "" && f.Entity == "" {
+		return nil, 0, fmt.Errorf("%w: record_id requires entity", ErrInvalidAuditFilter)
+	}
+	if f.Role != "" && !containsString([]string{entity.AuditRoleCustomer, entity.AuditRoleEmployee,
+		entity.AuditRoleManager, entity.AuditRoleAnonymous, entity.AuditRoleSystem}, f.Role) {
+		return nil, 0, fmt.Errorf("%w: unknown role %q", ErrInvalidAuditFilter, f.Role)
+	}
+	if f.Action != "" && !containsString([]string{entity.AuditCreate, entity.AuditUpdate, entity.AuditDelete}, f.Action) {
+		return nil, 0, fmt.Errorf("%w: action must be create, update or delete", ErrInvalidAuditFilter)
+	}
+
+	q := s.db.Model(&entity.AuditLog{})
+	if f.Entity != "" {
+		q = q.Where("entity = ?", f.Entity)
+	}
+	if f.RecordID != "" {
+		q = q.Where("record_id = ?", f.RecordID)
+	}
+	if f.ActorID != 0 {
+		q = q.Where("actor_id = ?", f.ActorID)
+	}
+	if f.Role != "" {
+		q = q.Where("actor_role = ?", f.Role)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	q, err := applyDateRange(q, "created_at", f.DateFrom, f.DateTo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if f.Limit <= 0 || f.Limit > MaxAuditPageLimit {
+		f.Limit = DefaultAuditPageLimit
+	}
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	var logs []entity.AuditLog
+	if err := q.Order("id DESC").Limit(f.Limit).Offset((f.Page - 1) * f.Limit).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	views := make([]AuditLogView, len(logs))
+	for i, l := range logs {
+		views[i] = AuditLogView{AuditLog: l, Changes: json.RawMessage(l.Changes)}
+	}
+	return views, total, nil
+}
+
+// Entities ชื่อตารางที่มีประวัติ (ใช้ทำตัวเลือกในหน้าค้นหา)
+func (s *AuditService) Entities() ([]string, error) {
+	var names []string
+	err := s.db.Model(&entity.AuditLog{}).Distinct("entity").Order("entity").Pluck("entity", &names).Error
+	return names, err
+}
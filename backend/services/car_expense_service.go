@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidExpenseCategory = errors.New("category must be refurbishment, inspection, transport, registration, maintenance or other")
+	ErrInvalidExpenseAmount   = errors.New("amount must be greater than 0")
+	ErrCarExpenseNotFound     = errors.New("car expense not found")
+)
+
+var expenseCategories = map[string]bool{
+	entity.ExpenseRefurbishment: true,
+	entity.ExpenseInspection:    true,
+	entity.ExpenseTransport:     true,
+	entity.ExpenseRegistration:  true,
+	entity.ExpenseMaintenance:   true,
+	entity.ExpenseOther:         true,
+}
+
+// ราคาขายของสัญญา: ราคาที่บันทึกตอนทำสัญญา ถ้าเป็นสัญญาเก่าที่ยังไม่มี
+// ใช้ราคาล่าสุดใน sale_price_changes ณ วันทำสัญญา แล้วจึงใช้ราคาปัจจุบันของประกาศ (ต้อง JOIN sale_lists)
+const salesContractPriceExpr = "COALESCE(NULLIF(sales_contracts.sale_price, 0), " +
+	"(SELECT sale_price_changes.new_price FROM sale_price_changes" +
+	" WHERE sale_price_changes.sale_list_id = sales_contracts.sale_list_id AND sale_price_changes.deleted_at IS NULL" +
+	" AND sale_price_changes.created_at <= sales_contracts.created_at" +
+	" ORDER BY sale_price_changes.created_at DESC, sale_price_changes.id DESC LIMIT 1), " +
+	"sale_lists.sale_price)"
+
+// CarExpenseInput ข้อมูลค่าใช้จ่ายที่ผู้จัดการบันทึก
+type CarExpenseInput struct {
+	Category    string  `json:"category" binding:"required"`
+	Amount      float64 `json:"amount" binding:"required"`
+	ExpenseDate string  `json:"expense_date"` // YYYY-MM-DD (ไม่ระบุ = วันนี้)
+	Vendor      string  `json:"vendor"`
+	Description string  `json:"description"`
+}
+
+// CarProfit ต้นทุนรวมและผลตอบแทนของรถหนึ่งคัน
+type CarProfit struct {
+	CarID              uint               `json:"car_id"`
+	PurchasePrice      float64            `json:"purchase_price"`
+	Expenses           float64            `json:"expenses"`
+	ExpensesByCategory map[string]float64 `json:"expenses_by_category"`
+	LandedCost         float64            `json:"landed_cost"` // ราคาซื้อ + ค่าใช้จ่ายทั้งหมด
+
+	// ข้อมูลการขาย (nil ถ้ายังไม่มีสัญญาซื้อขาย)
+	SalesContractID *uint      `json:"sales_contract_id"`
+	SoldAt          *time.Time `json:"sold_at"`
+	SalePrice       *float64   `json:"sale_price"`
+	RealizedProfit  *float64   `json:"realized_profit"` // ราคาขายตามสัญญา - ต้นทุนรวม
+
+	RentContracts int64   `json:"rent_contracts"`
+	RentalIncome  float64 `json:"rental_income"` // ยอดที่ชำระแล้วของสัญญาเช่าทั้งหมด
+
+	// ราคาขาย (ถ้าขายแล้ว) + รายได้ค่าเช่า - ต้นทุนรวม
+	NetResult float64 `json:"net_result"`
+}
+
+// CarExpenseService บัญชีค่าใช้จ่ายรายคันและการคำนวณกำไร
+type CarExpenseService struct {
+	db *gorm.DB
+}
+
+func NewCarExpenseService(db *gorm.DB) *CarExpenseService {
+	return &CarExpenseService{db: db}
+}
+
+func (s *CarExpenseService) WithContext(ctx context.Context) *CarExpenseService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func (s *CarExpenseService) findCar(carID uint) (*entity.Car, error) {
+	var car entity.Car
+	if err := s.db.First(&car, carID).Error; err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// List ค่าใช้จ่ายของรถ เรียงตามวันที่
+func (s *CarExpenseService) List(carID uint) ([]entity.CarExpense, error) {
+	if _, err := s.findCar(carID); err != nil {
+		return nil, err
+	}
+	var expenses []entity.CarExpense
+	err := s.db.Where("car_id = ?", carID).Order("expense_date ASC, id ASC").Find(&expenses).Error
+	return expenses, err
+}
+
+func applyExpenseInput(expense *entity.CarExpense, in CarExpenseInput) error {
+	category := strings.ToLower(strings.TrimSpace(in.Category))
+	if !expenseCategories[category] {
+		return ErrInvalidExpenseCategory
+	}
+	if in.Amount <= 0 {
+		return ErrInvalidExpenseAmount
+	}
+	y, m, d := time.Now().Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if in.ExpenseDate != "" {
+		d, err := time.Parse("2006-01-02", in.ExpenseDate)
+		if err != nil {
+			return err
+		}
+		date = d
+	}
+	expense.Category = category
+	expense.Amount = in.Amount
+	expense.ExpenseDate = date
+	expense.Vendor = strings.TrimSpace(in.Vendor)
+	expense.Description = strings.TrimSpace(in.Description)
+	return nil
+}
+
+// Create บันทึกค่าใช้จ่ายใหม่ของรถ
+func (s *CarExpenseService) Create(carID, managerID uint, in CarExpenseInput) (*entity.CarExpense, error) {
+	if _, err := s.findCar(carID); err != nil {
+		return nil, err
+	}
+	expense := entity.CarExpense{CarID: carID, ManagerID: managerID}
+	if err := applyExpenseInput(&expense, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&expense).Error; err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+func (s *CarExpenseService) find(carID, expenseID uint) (*entity.CarExpense, error) {
+	var expense entity.CarExpense
+	if err := s.db.Where("id = ? AND car_id = ?", expenseID, carID).First(&expense).Error; err != nil {
+		return nil, notFoundAs(err, ErrCarExpenseNotFound)
+	}
+	return &expense, nil
+}
+
+// Update แก้ไขค่าใช้จ่าย (ใบเสร็จที่แนบไว้ยังอยู่)
+func (s *CarExpenseService) Update(carID, expenseID uint, in CarExpenseInput) (*entity.CarExpense, error) {
+	expense, err := s.find(carID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyExpenseInput(expense, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(expense).Error; err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// Delete ลบค่าใช้จ่าย (soft delete ไม่นับในต้นทุนอีก)
+func (s *CarExpenseService) Delete(carID, expenseID uint) error {
+	expense, err := s.find(carID, expenseID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(expense).Error
+}
+
+// Profit คำนวณต้นทุนรวม กำไรจากการขาย และรายได้ค่าเช่าของรถด้วย SQL aggregate
+func (s *CarExpenseService) Profit(carID uint) (*CarProfit, error) {
+	car, err := s.findCar(carID)
+	if err != nil {
+		return nil, err
+	}
+	p := &CarProfit{
+		CarID:              car.ID,
+		PurchasePrice:      car.PurchasePrice,
+		ExpensesByCategory: map[string]float64{},
+	}
+
+	var byCategory []struct {
+		Category string
+		Total    float64
+	}
+	if err := s.db.Model(&entity.CarExpense{}).
+		Select("category, SUM(amount) AS total").
+		Where("car_id = ?", carID).
+		Group("category").
+		Scan(&byCategory).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range byCategory {
+		p.ExpensesByCategory[c.Category] = c.Total
+		p.Expenses += c.Total
+	}
+	p.LandedCost = p.PurchasePrice + p.Expenses
+	p.NetResult = -p.LandedCost
+
+	// สัญญาซื้อขายล่าสุดของรถ ใช้ราคาตอนทำสัญญา ไม่ใช่ราคาปัจจุบันของประกาศ
+	var sale []struct {
+		ID        uint
+		CreatedAt time.Time
+		SalePrice float64
+	}
+	if err := s.db.Model(&entity.SalesContract{}).
+		Select("sales_contracts.id, sales_contracts.created_at, "+salesContractPriceExpr+" AS sale_price").
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id").
+		Where("sale_lists.car_id = ?", carID).
+		Order("sales_contracts.created_at DESC").
+		Limit(1).
+		Scan(&sale).Error; err != nil {
+		return nil, err
+	}
+	if len(sale) > 0 {
+		profit := sale[0].SalePrice - p.LandedCost
+		p.SalesContractID = &sale[0].ID
+		p.SoldAt = &sale[0].CreatedAt
+		p.SalePrice = &sale[0].SalePrice
+		p.RealizedProfit = &profit
+		p.NetResult += sale[0].SalePrice
+	}
+
+	rentContracts := s.db.Model(&entity.RentContract{}).
+		Select("rent_contracts.id").
+		Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ?", carID)
+	if err := rentContracts.Session(&gorm.Session{}).Count(&p.RentContracts).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&entity.Payment{}).
+		Select("COALESCE(SUM("+paymentAmountExpr+"), 0)").
+		Where("payments.rent_contract_id IN (?) AND payments.status = ?", rentContracts, PaymentStatusPaid).
+		Scan(&p.RentalIncome).Error; err != nil {
+		return nil, err
+	}
+	p.NetResult += p.RentalIncome
+	return p, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidVIN            = errors.New("invalid VIN")
+	ErrDuplicateVIN          = errors.New("another car already has this VIN")
+	ErrDuplicatePlate        = errors.New("another car already has this license plate in the same registration province")
+	ErrDuplicateEngineNumber = errors.New("another car already has this engine number")
+)
+
+// ค่าของตัวอักษรแต่ละตัวในการคำนวณเลขตรวจสอบ VIN (ISO 3779) — ไม่มี I, O, Q
+var vinValues = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+var vinWeights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// NormalizeVIN ตัวพิมพ์ใหญ่ ไม่มีช่องว่างหรือขีด
+func NormalizeVIN(vin string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(vin)))
+}
+
+// NormalizePlate ทะเบียนรถแบบไม่มีช่องว่างหรือขีด ใช้ทั้งตอนบันทึกและค้นหา ("1กข-1234" → "1กข1234")
+func NormalizePlate(plate string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(plate)))
+}
+
+// NormalizeEngineNumber ตัวพิมพ์ใหญ่ ไม่มีช่องว่าง
+func NormalizeEngineNumber(engine string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(engine), " ", ""))
+}
+
+// ValidateVIN ตรวจความยาว 17 ตัว ตัวอักษรที่อนุญาต และเลขตรวจสอบหลักที่ 9
+func ValidateVIN(vin string) error {
+	if len(vin) != 17 {
+		return fmt.Errorf("%w: must be 17 characters", ErrInvalidVIN)
+	}
+	sum := 0
+	for i, r := range vin {
+		v, ok := vinValues[r]
+		switch {
+		case ok:
+		case r >= '0' && r <= '9':
+			v = int(r - '0')
+		default:
+			return fmt.Errorf("%w: character %q is not allowed", ErrInvalidVIN, r)
+		}
+		sum += v * vinWeights[i]
+	}
+	check := byte('0' + sum%11)
+	if sum%11 == 10 {
+		check = 'X'
+	}
+	if vin[8] != check {
+		return fmt.Errorf("%w: check digit should be %c", ErrInvalidVIN, check)
+	}
+	return nil
+}
+
+// EnsureUniqueCarIdentity ตรวจว่า VIN เลขเครื่อง และทะเบียน+จังหวัด ไม่ซ้ำกับรถคันอื่น (ไม่นับรถที่ลบแล้ว)
+func EnsureUniqueCarIdentity(tx *gorm.DB, car *entity.Car) error {
+	checks := []struct {
+		skip  bool
+		where string
+		args  []interface{}
+		err   error
+	}{
+		{car.VIN == "", "vin = ?", []interface{}{car.VIN}, ErrDuplicateVIN},
+		{car.EngineNumber == "", "engine_number = ?", []interface{}{car.EngineNumber}, ErrDuplicateEngineNumber},
+		{car.LicensePlate == "", "license_plate = ? AND registration_province_id = ?",
+			[]interface{}{car.LicensePlate, car.RegistrationProvinceID}, ErrDuplicatePlate},
+	}
+	for _, c := range checks {
+		if c.skip {
+			continue
+		}
+		var n int64
+		if err := tx.Model(&entity.Car{}).Where(c.where, c.args...).
+			Where("id <> ?", car.ID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// CarIdentityOf ดึงข้อมูลตามเล่มทะเบียนของรถ (ชื่อจังหวัดต้อง preload RegistrationProvince)
+func CarIdentityOf(car entity.Car) entity.CarIdentity {
+	identity := entity.CarIdentity{
+		VIN:                    car.VIN,
+		EngineNumber:           car.EngineNumber,
+		LicensePlate:           car.LicensePlate,
+		RegistrationProvinceID: car.RegistrationProvinceID,
+		RegistrationExpiry:     car.RegistrationExpiry,
+		TaxExpiry:              car.TaxExpiry,
+	}
+	if car.RegistrationProvince != nil {
+		identity.RegistrationProvince = car.RegistrationProvince.ProvinceName
+	}
+	return identity
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateVIN(t *testing.T) {
+	cases := []struct {
+		vin    string
+		valid  bool
+		reason string
+	}{
+		{"1M8GDM9AXKP042788", true, ""}, // เลขตรวจสอบเป็น X
+		{"1HGCM82633A004352", true, ""},
+		{"JH4KA7561PC008269", true, ""},
+		{"11111111111111111", true, ""},
+		{"1HGCM82633A00435", false, "17 characters"},
+		{"1HGCM82633A0043521", false, "17 characters"},
+		{"", false, "17 characters"},
+		{"1HGCM82643A004352", false, "check digit should be 3"},
+		{"1M8GDM9A1KP042788", false, "check digit should be X"},
+		{"1HGCM8263IA004352", false, `'I'`},
+		{"1HGCM8263OA004352", false, `'O'`},
+		{"1HGCM8263QA004352", false, `'Q'`},
+		{"1hgcm82633a004352", false, `'h'`}, // ต้อง NormalizeVIN ก่อน
+		{"1HGCM82633A00435-", false, `'-'`},
+		{"1HGCM82633Aกข4352", false, "17 characters"},
+	}
+	for _, tc := range cases {
+		err := ValidateVIN(tc.vin)
+		if tc.valid {
+			if err != nil {
+				t.Errorf("ValidateVIN(%q) = %v, want nil", tc.vin, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidVIN) || !strings.Contains(err.Error(), tc.reason) {
+			t.Errorf("ValidateVIN(%q) = %v, want ErrInvalidVIN mentioning %s", tc.vin, err, tc.reason)
+		}
+	}
+}
+
+func TestNormalizeCarIdentity(t *testing.T) {
+	cases := []struct {
+		fn       func(string) string
+		name     string
+		in, want string
+	}{
+		{NormalizePlate, "plate", "1กข-1234", "1กข1234"},
+		{NormalizePlate, "plate", " 1 กข 1234 ", "1กข1234"},
+		{NormalizePlate, "plate", "กก 99", "กก99"},
+		{NormalizePlate, "plate", "ab-12", "AB12"},
+		{NormalizePlate, "plate", "", ""},
+		{NormalizeVIN, "vin", " 1hgcm82633a004352 ", "1HGCM82633A004352"},
+		{NormalizeVIN, "vin", "1HG-CM8 2633-A004352", "1HGCM82633A004352"},
+		{NormalizeEngineNumber, "engine", " 2gd 123456 ", "2GD123456"},
+		{NormalizeEngineNumber, "engine", "2GD-123456", "2GD-123456"}, // เลขเครื่องคงขีดไว้
+	}
+	for _, tc := range cases {
+		if got := tc.fn(tc.in); got != tc.want {
+			t.Errorf("normalize %s(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
+		}
+	}
+
+	// ทะเบียนที่พิมพ์ต่างกันต้องได้ค่าเดียวกัน ค้นหาและตรวจซ้ำจึงตรงกัน
+	if NormalizePlate("1กข 1234") != NormalizePlate("1กข-1234") {
+		t.Error("plate variants should normalize to the same value")
+	}
+	if vin := NormalizeVIN("1hgcm8-2633a004352"); ValidateVIN(vin) != nil {
+		t.Errorf("normalized VIN %q should validate", vin)
+	}
+}
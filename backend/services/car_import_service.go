@@ -0,0 +1,537 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+	"gorm.io/gorm"
+)
+
+const MaxImportRows = 5000
+
+// สถานะผลของแต่ละแถว
+const (
+	ImportRowCreated = "created"
+	ImportRowSkipped = "skipped"
+	ImportRowError   = "error"
+)
+
+var (
+	ErrInvalidImportFile  = errors.New("invalid import file")
+	ErrImportEmpty        = errors.New("file has no header row")
+	ErrImportTooManyRows  = fmt.Errorf("file has more than %d rows", MaxImportRows)
+	errImportRollback     = errors.New("rollback import")
+	importRequiredColumns = []string{"brand", "model", "sub_model", "car_name", "year"}
+	importColumnAliases   = map[string]string{
+		"brand":                 "brand",
+		"brand_name":            "brand",
+		"model":                 "model",
+		"model_name":            "model",
+		"sub_model":             "sub_model",
+		"submodel":              "sub_model",
+		"sub_model_name":        "sub_model",
+		"submodel_name":         "sub_model",
+		"car_name":              "car_name",
+		"name":                  "car_name",
+		"year":                  "year",
+		"year_manufacture":      "year",
+		"purchase_price":        "purchase_price",
+		"price":                 "purchase_price",
+		"purchase_date":         "purchase_date",
+		"color":                 "color",
+		"colour":                "color",
+		"mileage":               "mileage",
+		"condition":             "condition",
+		"province":              "province",
+		"province_id":           "province",
+		"province_name":         "province",
+		"manager_id":            "manager_id",
+		"employee_id":           "manager_id", // ไฟล์ตัวอย่างรุ่นแรกใช้ชื่อนี้เก็บ ID ผู้จัดการ
+		"vin":                   "vin",
+		"plate":                 "plate",
+		"license_plate":         "plate",
+		"engine_number":         "engine_number",
+		"engine_no":             "engine_number",
+		"registration_province": "registration_province",
+		"registration_expiry":   "registration_expiry",
+		"tax_expiry":            "tax_expiry",
+		"pictures":              "pictures",
+		"car_main":              "car_main",
+		"car_mian":              "car_main",
+		"car_front":             "car_front",
+		"car_side":              "car_side",
+		"car_in":                "car_in",
+		"car_interior":          "car_in",
+		"car_bottom":            "car_bottom",
+	}
+	// คอลัมน์รูปแต่ละมุม เรียงตามลำดับที่จะแสดง
+	importPictureColumns = []struct{ column, title string }{
+		{"car_main", "Main"},
+		{"car_front", "Front view"},
+		{"car_side", "Side view"},
+		{"car_in", "Interior"},
+		{"car_bottom", "Bottom"},
+	}
+)
+
+// CarImportOptions ตัวเลือกการนำเข้า
+type CarImportOptions struct {
+	FileName  string
+	DryRun    bool // ตรวจและจำลองทั้งไฟล์แต่ไม่บันทึก
+	ManagerID uint // ใช้เมื่อแถวไม่ได้ระบุ manager_id
+	// ใช้แทนเมื่อ manager_id ในแถวไม่มีอยู่จริง (เช่นไฟล์ตัวอย่างเก่า) 0 = ถือเป็นข้อผิดพลาด
+	FallbackManagerID uint
+}
+
+// CarImportRow ผลของแต่ละแถว (Row คือเลขแถวในไฟล์ หัวตารางคือแถว 1)
+type CarImportRow struct {
+	Row     int    `json:"row"`
+	Status  string `json:"status"`
+	CarID   uint   `json:"car_id,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// CarImportResult สรุปผลการนำเข้า
+// ถ้ามีแถวผิดแม้แถวเดียว จะไม่บันทึกอะไรเลย (Committed = false)
+type CarImportResult struct {
+	ImportID  uint           `json:"import_id"`
+	DryRun    bool           `json:"dry_run"`
+	Committed bool           `json:"committed"`
+	TotalRows int            `json:"total_rows"`
+	Created   int            `json:"created"`
+	Skipped   int            `json:"skipped"`
+	Failed    int            `json:"failed"`
+	Rows      []CarImportRow `json:"rows"`
+}
+
+// CarImportService นำเข้ารถจาก CSV โดยจับคอลัมน์จากชื่อหัวตาราง
+type CarImportService struct {
+	db     *gorm.DB
+	search *CarSearchService
+}
+
+func NewCarImportService(db *gorm.DB) *CarImportService {
+	return &CarImportService{db: db, search: NewCarSearchService(db)}
+}
+
+func (s *CarImportService) WithContext(ctx context.Context) *CarImportService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+type importRecord struct {
+	line   int
+	values map[string]string
+}
+
+func (rec importRecord) get(column string) string {
+	return strings.TrimSpace(rec.values[column])
+}
+
+// Import ตรวจทุกแถวแล้วบันทึกทั้งไฟล์ใน transaction เดียว แถวที่ซ้ำ (VIN/ทะเบียน/ข้อมูลเดียวกัน) จะถูกข้าม
+func (s *CarImportService) Import(r io.Reader, opts CarImportOptions) (*CarImportResult, error) {
+	records, err := readImportCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+	}
+
+	result := &CarImportResult{DryRun: opts.DryRun, TotalRows: len(records), Rows: make([]CarImportRow, 0, len(records))}
+	var createdIDs []uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		seen := map[string]int{}
+		for _, rec := range records {
+			row := s.importRow(tx, rec, opts, seen)
+			switch row.Status {
+			case ImportRowCreated:
+				result.Created++
+				createdIDs = append(createdIDs, row.CarID)
+			case ImportRowSkipped:
+				result.Skipped++
+			case ImportRowError:
+				result.Failed++
+			}
+			result.Rows = append(result.Rows, row)
+		}
+		if result.Failed > 0 || opts.DryRun {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+	result.Committed = err == nil
+	if !result.Committed {
+		// ID ที่ได้ระหว่าง transaction ถูก rollback ไปแล้ว
+		for i := range result.Rows {
+			result.Rows[i].CarID = 0
+		}
+	}
+
+	record := entity.CarImport{
+		FileName:  opts.FileName,
+		DryRun:    opts.DryRun,
+		Committed: result.Committed,
+		TotalRows: result.TotalRows,
+		Created:   result.Created,
+		Skipped:   result.Skipped,
+		Failed:    result.Failed,
+		ResultCSV: importResultCSV(result.Rows),
+		ManagerID: opts.ManagerID,
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return nil, err
+	}
+	result.ImportID = record.ID
+
+	if result.Committed && len(createdIDs) > 0 {
+		if err := s.search.EnsureIndex(); err != nil {
+			log.Println("failed to create car search index:", err)
+		}
+		for _, id := range createdIDs {
+			if err := s.search.IndexCar(id); err != nil {
+				log.Println("failed to update car search index:", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Get ประวัติการนำเข้าพร้อมผลรายแถว (ResultCSV) สำหรับดาวน์โหลด
+func (s *CarImportService) Get(importID uint) (*entity.CarImport, error) {
+	var record entity.CarImport
+	if err := s.db.First(&record, importID).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// List ประวัติการนำเข้าล่าสุดก่อน
+func (s *CarImportService) List(limit int) ([]entity.CarImport, error) {
+	var records []entity.CarImport
+	err := s.db.Order("id DESC").Limit(limit).Find(&records).Error
+	return records, err
+}
+
+func readImportCSV(r io.Reader) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrImportEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	found := map[string]bool{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		h = strings.ReplaceAll(h, " ", "_")
+		if c, ok := importColumnAliases[h]; ok {
+			columns[i] = c
+			found[c] = true
+		}
+	}
+	var missing []string
+	for _, c := range importRequiredColumns {
+		if !found[c] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required columns: %s", strings.Join(missing, ", "))
+	}
+
+	var records []importRecord
+	line := 1
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+		if len(records) >= MaxImportRows {
+			return nil, ErrImportTooManyRows
+		}
+		rec := importRecord{line: line, values: map[string]string{}}
+		blank := true
+		for i, v := range fields {
+			if i < len(columns) && columns[i] != "" {
+				rec.values[columns[i]] = v
+			}
+			if strings.TrimSpace(v) != "" {
+				blank = false
+			}
+		}
+		if !blank {
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// importRow ตรวจและบันทึกหนึ่งแถว seen เก็บ key ของแถวก่อนหน้าในไฟล์เดียวกัน
+func (s *CarImportService) importRow(tx *gorm.DB, rec importRecord, opts CarImportOptions, seen map[string]int) CarImportRow {
+	row := CarImportRow{Row: rec.line}
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	car := entity.Car{
+		CarName:      rec.get("car_name"),
+		Color:        rec.get("color"),
+		Condition:    rec.get("condition"),
+		VIN:          NormalizeVIN(rec.get("vin")),
+		EngineNumber: NormalizeEngineNumber(rec.get("engine_number")),
+		LicensePlate: NormalizePlate(rec.get("plate")),
+	}
+	for _, c := range importRequiredColumns {
+		if rec.get(c) == "" {
+			fail("%s is required", c)
+		}
+	}
+
+	if v := rec.get("year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil || year < 1900 || year > time.Now().Year()+1 {
+			fail("year %q is not a valid year", v)
+		}
+		car.YearManufacture = year
+	}
+	if v := rec.get("purchase_price"); v != "" {
+		price, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+		if err != nil || price < 0 {
+			fail("purchase_price %q is not a valid amount", v)
+		}
+		car.PurchasePrice = price
+	}
+	if v := rec.get("purchase_date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			fail("purchase_date %q must be YYYY-MM-DD", v)
+		}
+		car.PurchaseDate = d
+	}
+	if v := rec.get("mileage"); v != "" {
+		mileage, err := strconv.Atoi(strings.ReplaceAll(v, ",", ""))
+		if err != nil || mileage < 0 {
+			fail("mileage %q is not a valid number", v)
+		}
+		car.Mileage = mileage
+	}
+
+	if car.VIN != "" {
+		if err := ValidateVIN(car.VIN); err != nil {
+			fail("vin %q: %v", car.VIN, err)
+		}
+	}
+	for _, c := range []struct {
+		column string
+		target **time.Time
+	}{{"registration_expiry", &car.RegistrationExpiry}, {"tax_expiry", &car.TaxExpiry}} {
+		d, err := parseOptionalDate(rec.get(c.column))
+		if err != nil {
+			fail("%s %q must be YYYY-MM-DD", c.column, rec.get(c.column))
+		}
+		*c.target = d
+	}
+
+	for _, c := range []struct {
+		column string
+		target *uint
+	}{{"province", &car.ProvinceID}, {"registration_province", &car.RegistrationProvinceID}} {
+		v := rec.get(c.column)
+		if v == "" {
+			continue
+		}
+		var province entity.Province
+		q := tx.Where("LOWER(province_name) = LOWER(?)", v)
+		if id, err := strconv.Atoi(v); err == nil {
+			q = tx.Where("id = ?", id)
+		}
+		if err := q.Limit(1).Find(&province).Error; err != nil || province.ID == 0 {
+			fail("%s %q does not exist", c.column, v)
+		}
+		*c.target = province.ID
+	}
+
+	car.ManagerID = opts.ManagerID
+	if v := rec.get("manager_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			fail("manager_id %q is not a valid id", v)
+		} else {
+			car.ManagerID = uint(id)
+		}
+	}
+	var notes []string
+	if car.ManagerID == 0 {
+		fail("manager_id is required")
+	} else {
+		var n int64
+		if err := tx.Model(&entity.Manager{}).Where("id = ?", car.ManagerID).Count(&n).Error; err != nil || n == 0 {
+			if opts.FallbackManagerID != 0 && opts.FallbackManagerID != car.ManagerID {
+				notes = append(notes, fmt.Sprintf("manager %d does not exist, assigned to manager %d", car.ManagerID, opts.FallbackManagerID))
+				car.ManagerID = opts.FallbackManagerID
+			} else {
+				fail("manager %d does not exist", car.ManagerID)
+			}
+		}
+	}
+
+	var pictures []entity.CarPicture
+	for _, pc := range importPictureColumns {
+		if name := rec.get(pc.column); name != "" {
+			pictures = append(pictures, entity.CarPicture{Title: pc.title, Path: name})
+		}
+	}
+	for _, name := range strings.Split(rec.get("pictures"), ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			pictures = append(pictures, entity.CarPicture{Path: name})
+		}
+	}
+	for _, p := range pictures {
+		if path.Base(p.Path) != p.Path || !storage.ValidKey(p.Path) {
+			fail("picture %q must be a plain file name", p.Path)
+		}
+	}
+
+	if len(problems) > 0 {
+		row.Status = ImportRowError
+		row.Message = strings.Join(problems, "; ")
+		return row
+	}
+
+	// ซ้ำกับแถวก่อนหน้าในไฟล์
+	key := importDedupeKey(car)
+	if first, ok := seen[key]; ok {
+		row.Status = ImportRowSkipped
+		row.Message = fmt.Sprintf("duplicate of row %d", first)
+		return row
+	}
+	seen[key] = rec.line
+
+	// ซ้ำกับรถที่มีอยู่แล้ว
+	// รวมรถที่ถูกลบไปแล้ว เพื่อไม่ให้นำเข้าซ้ำกลับมาทุกครั้งที่ seed
+	existingID, deleted, err := findExistingCar(tx, car)
+	if err != nil {
+		row.Status = ImportRowError
+		row.Message = err.Error()
+		return row
+	}
+	if existingID != 0 {
+		row.Status = ImportRowSkipped
+		row.CarID = existingID
+		row.Message = fmt.Sprintf("already exists as car %d", existingID)
+		if deleted {
+			row.Message = fmt.Sprintf("exists (deleted) as car %d", existingID)
+		}
+		return row
+	}
+
+	// VIN เดียวกันถือว่าซ้ำไปแล้วข้างบน ที่เหลือคือเลขเครื่องหรือทะเบียนชนกับรถคันอื่น
+	if err := EnsureUniqueCarIdentity(tx, &car); err != nil {
+		row.Status = ImportRowError
+		row.Message = err.Error()
+		return row
+	}
+
+	detail, err := ResolveDetail(tx, rec.get("brand"), rec.get("model"), rec.get("sub_model"))
+	if err != nil {
+		row.Status = ImportRowError
+		row.Message = err.Error()
+		return row
+	}
+	car.DetailID = detail.ID
+	car.Lifecycle = entity.CarInStock
+	if err := tx.Create(&car).Error; err != nil {
+		row.Status = ImportRowError
+		row.Message = err.Error()
+		return row
+	}
+	for i := range pictures {
+		pictures[i].CarID = car.ID
+		pictures[i].SortOrder = i + 1
+	}
+	if len(pictures) > 0 {
+		if err := tx.Create(&pictures).Error; err != nil {
+			row.Status = ImportRowError
+			row.Message = err.Error()
+			return row
+		}
+	}
+	row.Status = ImportRowCreated
+	row.CarID = car.ID
+	row.Message = strings.Join(notes, "; ")
+	return row
+}
+
+// importDedupeKey ใช้ VIN ถ้ามี รองลงมาคือทะเบียน ถ้าไม่มีทั้งคู่ใช้ชื่อ ปี วันที่ซื้อ สี และเลขไมล์
+func importDedupeKey(car entity.Car) string {
+	switch {
+	case car.VIN != "":
+		return "vin:" + car.VIN
+	case car.LicensePlate != "":
+		return fmt.Sprintf("plate:%s|%d", car.LicensePlate, car.RegistrationProvinceID)
+	default:
+		return fmt.Sprintf("car:%s|%d|%s|%s|%d", strings.ToLower(car.CarName), car.YearManufacture,
+			car.PurchaseDate.Format("2006-01-02"), strings.ToLower(car.Color), car.Mileage)
+	}
+}
+
+// findExistingCar คืน ID ของรถที่ตรงกัน และบอกว่ารถคันนั้นถูกลบ (soft delete) ไปแล้วหรือไม่
+func findExistingCar(tx *gorm.DB, car entity.Car) (uint, bool, error) {
+	q := tx.Unscoped().Model(&entity.Car{})
+	switch {
+	case car.VIN != "":
+		q = q.Where("vin = ?", car.VIN)
+	case car.LicensePlate != "":
+		q = q.Where("license_plate = ? AND registration_province_id = ?", car.LicensePlate, car.RegistrationProvinceID)
+	default:
+		q = q.Where("LOWER(car_name) = LOWER(?) AND year_manufacture = ? AND date(purchase_date) = ? AND LOWER(color) = LOWER(?) AND mileage = ?",
+			car.CarName, car.YearManufacture, car.PurchaseDate.Format("2006-01-02"), car.Color, car.Mileage)
+	}
+	var found []entity.Car
+	if err := q.Select("id", "deleted_at").Order("id ASC").Limit(1).Find(&found).Error; err != nil {
+		return 0, false, err
+	}
+	if len(found) == 0 {
+		return 0, false, nil
+	}
+	return found[0].ID, found[0].DeletedAt.Valid, nil
+}
+
+func importResultCSV(rows []CarImportRow) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"row", "status", "car_id", "message"})
+	for _, r := range rows {
+		carID := ""
+		if r.CarID != 0 {
+			carID = strconv.FormatUint(uint64(r.CarID), 10)
+		}
+		w.Write([]string{strconv.Itoa(r.Row), r.Status, carID, r.Message})
+	}
+	w.Flush()
+	return buf.String()
+}
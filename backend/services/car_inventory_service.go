@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCarHasActiveContract = errors.New("car has active sales or rent contracts")
+	ErrCarDetailRequired    = errors.New("detail_id or brand_name, model_name and sub_model_name are required")
+	ErrPictureOrderMismatch = errors.New("picture_ids must list every picture of the car exactly once")
+	ErrInvalidCarReference  = errors.New("detail_id or province_id does not exist")
+	ErrCarPictureNotFound   = errors.New("car picture not found")
+)
+
+// CarInput ข้อมูลรถที่ผู้จัดการส่งมาเมื่อเพิ่ม/แก้ไขรถ
+// ระบุ DetailID หรือชื่อ Brand/Model/SubModel (ถ้ายังไม่มีในระบบจะสร้างให้)
+type CarInput struct {
+	CarName         string  `json:"car_name" binding:"required"`
+	YearManufacture int     `json:"year_manufacture" binding:"required"`
+	PurchasePrice   float64 `json:"purchase_price"`
+	PurchaseDate    string  `json:"purchase_date"` // YYYY-MM-DD
+	Color           string  `json:"color"`
+	Mileage         int     `json:"mileage"`
+	Condition       string  `json:"condition"`
+	ProvinceID      uint    `json:"province_id"`
+
+	// ข้อมูลตามเล่มทะเบียน
+	VIN                    string `json:"vin"`
+	EngineNumber           string `json:"engine_number"`
+	LicensePlate           string `json:"license_plate"`
+	RegistrationProvinceID uint   `json:"registration_province_id"`
+	RegistrationExpiry     string `json:"registration_expiry"` // YYYY-MM-DD
+	TaxExpiry              string `json:"tax_expiry"`          // YYYY-MM-DD
+
+	DetailID     uint   `json:"detail_id"`
+	BrandName    string `json:"brand_name"`
+	ModelName    string `json:"model_name"`
+	SubModelName string `json:"sub_model_name"`
+}
+
+// CarInventoryService จัดการสต็อกรถ (เพิ่ม/แก้ไข/ลบ และรูปภาพ)
+type CarInventoryService struct {
+	db     *gorm.DB
+	search *CarSearchService
+}
+
+func NewCarInventoryService(db *gorm.DB) *CarInventoryService {
+	return &CarInventoryService{db: db, search: NewCarSearchService(db)}
+}
+
+func (s *CarInventoryService) WithContext(ctx context.Context) *CarInventoryService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// ResolveDetail หา Detail จากชื่อ Brand/Model/SubModel (ไม่สนตัวพิมพ์) หรือสร้างใหม่ถ้าไม่มี
+func ResolveDetail(tx *gorm.DB, brandName, modelName, subModelName string) (*entity.Detail, error) {
+	brandName = strings.TrimSpace(brandName)
+	modelName = strings.TrimSpace(modelName)
+	subModelName = strings.TrimSpace(subModelName)
+	if brandName == "" || modelName == "" || subModelName == "" {
+		return nil, ErrCarDetailRequired
+	}
+
+	var brand entity.Brand
+	if err := tx.Where("LOWER(brand_name) = LOWER(?)", brandName).
+		Attrs(entity.Brand{BrandName: brandName}).
+		FirstOrCreate(&brand).Error; err != nil {
+		return nil, err
+	}
+
+	var carModel entity.CarModel
+	if err := tx.Where("LOWER(model_name) = LOWER(?) AND brand_id = ?", modelName, brand.ID).
+		Attrs(entity.CarModel{ModelName: modelName, BrandID: brand.ID}).
+		FirstOrCreate(&carModel).Error; err != nil {
+		return nil, err
+	}
+
+	var subModel entity.SubModel
+	if err := tx.Where("LOWER(sub_model_name) = LOWER(?) AND car_model_id = ?", subModelName, carModel.ID).
+		Attrs(entity.SubModel{SubModelName: subModelName, CarModelID: carModel.ID}).
+		FirstOrCreate(&subModel).Error; err != nil {
+		return nil, err
+	}
+
+	var detail entity.Detail
+	if err := tx.Where("brand_id = ? AND car_model_id = ? AND sub_model_id = ?", brand.ID, carModel.ID, subModel.ID).
+		Attrs(entity.Detail{BrandID: brand.ID, CarModelID: carModel.ID, SubModelID: subModel.ID}).
+		FirstOrCreate(&detail).Error; err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+func (s *CarInventoryService) applyInput(tx *gorm.DB, car *entity.Car, in CarInput) error {
+	if in.DetailID != 0 {
+		var detail entity.Detail
+		if err := tx.First(&detail, in.DetailID).Error; err != nil {
+			return notFoundAs(err, ErrInvalidCarReference)
+		}
+		car.DetailID = detail.ID
+	} else {
+		detail, err := ResolveDetail(tx, in.BrandName, in.ModelName, in.SubModelName)
+		if err != nil {
+			return err
+		}
+		car.DetailID = detail.ID
+	}
+
+	for _, id := range []uint{in.ProvinceID, in.RegistrationProvinceID} {
+		if id == 0 {
+			continue
+		}
+		var province entity.Province
+		if err := tx.First(&province, id).Error; err != nil {
+			return notFoundAs(err, ErrInvalidCarReference)
+		}
+	}
+
+	if in.PurchaseDate != "" {
+		d, err := time.Parse("2006-01-02", in.PurchaseDate)
+		if err != nil {
+			return err
+		}
+		car.PurchaseDate = d
+	}
+
+	if car.Lifecycle == "" {
+		car.Lifecycle = entity.CarInStock
+	}
+	car.CarName = strings.TrimSpace(in.CarName)
+	car.YearManufacture = in.YearManufacture
+	car.PurchasePrice = in.PurchasePrice
+	car.Color = in.Color
+	car.Mileage = in.Mileage
+	car.Condition = in.Condition
+	car.ProvinceID = in.ProvinceID
+
+	car.VIN = NormalizeVIN(in.VIN)
+	if car.VIN != "" {
+		if err := ValidateVIN(car.VIN); err != nil {
+			return err
+		}
+	}
+	car.EngineNumber = NormalizeEngineNumber(in.EngineNumber)
+	car.LicensePlate = NormalizePlate(in.LicensePlate)
+	car.RegistrationProvinceID = in.RegistrationProvinceID
+	var err error
+	if car.RegistrationExpiry, err = parseOptionalDate(in.RegistrationExpiry); err != nil {
+		return err
+	}
+	if car.TaxExpiry, err = parseOptionalDate(in.TaxExpiry); err != nil {
+		return err
+	}
+	return EnsureUniqueCarIdentity(tx, car)
+}
+
+// parseOptionalDate แปลง YYYY-MM-DD ค่าว่างคือไม่ระบุ
+func parseOptionalDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Create เพิ่มรถใหม่เข้าสต็อก
+func (s *CarInventoryService) Create(managerID uint, in CarInput) (*entity.Car, error) {
+	car := entity.Car{ManagerID: managerID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.applyInput(tx, &car, in); err != nil {
+			return err
+		}
+		return tx.Create(&car).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reindex(car.ID)
+	return &car, nil
+}
+
+// Update แก้ไขข้อมูลรถ
+func (s *CarInventoryService) Update(id uint, in CarInput) (*entity.Car, error) {
+	var car entity.Car
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&car, id).Error; err != nil {
+			return err
+		}
+		if err := s.applyInput(tx, &car, in); err != nil {
+			return err
+		}
+		return tx.Save(&car).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reindex(car.ID)
+	return &car, nil
+}
+
+// HasActiveContract รถมีสัญญาซื้อขาย หรือสัญญาเช่าที่ยังไม่สิ้นสุดหรือไม่
+func HasActiveContract(tx *gorm.DB, carID uint) (bool, error) {
+	var sales int64
+	if err := tx.Model(&entity.SalesContract{}).
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id").
+		Where("sale_lists.car_id = ?", carID).
+		Count(&sales).Error; err != nil {
+		return false, err
+	}
+	if sales > 0 {
+		return true, nil
+	}
+
+	var rents int64
+	if err := tx.Model(&entity.RentContract{}).
+		Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ? AND rent_contracts.date_end >= ?", carID, time.Now()).
+		Count(&rents).Error; err != nil {
+		return false, err
+	}
+	return rents > 0, nil
+}
+
+// Delete soft-delete รถที่ไม่มีสัญญาค้างอยู่
+func (s *CarInventoryService) Delete(id uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var car entity.Car
+		if err := tx.First(&car, id).Error; err != nil {
+			return err
+		}
+		active, err := HasActiveContract(tx, car.ID)
+		if err != nil {
+			return err
+		}
+		if active {
+			return ErrCarHasActiveContract
+		}
+		return tx.Delete(&car).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.reindex(id)
+	return nil
+}
+
+// AddPictures เพิ่มรูปต่อท้ายรูปเดิมของรถ
+func (s *CarInventoryService) AddPictures(carID uint, pictures []entity.CarPicture) ([]entity.CarPicture, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var car entity.Car
+		if err := tx.First(&car, carID).Error; err != nil {
+			return err
+		}
+		var maxOrder int
+		if err := tx.Model(&entity.CarPicture{}).Where("car_id = ?", carID).
+			Select("COALESCE(MAX(sort_order), 0)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		for i := range pictures {
+			maxOrder++
+			pictures[i].CarID = carID
+			pictures[i].SortOrder = maxOrder
+		}
+		if len(pictures) == 0 {
+			return nil
+		}
+		return tx.Create(&pictures).Error
+	})
+	return pictures, err
+}
+
+// ReorderPictures จัดลำดับรูปใหม่ตาม pictureIDs (ต้องครบทุกรูปของรถ)
+func (s *CarInventoryService) ReorderPictures(carID uint, pictureIDs []uint) ([]entity.CarPicture, error) {
+	var pictures []entity.CarPicture
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []entity.CarPicture
+		if err := tx.Where("car_id = ?", carID).Find(&existing).Error; err != nil {
+			return err
+		}
+		if len(existing) != len(pictureIDs) {
+			return ErrPictureOrderMismatch
+		}
+		owned := make(map[uint]bool, len(existing))
+		for _, p := range existing {
+			owned[p.ID] = true
+		}
+		for i, id := range pictureIDs {
+			if !owned[id] {
+				return ErrPictureOrderMismatch
+			}
+			delete(owned, id)
+			if err := tx.Model(&entity.CarPicture{}).Where("id = ?", id).
+				Update("sort_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return OrderCarPictures(tx).Where("car_id = ?", carID).Find(&pictures).Error
+	})
+	return pictures, err
+}
+
+// RemovePicture ลบรูปของรถ คืนค่ารูปที่ถูกลบ
+func (s *CarInventoryService) RemovePicture(carID, pictureID uint) (*entity.CarPicture, error) {
+	var picture entity.CarPicture
+	if err := s.db.Where("id = ? AND car_id = ?", pictureID, carID).First(&picture).Error; err != nil {
+		return nil, notFoundAs(err, ErrCarPictureNotFound)
+	}
+	if err := s.db.Delete(&picture).Error; err != nil {
+		return nil, err
+	}
+	return &picture, nil
+}
+
+// แทน ErrRecordNotFound ด้วย error ที่สื่อความหมายกว่า
+func notFoundAs(err, target error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return target
+	}
+	return err
+}
+
+func (s *CarInventoryService) reindex(carID uint) {
+	if err := s.search.IndexCar(carID); err != nil {
+		// index ค้นหาไม่ควรทำให้การบันทึกรถล้มเหลว
+		log.Println("failed to update car search index:", err)
+	}
+}
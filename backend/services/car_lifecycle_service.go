@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLifecycleCarNotFound  = errors.New("car not found")
+	ErrInvalidLifecycleState = errors.New("invalid car lifecycle state")
+	ErrLifecycleTransition   = errors.New("car lifecycle does not allow this change")
+	ErrCarHasUpcomingRental  = errors.New("car has a current or upcoming rent contract")
+	ErrCarNotForSale         = errors.New("car is not listed for sale")
+	ErrCarNotForRent         = errors.New("car is not listed for rent")
+)
+
+// สถานะปลายทาง → สถานะต้นทางที่เปลี่ยนมาได้
+// ขาย/เช่าอยู่ในสถานะคนละชุด รถจึงเปิดขายและให้เช่าพร้อมกันไม่ได้
+var lifecycleTransitions = map[string][]string{
+	entity.CarInStock:       {entity.CarListedForSale, entity.CarListedForRent, entity.CarReserved, entity.CarInMaintenance},
+	entity.CarListedForSale: {entity.CarInStock, entity.CarReserved, entity.CarInMaintenance, entity.CarSold},
+	entity.CarListedForRent: {entity.CarInStock, entity.CarReserved, entity.CarRentedOut, entity.CarInMaintenance},
+	entity.CarReserved:      {entity.CarInStock, entity.CarListedForSale, entity.CarListedForRent, entity.CarInMaintenance},
+	entity.CarRentedOut:     {entity.CarListedForRent},
+	entity.CarInMaintenance: {entity.CarInStock, entity.CarListedForSale, entity.CarListedForRent, entity.CarReserved},
+	entity.CarSold:          {entity.CarInStock, entity.CarListedForSale, entity.CarReserved},
+}
+
+// สถานะที่ผู้จัดการตั้งเองได้ ที่เหลือเกิดจากสัญญาหรืองานซ่อม
+var manualLifecycleStates = []string{entity.CarInStock, entity.CarListedForSale, entity.CarListedForRent, entity.CarReserved}
+
+var rentalLifecycleStates = []string{entity.CarListedForRent, entity.CarRentedOut}
+
+// CarLifecycleView สถานะปัจจุบันพร้อมประวัติ (ล่าสุดก่อน)
+type CarLifecycleView struct {
+	CarID  uint                       `json:"car_id"`
+	State  string                     `json:"state"`
+	Events []entity.CarLifecycleEvent `json:"events"`
+}
+
+// CarLifecycleService จุดเดียวที่เปลี่ยนสถานะหลักของรถ
+type CarLifecycleService struct {
+	db *gorm.DB
+}
+
+func NewCarLifecycleService(db *gorm.DB) *CarLifecycleService {
+	return &CarLifecycleService{db: db}
+}
+
+func (s *CarLifecycleService) WithContext(ctx context.Context) *CarLifecycleService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func lifecycleCar(tx *gorm.DB, carID uint) (*entity.Car, error) {
+	var car entity.Car
+	if err := tx.Select("id", "lifecycle").First(&car, carID).Error; err != nil {
+		return nil, notFoundAs(err, ErrLifecycleCarNotFound)
+	}
+	return &car, nil
+}
+
+// hasUpcomingRental สัญญาเช่าที่ยังไม่จบ (รวมที่เริ่มในอนาคต)
+func hasUpcomingRental(tx *gorm.DB, carID uint) (bool, error) {
+	var n int64
+	err := tx.Model(&entity.RentContract{}).
+		Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ? AND rent_contracts.date_end >= ?", carID, localToday()).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// TransitionCar เปลี่ยนสถานะรถภายใน transaction ของผู้เรียก (สถานะเดิมซ้ำ = ไม่ทำอะไร)
+func TransitionCar(tx *gorm.DB, carID uint, to, reason string, managerID *uint) error {
+	allowedFrom, ok := lifecycleTransitions[to]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidLifecycleState, to)
+	}
+	car, err := lifecycleCar(tx, carID)
+	if err != nil {
+		return err
+	}
+	from := car.Lifecycle
+	if from == to && to != entity.CarSold { // ขายซ้ำไม่ได้
+		return nil
+	}
+	if !containsString(allowedFrom, from) {
+		return fmt.Errorf("%w: %s → %s", ErrLifecycleTransition, from, to)
+	}
+	// ห้ามขายหรือเลิกให้เช่ารถที่ยังมีสัญญาเช่าค้าง (งานซ่อมตรวจช่วงทับสัญญาเช่าเองแล้ว)
+	leavesRental := containsString(rentalLifecycleStates, from) && !containsString(rentalLifecycleStates, to) && to != entity.CarInMaintenance
+	if to == entity.CarSold || leavesRental {
+		busy, err := hasUpcomingRental(tx, carID)
+		if err != nil {
+			return err
+		}
+		if busy {
+			return ErrCarHasUpcomingRental
+		}
+	}
+
+	if err := tx.Model(&entity.Car{}).Where("id = ?", carID).Update("lifecycle", to).Error; err != nil {
+		return err
+	}
+	if to == entity.CarSold {
+		if err := tx.Model(&entity.SaleList{}).
+			Where("car_id = ? AND status = ?", carID, entity.SaleListAvailable).
+			Update("status", entity.SaleListSold).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Create(&entity.CarLifecycleEvent{
+		CarID: carID, FromState: from, ToState: to, Reason: reason, ManagerID: managerID,
+	}).Error
+}
+
+// RequireCarState ตรวจว่ารถอยู่ในสถานะใดสถานะหนึ่ง ไม่ใช่ก็คืน failErr
+func RequireCarState(tx *gorm.DB, carID uint, failErr error, states ...string) error {
+	car, err := lifecycleCar(tx, carID)
+	if err != nil {
+		return err
+	}
+	if !containsString(states, car.Lifecycle) {
+		return fmt.Errorf("%w (car is %s)", failErr, car.Lifecycle)
+	}
+	return nil
+}
+
+// listFrom รถในสต็อกเปลี่ยนเป็น to ได้ สถานะใน keep ถือว่าเปิดอยู่แล้ว
+func listFrom(tx *gorm.DB, carID uint, to, reason string, keep ...string) error {
+	car, err := lifecycleCar(tx, carID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case containsString(keep, car.Lifecycle):
+		return nil
+	case car.Lifecycle == entity.CarInStock:
+		return TransitionCar(tx, carID, to, reason, nil)
+	default:
+		return fmt.Errorf("%w: %s → %s", ErrLifecycleTransition, car.Lifecycle, to)
+	}
+}
+
+// ListCarForSale เปิดขายรถในสต็อก (รถที่เปิดขายหรือจองไว้แล้วคงสถานะเดิม)
+func ListCarForSale(tx *gorm.DB, carID uint, reason string) error {
+	return listFrom(tx, carID, entity.CarListedForSale, reason, entity.CarListedForSale, entity.CarReserved)
+}
+
+// ListCarForRent เปิดให้เช่ารถในสต็อก (รถที่ถูกเช่าอยู่คงสถานะเดิม)
+func ListCarForRent(tx *gorm.DB, carID uint, reason string) error {
+	return listFrom(tx, carID, entity.CarListedForRent, reason, rentalLifecycleStates...)
+}
+
+// ReopenCarSale รถที่ขายแล้วกลับมาเปิดขายเมื่อสัญญาถูกยกเลิก (สถานะอื่นไม่เปลี่ยน)
+func ReopenCarSale(tx *gorm.DB, carID uint, reason string) error {
+	car, err := lifecycleCar(tx, carID)
+	if err != nil {
+		return err
+	}
+	if car.Lifecycle != entity.CarSold {
+		return nil
+	}
+	return TransitionCar(tx, carID, entity.CarListedForSale, reason, nil)
+}
+
+// SyncCarRental สลับ listed_for_rent ↔ rented_out ตามสัญญาเช่าที่ครอบวันนี้
+func SyncCarRental(tx *gorm.DB, carID uint) error {
+	car, err := lifecycleCar(tx, carID)
+	if err != nil {
+		return err
+	}
+	if !containsString(rentalLifecycleStates, car.Lifecycle) {
+		return nil
+	}
+	var contracts []entity.RentContract
+	today := localToday()
+	err = tx.Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ? AND rent_contracts.date_start < ? AND rent_contracts.date_end >= ?",
+			carID, today.AddDate(0, 0, 1), today).
+		Limit(1).Find(&contracts).Error
+	if err != nil {
+		return err
+	}
+	if len(contracts) == 0 {
+		return TransitionCar(tx, carID, entity.CarListedForRent, "rental returned", nil)
+	}
+	return TransitionCar(tx, carID, entity.CarRentedOut, fmt.Sprintf("rent contract %d started", contracts[0].ID), nil)
+}
+
+// ResumeCarAfterMaintenance คืนสถานะก่อนเข้าอู่
+func ResumeCarAfterMaintenance(tx *gorm.DB, carID uint, reason string) error {
+	car, err := lifecycleCar(tx, carID)
+	if err != nil {
+		return err
+	}
+	if car.Lifecycle != entity.CarInMaintenance {
+		return nil
+	}
+	var last entity.CarLifecycleEvent
+	err = tx.Where("car_id = ? AND to_state = ?", carID, entity.CarInMaintenance).
+		Order("id DESC").Limit(1).Find(&last).Error
+	if err != nil {
+		return err
+	}
+	back := last.FromState
+	if back == "" || !containsString(lifecycleTransitions[back], entity.CarInMaintenance) {
+		back = entity.CarInStock
+	}
+	if err := TransitionCar(tx, carID, back, reason, nil); err != nil {
+		return err
+	}
+	return SyncCarRental(tx, carID)
+}
+
+// derivedLifecycle สถานะจากข้อมูลเดิม ใช้กับรถที่ยังไม่เคยมีสถานะ
+func derivedLifecycle(tx *gorm.DB, carID uint) (string, error) {
+	checks := []struct {
+		state string
+		model interface{}
+		where string
+		args  []interface{}
+	}{
+		{entity.CarSold, &entity.SalesContract{}, "sale_list_id IN (SELECT id FROM sale_lists WHERE car_id = ?)", []interface{}{carID}},
+		{entity.CarInMaintenance, &entity.MaintenanceRecord{}, "car_id = ? AND status = ?", []interface{}{carID, entity.MaintenanceInProgress}},
+		{entity.CarListedForRent, &entity.RentList{}, "car_id = ? AND LOWER(status) <> ?", []interface{}{carID, strings.ToLower(entity.RentListDraft)}},
+		{entity.CarListedForSale, &entity.SaleList{}, "car_id = ? AND LOWER(status) = ?", []interface{}{carID, strings.ToLower(entity.SaleListAvailable)}},
+	}
+	for _, c := range checks {
+		var n int64
+		if err := tx.Model(c.model).Where(c.where, c.args...).Count(&n).Error; err != nil {
+			return "", err
+		}
+		if n > 0 {
+			return c.state, nil
+		}
+	}
+	return entity.CarInStock, nil
+}
+
+// SyncAll กำหนดสถานะให้รถที่ยังไม่มี และปรับสถานะรถเช่าตามวันที่ปัจจุบัน
+func (s *CarLifecycleService) SyncAll() error {
+	var missing []uint
+	if err := s.db.Model(&entity.Car{}).Where("lifecycle = '' OR lifecycle IS NULL").Pluck("id", &missing).Error; err != nil {
+		return err
+	}
+	for _, id := range missing {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			state, err := derivedLifecycle(tx, id)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&entity.Car{}).Where("id = ?", id).Update("lifecycle", state).Error; err != nil {
+				return err
+			}
+			return tx.Create(&entity.CarLifecycleEvent{CarID: id, ToState: state, Reason: "initial state from existing listings"}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var rental []uint
+	if err := s.db.Model(&entity.Car{}).Where("lifecycle IN ?", rentalLifecycleStates).Pluck("id", &rental).Error; err != nil {
+		return err
+	}
+	for _, id := range rental {
+		if err := s.db.Transaction(func(tx *gorm.DB) error { return SyncCarRental(tx, id) }); err != nil {
+			// รถคันหนึ่งพลาดไม่ควรหยุดคันอื่น
+			log.Println("failed to sync car lifecycle:", id, err)
+		}
+	}
+	return nil
+}
+
+// Get สถานะและประวัติของรถ
+func (s *CarLifecycleService) Get(carID uint) (*CarLifecycleView, error) {
+	car, err := lifecycleCar(s.db, carID)
+	if err != nil {
+		return nil, err
+	}
+	view := &CarLifecycleView{CarID: car.ID, State: car.Lifecycle, Events: []entity.CarLifecycleEvent{}}
+	if err := s.db.Where("car_id = ?", carID).Order("id DESC").Find(&view.Events).Error; err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// SetManual ผู้จัดการเปลี่ยนสถานะเอง (เช่น จองรถให้ลูกค้า, ถอนประกาศ)
+// สถานะขายแล้ว/ถูกเช่า/อยู่อู่ ต้องเปลี่ยนผ่านสัญญาหรืองานซ่อมเท่านั้น
+func (s *CarLifecycleService) SetManual(carID, managerID uint, to, reason string) (*CarLifecycleView, error) {
+	to = strings.ToLower(strings.TrimSpace(to))
+	if !containsString(manualLifecycleStates, to) {
+		return nil, fmt.Errorf("%w: state must be in_stock, listed_for_sale, listed_for_rent or reserved", ErrInvalidLifecycleState)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		car, err := lifecycleCar(tx, carID)
+		if err != nil {
+			return err
+		}
+		if car.Lifecycle == entity.CarSold || car.Lifecycle == entity.CarInMaintenance {
+			return fmt.Errorf("%w: %s → %s", ErrLifecycleTransition, car.Lifecycle, to)
+		}
+		if reason = strings.TrimSpace(reason); reason == "" {
+			reason = "changed by manager"
+		}
+		if err := TransitionCar(tx, carID, to, reason, &managerID); err != nil {
+			return err
+		}
+		if to == entity.CarListedForRent {
+			return SyncCarRental(tx, carID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(carID)
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var allLifecycleStates = []string{
+	entity.CarInStock, entity.CarListedForSale, entity.CarListedForRent, entity.CarReserved,
+	entity.CarRentedOut, entity.CarInMaintenance, entity.CarSold,
+}
+
+// สถานะต้นทาง → ปลายทางที่ต้องเปลี่ยนได้ (ที่เหลือต้องถูกปฏิเสธ)
+var wantLifecycleTransitions = map[string][]string{
+	entity.CarInStock:       {entity.CarListedForSale, entity.CarListedForRent, entity.CarReserved, entity.CarInMaintenance, entity.CarSold},
+	entity.CarListedForSale: {entity.CarInStock, entity.CarReserved, entity.CarInMaintenance, entity.CarSold},
+	entity.CarListedForRent: {entity.CarInStock, entity.CarReserved, entity.CarRentedOut, entity.CarInMaintenance},
+	entity.CarReserved:      {entity.CarInStock, entity.CarListedForSale, entity.CarListedForRent, entity.CarInMaintenance, entity.CarSold},
+	entity.CarRentedOut:     {entity.CarListedForRent},
+	entity.CarInMaintenance: {entity.CarInStock, entity.CarListedForSale, entity.CarListedForRent, entity.CarReserved},
+	entity.CarSold:          {entity.CarListedForSale},
+}
+
+func newLifecycleTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&entity.Car{}, &entity.SaleList{}, &entity.RentList{}, &entity.RentContract{},
+		&entity.CarLifecycleEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func createLifecycleCar(t *testing.T, db *gorm.DB, state string) uint {
+	t.Helper()
+	car := entity.Car{CarName: "Toyota Camry", Lifecycle: state}
+	if err := db.Create(&car).Error; err != nil {
+		t.Fatal(err)
+	}
+	return car.ID
+}
+
+func carLifecycle(t *testing.T, db *gorm.DB, carID uint) string {
+	t.Helper()
+	car, err := lifecycleCar(db, carID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return car.Lifecycle
+}
+
+func TestTransitionCarTable(t *testing.T) {
+	db := newLifecycleTestDB(t)
+	for _, from := range allLifecycleStates {
+		for _, to := range allLifecycleStates {
+			carID := createLifecycleCar(t, db, from)
+			err := TransitionCar(db, carID, to, "test", nil)
+
+			switch {
+			case from == to && to != entity.CarSold:
+				if err != nil {
+					t.Errorf("%s → %s: same state should be a no-op, got %v", from, to, err)
+				}
+			case containsString(wantLifecycleTransitions[from], to):
+				if err != nil {
+					t.Errorf("%s → %s: got %v, want allowed", from, to, err)
+					continue
+				}
+				if got := carLifecycle(t, db, carID); got != to {
+					t.Errorf("%s → %s: car is %s", from, to, got)
+				}
+				var ev entity.CarLifecycleEvent
+				if err := db.Where("car_id = ?", carID).Last(&ev).Error; err != nil || ev.FromState != from || ev.ToState != to {
+					t.Errorf("%s → %s: event = %+v (%v)", from, to, ev, err)
+				}
+			default:
+				if !errors.Is(err, ErrLifecycleTransition) {
+					t.Errorf("%s → %s: got %v, want ErrLifecycleTransition", from, to, err)
+				}
+				if got := carLifecycle(t, db, carID); got != from {
+					t.Errorf("%s → %s: rejected change still moved car to %s", from, to, got)
+				}
+			}
+		}
+	}
+}
+
+func TestTransitionCarGuards(t *testing.T) {
+	db := newLifecycleTestDB(t)
+
+	carID := createLifecycleCar(t, db, entity.CarInStock)
+	if err := TransitionCar(db, carID, "scrapped", "test", nil); !errors.Is(err, ErrInvalidLifecycleState) {
+		t.Errorf("unknown state: got %v, want ErrInvalidLifecycleState", err)
+	}
+	if err := TransitionCar(db, 9999, entity.CarInStock, "test", nil); !errors.Is(err, ErrLifecycleCarNotFound) {
+		t.Errorf("missing car: got %v, want ErrLifecycleCarNotFound", err)
+	}
+
+	// ขายแล้วปิดประกาศขายที่ยังเปิดอยู่
+	sale := entity.SaleList{CarID: carID, SalePrice: 500000, Status: entity.SaleListAvailable}
+	if err := db.Create(&sale).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := TransitionCar(db, carID, entity.CarSold, "sales contract 1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if db.First(&sale, sale.ID); sale.Status != entity.SaleListSold {
+		t.Errorf("sale list status = %s, want %s", sale.Status, entity.SaleListSold)
+	}
+
+	// รถที่ยังมีสัญญาเช่าค้าง ขายหรือเลิกให้เช่าไม่ได้ แต่เข้าอู่ได้
+	rentID := createLifecycleCar(t, db, entity.CarListedForRent)
+	rentList := entity.RentList{CarID: rentID}
+	if err := db.Create(&rentList).Error; err != nil {
+		t.Fatal(err)
+	}
+	start := localToday().AddDate(0, 0, 3)
+	if err := db.Create(&entity.RentContract{RentListID: rentList.ID, DateStart: start, DateEnd: start.Add(48 * time.Hour)}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, to := range []string{entity.CarInStock, entity.CarReserved} {
+		if err := TransitionCar(db, rentID, to, "test", nil); !errors.Is(err, ErrCarHasUpcomingRental) {
+			t.Errorf("listed_for_rent → %s with upcoming rental: got %v, want ErrCarHasUpcomingRental", to, err)
+		}
+	}
+	if err := TransitionCar(db, rentID, entity.CarInMaintenance, "test", nil); err != nil {
+		t.Errorf("listed_for_rent → in_maintenance with upcoming rental: %v", err)
+	}
+}
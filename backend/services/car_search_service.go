@@ -0,0 +1,278 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ตาราง full-text ของรถ: rowid = cars.id
+// ใช้ FTS5 จึงต้อง build ด้วย -tags sqlite_fts5 (ดู Makefile)
+// ถ้า sqlite ไม่มี FTS5 จะไม่มี index และ Search ใช้ LIKE กับตารางจริงแทน (ช้ากว่าและไม่มี bm25)
+// ข้อความถูกตัดคำด้วย utils.Tokenize ก่อนบันทึก จึงใช้ tokenizer แบบแยกด้วยช่องว่างเท่านั้น
+const carSearchTable = "car_search"
+
+// น้ำหนักคอลัมน์ title, attrs, description
+var carSearchWeights = []float64{10, 4, 1}
+
+var (
+	ErrEmptySearchQuery = errors.New("search query is empty")
+	ErrFTS5Unavailable  = errors.New("sqlite was built without FTS5, build with -tags sqlite_fts5")
+)
+
+// ตรวจครั้งเดียวต่อ process ว่า sqlite ที่ build มามี FTS5 หรือไม่
+var fts5Probe struct {
+	once sync.Once
+	ok   bool
+}
+
+// CarSearchHit ผลการค้นหาหนึ่งรายการ
+type CarSearchHit struct {
+	CarID uint
+	Score float64
+}
+
+type CarSearchService struct {
+	db *gorm.DB
+}
+
+func NewCarSearchService(db *gorm.DB) *CarSearchService {
+	return &CarSearchService{db: db}
+}
+
+// EnsureIndex สร้างตาราง full-text ถ้ายังไม่มี
+// ตาราง FTS4 ที่สร้างไว้ก่อนหน้าจะถูกลบทิ้ง ให้ Rebuild สร้างใหม่เป็น FTS5
+func (s *CarSearchService) EnsureIndex() error {
+	var sql string
+	if err := s.db.Raw("SELECT sql FROM sqlite_master WHERE name = ?", carSearchTable).Scan(&sql).Error; err != nil {
+		return err
+	}
+	if sql != "" && !strings.Contains(strings.ToLower(sql), "fts5") {
+		if err := s.db.Exec("DROP TABLE " + carSearchTable).Error; err != nil {
+			return err
+		}
+	}
+	// ไม่ต้อง log error กรณี sqlite ไม่มี fts5 เพราะคืน ErrFTS5Unavailable แทน
+	quiet := s.db.Session(&gorm.Session{Logger: s.db.Logger.LogMode(logger.Silent)})
+	err := quiet.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS " + carSearchTable +
+		" USING fts5(title, attrs, description, tokenize = 'ascii')").Error
+	if err != nil && strings.Contains(err.Error(), "no such module") {
+		return ErrFTS5Unavailable
+	}
+	return err
+}
+
+// HasFTS5 false = ไม่มี index ค้นหาด้วย LIKE แทน
+func (s *CarSearchService) HasFTS5() bool {
+	fts5Probe.once.Do(func() {
+		fts5Probe.ok = !errors.Is(s.EnsureIndex(), ErrFTS5Unavailable)
+	})
+	return fts5Probe.ok
+}
+
+// Rebuild สร้าง index ใหม่จากรถทุกคัน
+// คืน ErrFTS5Unavailable ถ้าไม่มี FTS5 (ค้นหายังใช้ได้แบบ LIKE)
+func (s *CarSearchService) Rebuild() error {
+	if !s.HasFTS5() {
+		return ErrFTS5Unavailable
+	}
+	if err := s.EnsureIndex(); err != nil {
+		return err
+	}
+	var ids []uint
+	if err := s.db.Model(&entity.Car{}).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM " + carSearchTable).Error; err != nil {
+			return err
+		}
+		txs := &CarSearchService{db: tx}
+		for _, id := range ids {
+			if err := txs.IndexCar(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IndexCar อัปเดต index ของรถหนึ่งคัน (ถ้ารถถูกลบแล้วจะเอาออกจาก index)
+func (s *CarSearchService) IndexCar(carID uint) error {
+	if !s.HasFTS5() {
+		return nil
+	}
+	var car entity.Car
+	err := s.db.Preload("Detail.Brand").
+		Preload("Detail.CarModel").
+		Preload("Detail.SubModel").
+		Preload("Province").
+		Preload("SaleList").
+		First(&car, carID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.RemoveCar(carID)
+	}
+	if err != nil {
+		return err
+	}
+
+	title := []string{car.CarName}
+	if car.Detail != nil {
+		if car.Detail.Brand != nil {
+			title = append(title, car.Detail.Brand.BrandName)
+		}
+		if car.Detail.CarModel != nil {
+			title = append(title, car.Detail.CarModel.ModelName)
+		}
+		if car.Detail.SubModel != nil {
+			title = append(title, car.Detail.SubModel.SubModelName)
+		}
+	}
+	attrs := []string{car.Color, car.Condition, strconv.Itoa(car.YearManufacture)}
+	if car.Province != nil {
+		attrs = append(attrs, car.Province.ProvinceName)
+	}
+	var desc []string
+	for _, sale := range car.SaleList {
+		desc = append(desc, sale.Description)
+	}
+
+	if err := s.RemoveCar(carID); err != nil {
+		return err
+	}
+	return s.db.Exec("INSERT INTO "+carSearchTable+"(rowid, title, attrs, description) VALUES (?, ?, ?, ?)",
+		car.ID, searchDocument(title), searchDocument(attrs), searchDocument(desc)).Error
+}
+
+// RemoveCar เอารถออกจาก index
+func (s *CarSearchService) RemoveCar(carID uint) error {
+	if !s.HasFTS5() {
+		return nil
+	}
+	return s.db.Exec("DELETE FROM "+carSearchTable+" WHERE rowid = ?", carID).Error
+}
+
+// ตัดคำและเติมคำพ้องภาษาไทย คั่นด้วยช่องว่าง
+func searchDocument(parts []string) string {
+	var out []string
+	for _, p := range parts {
+		for _, tok := range utils.Tokenize(p) {
+			out = append(out, tok)
+			out = append(out, utils.Synonyms(tok)...)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// สร้าง MATCH expression: ทุกคำต้องตรง และค้นแบบ prefix
+func searchMatchQuery(q string) string {
+	toks := utils.Tokenize(q)
+	for i, t := range toks {
+		toks[i] = t + "*"
+	}
+	return strings.Join(toks, " ")
+}
+
+// Search ค้นหารถตามคำค้น เรียงตามความเกี่ยวข้อง
+func (s *CarSearchService) Search(q string, limit, offset int) ([]CarSearchHit, int64, error) {
+	match := searchMatchQuery(q)
+	if match == "" {
+		return nil, 0, ErrEmptySearchQuery
+	}
+	if !s.HasFTS5() {
+		return s.searchLike(q, limit, offset)
+	}
+	if err := s.EnsureIndex(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := s.db.Raw("SELECT COUNT(*) FROM "+carSearchTable+" WHERE "+carSearchTable+" MATCH ?", match).
+		Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// bm25 คืนค่าติดลบ ยิ่งน้อยยิ่งเกี่ยวข้อง
+	var rows []struct {
+		ID   uint
+		Rank float64
+	}
+	err := s.db.Raw("SELECT rowid AS id, bm25("+carSearchTable+", ?, ?, ?) AS rank FROM "+carSearchTable+
+		" WHERE "+carSearchTable+" MATCH ? ORDER BY rank LIMIT ? OFFSET ?",
+		carSearchWeights[0], carSearchWeights[1], carSearchWeights[2], match, limit, offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]CarSearchHit, 0, len(rows))
+	for _, r := range rows {
+		hits = append(hits, CarSearchHit{CarID: r.ID, Score: -r.Rank})
+	}
+	return hits, total, nil
+}
+
+// ข้อความของรถแต่ละคันแยกตามคอลัมน์เดียวกับ index สำหรับค้นด้วย LIKE
+const carSearchLikeSource = `SELECT cars.id AS id,
+	COALESCE(cars.car_name, '') || ' ' || COALESCE(brands.brand_name, '') || ' ' ||
+		COALESCE(car_models.model_name, '') || ' ' || COALESCE(sub_models.sub_model_name, '') AS title,
+	COALESCE(cars.color, '') || ' ' || COALESCE(cars.condition, '') || ' ' ||
+		COALESCE(cars.year_manufacture, '') || ' ' || COALESCE(provinces.province_name, '') AS attrs,
+	COALESCE((SELECT GROUP_CONCAT(sale_lists.description, ' ') FROM sale_lists
+		WHERE sale_lists.car_id = cars.id AND sale_lists.deleted_at IS NULL), '') AS description
+FROM cars
+LEFT JOIN details ON details.id = cars.detail_id
+LEFT JOIN brands ON brands.id = details.brand_id
+LEFT JOIN car_models ON car_models.id = details.car_model_id
+LEFT JOIN sub_models ON sub_models.id = details.sub_model_id
+LEFT JOIN provinces ON provinces.id = cars.province_id
+WHERE cars.deleted_at IS NULL`
+
+// searchLike ค้นหาแบบไม่มี FTS5: ทุกคำ (หรือคำพ้อง) ต้องอยู่ในคอลัมน์ใดคอลัมน์หนึ่ง
+// คะแนนคือผลรวมน้ำหนักของคอลัมน์ที่ตรง ใช้น้ำหนักเดียวกับ bm25
+func (s *CarSearchService) searchLike(q string, limit, offset int) ([]CarSearchHit, int64, error) {
+	var where, score []string
+	var whereArgs, scoreArgs []interface{}
+	for _, tok := range utils.Tokenize(q) {
+		var either []string
+		for _, word := range append([]string{tok}, utils.Synonyms(tok)...) {
+			pattern := "%" + word + "%"
+			for i, col := range []string{"title", "attrs", "description"} {
+				either = append(either, "c."+col+" LIKE ?")
+				whereArgs = append(whereArgs, pattern)
+				score = append(score, "(CASE WHEN c."+col+" LIKE ? THEN ? ELSE 0 END)")
+				scoreArgs = append(scoreArgs, pattern, carSearchWeights[i])
+			}
+		}
+		where = append(where, "("+strings.Join(either, " OR ")+")")
+	}
+
+	base := s.db.Table("("+carSearchLikeSource+") AS c").Where(strings.Join(where, " AND "), whereArgs...)
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		ID    uint
+		Score float64
+	}
+	if err := base.Session(&gorm.Session{}).
+		Select("c.id AS id, "+strings.Join(score, " + ")+" AS score", scoreArgs...).
+		Order("score DESC, c.id DESC").Limit(limit).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]CarSearchHit, 0, len(rows))
+	for _, r := range rows {
+		hits = append(hits, CarSearchHit{CarID: r.ID, Score: r.Score})
+	}
+	return hits, total, nil
+}
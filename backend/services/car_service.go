@@ -0,0 +1,348 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultCarPageLimit = 20
+	MaxCarPageLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// CarFilter ตัวกรอง/เรียงลำดับรถ (ตรงกับ Filter.tsx และ Sorter.tsx ฝั่ง frontend)
+type CarFilter struct {
+	Brand      string   `form:"brand" json:"brand,omitempty"`
+	Model      string   `form:"model" json:"model,omitempty"`
+	SubModel   string   `form:"sub_model" json:"sub_model,omitempty"`
+	YearMin    *int     `form:"year_min" json:"year_min,omitempty"`
+	YearMax    *int     `form:"year_max" json:"year_max,omitempty"`
+	PriceMin   *float64 `form:"price_min" json:"price_min,omitempty"`
+	PriceMax   *float64 `form:"price_max" json:"price_max,omitempty"`
+	MileageMin *int     `form:"mileage_min" json:"mileage_min,omitempty"`
+	MileageMax *int     `form:"mileage_max" json:"mileage_max,omitempty"`
+	Color      string   `form:"color" json:"color,omitempty"`
+	Condition  []string `form:"condition" json:"condition,omitempty"`
+	Province   string   `form:"province" json:"province,omitempty"` // ชื่อจังหวัด หรือ ID
+	Status     string   `form:"status" json:"status,omitempty"`     // sale | rent
+	Plate      string   `form:"plate" json:"plate,omitempty"`       // ทะเบียนบางส่วนหรือทั้งหมด ไม่สนช่องว่าง/ขีด
+	VIN        string   `form:"vin" json:"vin,omitempty"`
+	Sort       string   `form:"sort" json:"sort,omitempty"` // priceAsc, priceDesc, yearUsedAsc, yearUsedDesc, mileageAsc, mileageDesc, condition
+	Page       int      `form:"page" json:"-"`
+	Limit      int      `form:"limit" json:"-"`
+	Cursor     string   `form:"cursor" json:"-"`
+}
+
+// Paginated บอกว่าผู้เรียกขอแบ่งหน้าหรือไม่ (ถ้าไม่ขอ จะคืนรถทั้งหมดเหมือนเดิม)
+func (f CarFilter) Paginated() bool {
+	return f.Page > 0 || f.Limit > 0 || f.Cursor != ""
+}
+
+// CarPage ผลลัพธ์หนึ่งหน้า
+type CarPage struct {
+	Cars       []entity.Car
+	Total      int64
+	Page       int
+	Limit      int
+	NextCursor string
+}
+
+type carCursor struct {
+	Value float64 `json:"v"`
+	ID    uint    `json:"id"`
+}
+
+// ราคาขายที่ใช้กรอง/เรียงลำดับ: ราคาต่ำสุดของ SaleList ที่ยังไม่ถูกลบ
+const carSalePriceExpr = "COALESCE((SELECT MIN(sale_lists.sale_price) FROM sale_lists WHERE sale_lists.car_id = cars.id AND sale_lists.deleted_at IS NULL), 0)"
+
+// ราคาเช่าต่อวันที่ใช้กรอง/เรียงลำดับเมื่อ status=rent: ราคาต่ำสุดของช่วงเช่า (DateforRent) ของรถ
+const carRentPriceExpr = "COALESCE((SELECT MIN(datefor_rents.rent_price) FROM rent_lists" +
+	" JOIN rent_able_dates ON rent_able_dates.rent_list_id = rent_lists.id AND rent_able_dates.deleted_at IS NULL" +
+	" JOIN datefor_rents ON datefor_rents.id = rent_able_dates.datefor_rent_id AND datefor_rents.deleted_at IS NULL" +
+	" WHERE rent_lists.car_id = cars.id AND rent_lists.deleted_at IS NULL), 0)"
+
+// carPriceExpr รายการเช่าใช้ราคาเช่า นอกนั้นใช้ราคาขาย
+func carPriceExpr(status string) string {
+	if strings.EqualFold(status, "rent") {
+		return carRentPriceExpr
+	}
+	return carSalePriceExpr
+}
+
+// เปิดขาย/เปิดเช่าตามสถานะหลักของรถ (รถที่ถูกเช่าอยู่ยังรับจองช่วงถัดไปได้)
+const (
+	carForSaleExpr = "cars.lifecycle = '" + entity.CarListedForSale + "'"
+	carForRentExpr = "cars.lifecycle IN ('" + entity.CarListedForRent + "', '" + entity.CarRentedOut + "')"
+)
+
+type CarService struct {
+	db *gorm.DB
+}
+
+func NewCarService(db *gorm.DB) *CarService {
+	return &CarService{db: db}
+}
+
+// PreloadCar preload ความสัมพันธ์ที่ใช้สร้าง CarResponse
+func PreloadCar(db *gorm.DB) *gorm.DB {
+	return db.Preload("Detail.Brand").
+		Preload("Detail.CarModel").
+		Preload("Detail.SubModel").
+		Preload("Pictures", OrderCarPictures).
+		Preload("Province").
+		Preload("RegistrationProvince").
+		Preload("Manager").
+		Preload("SaleList.Employee").
+		Preload("RentList").
+		Preload("RentList.RentAbleDates.DateforRent")
+}
+
+// OrderCarPictures เรียงรูปตามลำดับที่ผู้จัดการกำหนด
+func OrderCarPictures(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, id ASC")
+}
+
+// ApplyCarFilter เพิ่มเงื่อนไข where ตาม filter (ไม่รวม sort/แบ่งหน้า)
+func ApplyCarFilter(db *gorm.DB, f CarFilter) *gorm.DB {
+	q := db.Model(&entity.Car{})
+
+	if f.Brand != "" || f.Model != "" || f.SubModel != "" {
+		q = q.Joins("JOIN details ON details.id = cars.detail_id AND details.deleted_at IS NULL")
+	}
+	if f.Brand != "" {
+		q = q.Joins("JOIN brands ON brands.id = details.brand_id").
+			Where("LOWER(brands.brand_name) = LOWER(?)", f.Brand)
+	}
+	if f.Model != "" {
+		q = q.Joins("JOIN car_models ON car_models.id = details.car_model_id").
+			Where("LOWER(car_models.model_name) = LOWER(?)", f.Model)
+	}
+	if f.SubModel != "" {
+		q = q.Joins("JOIN sub_models ON sub_models.id = details.sub_model_id").
+			Where("LOWER(sub_models.sub_model_name) = LOWER(?)", f.SubModel)
+	}
+
+	if f.YearMin != nil {
+		q = q.Where("cars.year_manufacture >= ?", *f.YearMin)
+	}
+	if f.YearMax != nil {
+		q = q.Where("cars.year_manufacture <= ?", *f.YearMax)
+	}
+	if f.PriceMin != nil {
+		q = q.Where(carPriceExpr(f.Status)+" >= ?", *f.PriceMin)
+	}
+	if f.PriceMax != nil {
+		q = q.Where(carPriceExpr(f.Status)+" <= ?", *f.PriceMax)
+	}
+	if f.MileageMin != nil {
+		q = q.Where("cars.mileage >= ?", *f.MileageMin)
+	}
+	if f.MileageMax != nil {
+		q = q.Where("cars.mileage <= ?", *f.MileageMax)
+	}
+	if f.Color != "" {
+		q = q.Where("LOWER(cars.color) = LOWER(?)", f.Color)
+	}
+	if len(f.Condition) > 0 {
+		q = q.Where("cars.condition IN ?", f.Condition)
+	}
+	if f.Province != "" {
+		if id, err := strconv.ParseUint(f.Province, 10, 64); err == nil {
+			q = q.Where("cars.province_id = ?", id)
+		} else {
+			q = q.Where("cars.province_id IN (SELECT id FROM provinces WHERE province_name = ?)", f.Province)
+		}
+	}
+
+	if plate := NormalizePlate(f.Plate); plate != "" {
+		q = q.Where("cars.license_plate LIKE ?", "%"+plate+"%")
+	}
+	if vin := NormalizeVIN(f.VIN); vin != "" {
+		q = q.Where("cars.vin = ?", vin)
+	}
+
+	// รถที่ติดซ่อมอยู่ไม่แสดงในรายการขาย/เช่า
+	switch strings.ToLower(f.Status) {
+	case "sale":
+		q = q.Where(carForSaleExpr).Not(carInMaintenanceExpr, localToday(), localToday())
+	case "rent":
+		q = q.Where(carForRentExpr).Not(carInMaintenanceExpr, localToday(), localToday())
+	}
+	return q
+}
+
+// ลำดับสภาพรถ ดี → แย่ (ค่าอื่นอยู่ท้ายสุด)
+const carConditionRankExpr = "CASE cars.condition WHEN 'ดี' THEN 1 WHEN 'ปานกลาง' THEN 2 WHEN 'แย่' THEN 3 ELSE 4 END"
+
+var carConditionRank = map[string]float64{"ดี": 1, "ปานกลาง": 2, "แย่": 3}
+
+// ValidateCarSort ค่า sort ต้องเป็นค่าที่ Sorter.tsx ส่งมา หรือว่าง
+func ValidateCarSort(sort string) error {
+	if sort == "" {
+		return nil
+	}
+	if col, _ := carSortColumn(sort, ""); col == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidSort, sort)
+	}
+	return nil
+}
+
+// คืน expression ที่ใช้เรียง และทิศทาง (desc = true)
+// ราคาเลือกตาม status เดียวกับตัวกรอง price_min/price_max
+func carSortColumn(sort, status string) (string, bool) {
+	switch sort {
+	case "condition":
+		return carConditionRankExpr, false
+	case "priceAsc":
+		return carPriceExpr(status), false
+	case "priceDesc":
+		return carPriceExpr(status), true
+	case "yearUsedAsc": // ปีผลิตน้อย → มาก เหมือน BuyCar.tsx
+		return "cars.year_manufacture", false
+	case "yearUsedDesc":
+		return "cars.year_manufacture", true
+	case "mileageAsc":
+		return "cars.mileage", false
+	case "mileageDesc":
+		return "cars.mileage", true
+	}
+	return "", false
+}
+
+// ApplyCarSort เรียงตาม f.Sort แล้วตามด้วย id เพื่อให้ลำดับคงที่
+func ApplyCarSort(q *gorm.DB, f CarFilter) *gorm.DB {
+	if col, desc := carSortColumn(f.Sort, f.Status); col != "" {
+		if desc {
+			q = q.Order(col + " DESC")
+		} else {
+			q = q.Order(col + " ASC")
+		}
+	}
+	return q.Order("cars.id ASC")
+}
+
+// List ดึงรถตาม filter พร้อมแบ่งหน้าแบบ page/limit หรือ cursor
+func (s *CarService) List(f CarFilter) (*CarPage, error) {
+	if err := ValidateCarSort(f.Sort); err != nil {
+		return nil, err
+	}
+	var total int64
+	if err := ApplyCarFilter(s.db, f).Distinct("cars.id").Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	col, desc := carSortColumn(f.Sort, f.Status)
+	q := ApplyCarSort(ApplyCarFilter(PreloadCar(s.db), f), f)
+
+	page := &CarPage{Total: total}
+	if f.Paginated() {
+		page.Limit = f.Limit
+		if page.Limit <= 0 {
+			page.Limit = DefaultCarPageLimit
+		}
+		if page.Limit > MaxCarPageLimit {
+			page.Limit = MaxCarPageLimit
+		}
+
+		if f.Cursor != "" {
+			cur, err := decodeCarCursor(f.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case col == "":
+				q = q.Where("cars.id > ?", cur.ID)
+			case desc:
+				q = q.Where("("+col+" < ? OR ("+col+" = ? AND cars.id > ?))", cur.Value, cur.Value, cur.ID)
+			default:
+				q = q.Where("("+col+" > ? OR ("+col+" = ? AND cars.id > ?))", cur.Value, cur.Value, cur.ID)
+			}
+		} else {
+			page.Page = f.Page
+			if page.Page <= 0 {
+				page.Page = 1
+			}
+			q = q.Offset((page.Page - 1) * page.Limit)
+		}
+		q = q.Limit(page.Limit)
+	}
+
+	if err := q.Find(&page.Cars).Error; err != nil {
+		return nil, err
+	}
+
+	if f.Paginated() && len(page.Cars) == page.Limit {
+		last := page.Cars[len(page.Cars)-1]
+		page.NextCursor = encodeCarCursor(carCursor{Value: carSortValue(last, f.Sort, f.Status), ID: last.ID})
+	}
+	return page, nil
+}
+
+// ค่าของคอลัมน์ที่ใช้เรียงสำหรับรถคันสุดท้ายของหน้า
+func carSortValue(car entity.Car, sort, status string) float64 {
+	switch sort {
+	case "priceAsc", "priceDesc":
+		if strings.EqualFold(status, "rent") {
+			return carRentPrice(car)
+		}
+		price := 0.0
+		for i, s := range car.SaleList {
+			if i == 0 || s.SalePrice < price {
+				price = s.SalePrice
+			}
+		}
+		return price
+	case "yearUsedAsc", "yearUsedDesc":
+		return float64(car.YearManufacture)
+	case "mileageAsc", "mileageDesc":
+		return float64(car.Mileage)
+	case "condition":
+		if rank, ok := carConditionRank[car.Condition]; ok {
+			return rank
+		}
+		return 4
+	}
+	return 0
+}
+
+// ราคาเช่าต่ำสุดของรถ ตรงกับ carRentPriceExpr
+func carRentPrice(car entity.Car) float64 {
+	price, found := 0.0, false
+	for _, r := range car.RentList {
+		for _, rd := range r.RentAbleDates {
+			if rd.DateforRent != nil && (!found || rd.DateforRent.RentPrice < price) {
+				price, found = rd.DateforRent.RentPrice, true
+			}
+		}
+	}
+	return price
+}
+
+func encodeCarCursor(cur carCursor) string {
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCarCursor(s string) (carCursor, error) {
+	var cur carCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &cur); err != nil {
+		return cur, ErrInvalidCursor
+	}
+	return cur, nil
+}
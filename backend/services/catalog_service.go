@@ -0,0 +1,667 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCatalogNameRequired = errors.New("name is required")
+	ErrCatalogDuplicate    = errors.New("an entry with this name already exists")
+	ErrCatalogInUse        = errors.New("entry is still used by cars")
+	ErrCatalogMergeSelf    = errors.New("cannot merge an entry into itself")
+	ErrCatalogParentNeeded = errors.New("parent entry does not exist")
+)
+
+// CatalogEntry รายการ Brand/Model/SubModel สำหรับ dropdown แบบ cascade
+type CatalogEntry struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	ParentID uint   `json:"parent_id,omitempty"`
+	CarCount int64  `json:"car_count"`
+}
+
+// DuplicateGroup รายการที่ชื่อซ้ำกันเมื่อไม่สนตัวพิมพ์/ช่องว่าง (ควร merge)
+type DuplicateGroup struct {
+	Level    string         `json:"level"` // brand | model | sub_model
+	Name     string         `json:"name"`
+	ParentID uint           `json:"parent_id,omitempty"`
+	Entries  []CatalogEntry `json:"entries"`
+}
+
+// CatalogService จัดการข้อมูล Brand → CarModel → SubModel และ Detail ที่รถอ้างถึง
+type CatalogService struct {
+	db     *gorm.DB
+	search *CarSearchService
+}
+
+func NewCatalogService(db *gorm.DB) *CatalogService {
+	return &CatalogService{db: db, search: NewCarSearchService(db)}
+}
+
+func (s *CatalogService) WithContext(ctx context.Context) *CatalogService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func normalizeCatalogName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// =========================
+// Read (cascade)
+// =========================
+
+// จำนวนรถ (ที่ยังไม่ถูกลบ) ที่ใช้ Detail ตามคอลัมน์ที่ระบุ
+func (s *CatalogService) carCounts(column string, ids []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ID    uint
+		Count int64
+	}
+	err := s.db.Model(&entity.Car{}).
+		Joins("JOIN details ON details.id = cars.detail_id").
+		Where("details."+column+" IN ?", ids).
+		Group("details." + column).
+		Select("details." + column + " AS id, COUNT(*) AS count").
+		Scan(&rows).Error
+	for _, r := range rows {
+		counts[r.ID] = r.Count
+	}
+	return counts, err
+}
+
+func (s *CatalogService) ListBrands(q string) ([]CatalogEntry, error) {
+	var brands []entity.Brand
+	tx := s.db.Order("brand_name ASC")
+	if q = strings.TrimSpace(q); q != "" {
+		tx = tx.Where("LOWER(brand_name) LIKE LOWER(?)", "%"+q+"%")
+	}
+	if err := tx.Find(&brands).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(brands))
+	for i, b := range brands {
+		ids[i] = b.ID
+	}
+	counts, err := s.carCounts("brand_id", ids)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CatalogEntry, len(brands))
+	for i, b := range brands {
+		entries[i] = CatalogEntry{ID: b.ID, Name: b.BrandName, CarCount: counts[b.ID]}
+	}
+	return entries, nil
+}
+
+func (s *CatalogService) ListModels(brandID uint) ([]CatalogEntry, error) {
+	if err := s.db.First(&entity.Brand{}, brandID).Error; err != nil {
+		return nil, err
+	}
+	var models []entity.CarModel
+	if err := s.db.Where("brand_id = ?", brandID).Order("model_name ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(models))
+	for i, m := range models {
+		ids[i] = m.ID
+	}
+	counts, err := s.carCounts("car_model_id", ids)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CatalogEntry, len(models))
+	for i, m := range models {
+		entries[i] = CatalogEntry{ID: m.ID, Name: m.ModelName, ParentID: m.BrandID, CarCount: counts[m.ID]}
+	}
+	return entries, nil
+}
+
+func (s *CatalogService) ListSubModels(modelID uint) ([]CatalogEntry, error) {
+	if err := s.db.First(&entity.CarModel{}, modelID).Error; err != nil {
+		return nil, err
+	}
+	var subs []entity.SubModel
+	if err := s.db.Where("car_model_id = ?", modelID).Order("sub_model_name ASC").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(subs))
+	for i, sm := range subs {
+		ids[i] = sm.ID
+	}
+	counts, err := s.carCounts("sub_model_id", ids)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CatalogEntry, len(subs))
+	for i, sm := range subs {
+		entries[i] = CatalogEntry{ID: sm.ID, Name: sm.SubModelName, ParentID: sm.CarModelID, CarCount: counts[sm.ID]}
+	}
+	return entries, nil
+}
+
+// Duplicates หารายการที่ชื่อซ้ำกันเมื่อไม่สนตัวพิมพ์ เพื่อให้ผู้จัดการเลือก merge
+func (s *CatalogService) Duplicates() ([]DuplicateGroup, error) {
+	groups := []DuplicateGroup{}
+
+	var brands []entity.Brand
+	if err := s.db.Order("id ASC").Find(&brands).Error; err != nil {
+		return nil, err
+	}
+	byName := map[string][]CatalogEntry{}
+	var order []string
+	for _, b := range brands {
+		k := strings.ToLower(normalizeCatalogName(b.BrandName))
+		if _, ok := byName[k]; !ok {
+			order = append(order, k)
+		}
+		byName[k] = append(byName[k], CatalogEntry{ID: b.ID, Name: b.BrandName})
+	}
+	for _, k := range order {
+		if len(byName[k]) > 1 {
+			groups = append(groups, DuplicateGroup{Level: "brand", Name: byName[k][0].Name, Entries: byName[k]})
+		}
+	}
+
+	var models []entity.CarModel
+	if err := s.db.Order("id ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	type parentKey struct {
+		parent uint
+		name   string
+	}
+	modelGroups := map[parentKey][]CatalogEntry{}
+	var modelOrder []parentKey
+	for _, m := range models {
+		k := parentKey{m.BrandID, strings.ToLower(normalizeCatalogName(m.ModelName))}
+		if _, ok := modelGroups[k]; !ok {
+			modelOrder = append(modelOrder, k)
+		}
+		modelGroups[k] = append(modelGroups[k], CatalogEntry{ID: m.ID, Name: m.ModelName, ParentID: m.BrandID})
+	}
+	for _, k := range modelOrder {
+		if len(modelGroups[k]) > 1 {
+			groups = append(groups, DuplicateGroup{Level: "model", Name: modelGroups[k][0].Name, ParentID: k.parent, Entries: modelGroups[k]})
+		}
+	}
+
+	var subs []entity.SubModel
+	if err := s.db.Order("id ASC").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	subGroups := map[parentKey][]CatalogEntry{}
+	var subOrder []parentKey
+	for _, sm := range subs {
+		k := parentKey{sm.CarModelID, strings.ToLower(normalizeCatalogName(sm.SubModelName))}
+		if _, ok := subGroups[k]; !ok {
+			subOrder = append(subOrder, k)
+		}
+		subGroups[k] = append(subGroups[k], CatalogEntry{ID: sm.ID, Name: sm.SubModelName, ParentID: sm.CarModelID})
+	}
+	for _, k := range subOrder {
+		if len(subGroups[k]) > 1 {
+			groups = append(groups, DuplicateGroup{Level: "sub_model", Name: subGroups[k][0].Name, ParentID: k.parent, Entries: subGroups[k]})
+		}
+	}
+
+	columns := map[string]string{"brand": "brand_id", "model": "car_model_id", "sub_model": "sub_model_id"}
+	for gi := range groups {
+		ids := make([]uint, len(groups[gi].Entries))
+		for i, e := range groups[gi].Entries {
+			ids[i] = e.ID
+		}
+		counts, err := s.carCounts(columns[groups[gi].Level], ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range groups[gi].Entries {
+			groups[gi].Entries[i].CarCount = counts[groups[gi].Entries[i].ID]
+		}
+	}
+	return groups, nil
+}
+
+// =========================
+// Brand
+// =========================
+
+func (s *CatalogService) CreateBrand(name string) (*entity.Brand, error) {
+	name = normalizeCatalogName(name)
+	if name == "" {
+		return nil, ErrCatalogNameRequired
+	}
+	brand := entity.Brand{BrandName: name}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUnique(tx.Model(&entity.Brand{}).Where("LOWER(brand_name) = LOWER(?)", name)); err != nil {
+			return err
+		}
+		return tx.Create(&brand).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+func (s *CatalogService) RenameBrand(id uint, name string) (*entity.Brand, error) {
+	name = normalizeCatalogName(name)
+	if name == "" {
+		return nil, ErrCatalogNameRequired
+	}
+	var brand entity.Brand
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&brand, id).Error; err != nil {
+			return err
+		}
+		if err := ensureUnique(tx.Model(&entity.Brand{}).
+			Where("LOWER(brand_name) = LOWER(?) AND id <> ?", name, id)); err != nil {
+			return err
+		}
+		brand.BrandName = name
+		return tx.Save(&brand).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rebuildSearch()
+	return &brand, nil
+}
+
+// DeleteBrand ลบ Brand พร้อม Model/SubModel/Detail ข้างใต้ ถ้าไม่มีรถใช้อยู่
+func (s *CatalogService) DeleteBrand(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&entity.Brand{}, id).Error; err != nil {
+			return err
+		}
+		if err := ensureUnused(tx, "brand_id", id); err != nil {
+			return err
+		}
+		var modelIDs []uint
+		if err := tx.Model(&entity.CarModel{}).Where("brand_id = ?", id).Pluck("id", &modelIDs).Error; err != nil {
+			return err
+		}
+		if len(modelIDs) > 0 {
+			if err := tx.Where("car_model_id IN ?", modelIDs).Delete(&entity.SubModel{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", modelIDs).Delete(&entity.CarModel{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("brand_id = ?", id).Delete(&entity.Detail{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.Brand{}, id).Error
+	})
+}
+
+// MergeBrands รวม Brand ใน sourceIDs เข้ากับ targetID
+// Model ที่ชื่อซ้ำกับของ target จะถูกรวมต่อ และรถทุกคันจะชี้ไปที่ target
+func (s *CatalogService) MergeBrands(targetID uint, sourceIDs []uint) (*entity.Brand, error) {
+	var target entity.Brand
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		for _, sourceID := range sourceIDs {
+			if sourceID == targetID {
+				return ErrCatalogMergeSelf
+			}
+			var source entity.Brand
+			if err := tx.First(&source, sourceID).Error; err != nil {
+				return err
+			}
+			if err := mergeBrand(tx, &target, &source); err != nil {
+				return err
+			}
+		}
+		return dedupeDetails(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rebuildSearch()
+	return &target, nil
+}
+
+func mergeBrand(tx *gorm.DB, target, source *entity.Brand) error {
+	var models []entity.CarModel
+	if err := tx.Where("brand_id = ?", source.ID).Find(&models).Error; err != nil {
+		return err
+	}
+	for i := range models {
+		var existing entity.CarModel
+		err := tx.Where("brand_id = ? AND LOWER(model_name) = LOWER(?)", target.ID, models[i].ModelName).First(&existing).Error
+		switch {
+		case err == nil:
+			if err := mergeModel(tx, &existing, &models[i]); err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Model(&entity.CarModel{}).Where("id = ?", models[i].ID).Update("brand_id", target.ID).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+	if err := tx.Model(&entity.Detail{}).Where("brand_id = ?", source.ID).Update("brand_id", target.ID).Error; err != nil {
+		return err
+	}
+	return tx.Delete(source).Error
+}
+
+// =========================
+// CarModel
+// =========================
+
+func (s *CatalogService) CreateModel(brandID uint, name string) (*entity.CarModel, error) {
+	name = normalizeCatalogName(name)
+	if name == "" {
+		return nil, ErrCatalogNameRequired
+	}
+	model := entity.CarModel{ModelName: name, BrandID: brandID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&entity.Brand{}, brandID).Error; err != nil {
+			return notFoundAs(err, ErrCatalogParentNeeded)
+		}
+		if err := ensureUnique(tx.Model(&entity.CarModel{}).
+			Where("brand_id = ? AND LOWER(model_name) = LOWER(?)", brandID, name)); err != nil {
+			return err
+		}
+		return tx.Create(&model).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (s *CatalogService) RenameModel(id uint, name string) (*entity.CarModel, error) {
+	name = normalizeCatalogName(name)
+	if name == "" {
+		return nil, ErrCatalogNameRequired
+	}
+	var model entity.CarModel
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&model, id).Error; err != nil {
+			return err
+		}
+		if err := ensureUnique(tx.Model(&entity.CarModel{}).
+			Where("brand_id = ? AND LOWER(model_name) = LOWER(?) AND id <> ?", model.BrandID, name, id)); err != nil {
+			return err
+		}
+		model.ModelName = name
+		return tx.Save(&model).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rebuildSearch()
+	return &model, nil
+}
+
+func (s *CatalogService) DeleteModel(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&entity.CarModel{}, id).Error; err != nil {
+			return err
+		}
+		if err := ensureUnused(tx, "car_model_id", id); err != nil {
+			return err
+		}
+		if err := tx.Where("car_model_id = ?", id).Delete(&entity.SubModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("car_model_id = ?", id).Delete(&entity.Detail{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.CarModel{}, id).Error
+	})
+}
+
+// MergeModels รวม Model ใน sourceIDs เข้ากับ targetID (ย้ายไปอยู่ใต้ Brand ของ target)
+func (s *CatalogService) MergeModels(targetID uint, sourceIDs []uint) (*entity.CarModel, error) {
+	var target entity.CarModel
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		for _, sourceID := range sourceIDs {
+			if sourceID == targetID {
+				return ErrCatalogMergeSelf
+			}
+			var source entity.CarModel
+			if err := tx.First(&source, sourceID).Error; err != nil {
+				return err
+			}
+			if err := mergeModel(tx, &target, &source); err != nil {
+				return err
+			}
+		}
+		return dedupeDetails(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rebuildSearch()
+	return &target, nil
+}
+
+func mergeModel(tx *gorm.DB, target, source *entity.CarModel) error {
+	var subs []entity.SubModel
+	if err := tx.Where("car_model_id = ?", source.ID).Find(&subs).Error; err != nil {
+		return err
+	}
+	for i := range subs {
+		var existing entity.SubModel
+		err := tx.Where("car_model_id = ? AND LOWER(sub_model_name) = LOWER(?)", target.ID, subs[i].SubModelName).First(&existing).Error
+		switch {
+		case err == nil:
+			if err := mergeSubModel(tx, &existing, &subs[i], target.BrandID); err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Model(&entity.SubModel{}).Where("id = ?", subs[i].ID).Update("car_model_id", target.ID).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+	if err := tx.Model(&entity.Detail{}).Where("car_model_id = ?", source.ID).
+		Updates(map[string]interface{}{"car_model_id": target.ID, "brand_id": target.BrandID}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(source).Error
+}
+
+// =========================
+// SubModel
+// =========================
+
+func (s *CatalogService) CreateSubModel(modelID uint, name string) (*entity.SubModel, error) {
+	name = normalizeCatalogName(name)
+	if name == "" {
+		return nil, ErrCatalogNameRequired
+	}
+	sub := entity.SubModel{SubModelName: name, CarModelID: modelID}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&entity.CarModel{}, modelID).Error; err != nil {
+			return notFoundAs(err, ErrCatalogParentNeeded)
+		}
+		if err := ensureUnique(tx.Model(&entity.SubModel{}).
+			Where("car_model_id = ? AND LOWER(sub_model_name) = LOWER(?)", modelID, name)); err != nil {
+			return err
+		}
+		return tx.Create(&sub).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (s *CatalogService) RenameSubModel(id uint, name string) (*entity.SubModel, error) {
+	name = normalizeCatalogName(name)
+	if name == "" {
+		return nil, ErrCatalogNameRequired
+	}
+	var sub entity.SubModel
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&sub, id).Error; err != nil {
+			return err
+		}
+		if err := ensureUnique(tx.Model(&entity.SubModel{}).
+			Where("car_model_id = ? AND LOWER(sub_model_name) = LOWER(?) AND id <> ?", sub.CarModelID, name, id)); err != nil {
+			return err
+		}
+		sub.SubModelName = name
+		return tx.Save(&sub).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rebuildSearch()
+	return &sub, nil
+}
+
+func (s *CatalogService) DeleteSubModel(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&entity.SubModel{}, id).Error; err != nil {
+			return err
+		}
+		if err := ensureUnused(tx, "sub_model_id", id); err != nil {
+			return err
+		}
+		if err := tx.Where("sub_model_id = ?", id).Delete(&entity.Detail{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.SubModel{}, id).Error
+	})
+}
+
+// MergeSubModels รวม SubModel ใน sourceIDs เข้ากับ targetID
+func (s *CatalogService) MergeSubModels(targetID uint, sourceIDs []uint) (*entity.SubModel, error) {
+	var target entity.SubModel
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		var model entity.CarModel
+		if err := tx.First(&model, target.CarModelID).Error; err != nil {
+			return err
+		}
+		for _, sourceID := range sourceIDs {
+			if sourceID == targetID {
+				return ErrCatalogMergeSelf
+			}
+			var source entity.SubModel
+			if err := tx.First(&source, sourceID).Error; err != nil {
+				return err
+			}
+			if err := mergeSubModel(tx, &target, &source, model.BrandID); err != nil {
+				return err
+			}
+		}
+		return dedupeDetails(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rebuildSearch()
+	return &target, nil
+}
+
+func mergeSubModel(tx *gorm.DB, target, source *entity.SubModel, brandID uint) error {
+	if err := tx.Model(&entity.Detail{}).Where("sub_model_id = ?", source.ID).
+		Updates(map[string]interface{}{
+			"sub_model_id": target.ID,
+			"car_model_id": target.CarModelID,
+			"brand_id":     brandID,
+		}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(source).Error
+}
+
+// =========================
+// helpers
+// =========================
+
+// dedupeDetails หลัง merge อาจมี Detail ที่ชี้ Brand/Model/SubModel ชุดเดียวกันหลายแถว
+// ให้รถย้ายไปใช้แถวแรกแล้วลบแถวที่เหลือ
+func dedupeDetails(tx *gorm.DB) error {
+	var groups []struct {
+		BrandID    uint
+		CarModelID uint
+		SubModelID uint
+		KeepID     uint
+	}
+	if err := tx.Model(&entity.Detail{}).
+		Select("brand_id, car_model_id, sub_model_id, MIN(id) AS keep_id").
+		Group("brand_id, car_model_id, sub_model_id").
+		Having("COUNT(*) > 1").
+		Scan(&groups).Error; err != nil {
+		return err
+	}
+	for _, g := range groups {
+		var dupIDs []uint
+		if err := tx.Model(&entity.Detail{}).
+			Where("brand_id = ? AND car_model_id = ? AND sub_model_id = ? AND id <> ?", g.BrandID, g.CarModelID, g.SubModelID, g.KeepID).
+			Pluck("id", &dupIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&entity.Car{}).Where("detail_id IN ?", dupIDs).
+			Update("detail_id", g.KeepID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.Detail{}, dupIDs).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureUnique(query *gorm.DB) error {
+	var n int64
+	if err := query.Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrCatalogDuplicate
+	}
+	return nil
+}
+
+// ensureUnused ห้ามลบถ้ายังมีรถ (รวมรถที่ถูก soft delete ซึ่งยังมีสัญญาอ้างอิงอยู่) ใช้ Detail นี้
+func ensureUnused(tx *gorm.DB, column string, id uint) error {
+	var n int64
+	if err := tx.Unscoped().Model(&entity.Car{}).
+		Joins("JOIN details ON details.id = cars.detail_id").
+		Where("details."+column+" = ?", id).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrCatalogInUse
+	}
+	return nil
+}
+
+func (s *CatalogService) rebuildSearch() {
+	// ชื่อ Brand/Model อยู่ใน index ค้นหารถ
+	if err := s.search.Rebuild(); err != nil {
+		log.Println("failed to rebuild car search index:", err)
+	}
+}
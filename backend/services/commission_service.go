@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCommissionRule         = errors.New("invalid commission rule")
+	ErrCommissionRuleNotFound        = errors.New("commission rule not found")
+	ErrInvalidCommissionMonth        = errors.New("month must be YYYY-MM")
+	ErrCommissionStatementNotFound   = errors.New("commission statement not found")
+	ErrCommissionStatementApproved   = errors.New("commission statement is already approved")
+	ErrInvalidCommissionStatusFilter = errors.New("status must be draft or approved")
+)
+
+// CommissionRuleInput ข้อมูลกฎที่ผู้จัดการส่งมา
+type CommissionRuleInput struct {
+	Name        string  `json:"name" binding:"required"`
+	Kind        string  `json:"kind" binding:"required"` // sale_tier | brand_bonus | rental
+	MinAmount   float64 `json:"min_amount"`
+	Rate        float64 `json:"rate"`
+	FixedAmount float64 `json:"fixed_amount"`
+	BrandID     *uint   `json:"brand_id"`
+	Active      *bool   `json:"active"` // ไม่ระบุ = เปิดใช้
+}
+
+// CommissionStatementFilter ?month=&employee_id=&status=
+type CommissionStatementFilter struct {
+	Month      string `form:"month"`
+	EmployeeID uint   `form:"employee_id"`
+	Status     string `form:"status"`
+}
+
+// CommissionService กฎค่าคอมมิชชั่นและใบสรุปรายเดือน
+type CommissionService struct {
+	db *gorm.DB
+}
+
+func NewCommissionService(db *gorm.DB) *CommissionService {
+	return &CommissionService{db: db}
+}
+
+func (s *CommissionService) WithContext(ctx context.Context) *CommissionService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidRule(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCommissionRule, reason)
+}
+
+func (s *CommissionService) applyRuleInput(rule *entity.CommissionRule, in CommissionRuleInput) error {
+	kind := strings.ToLower(strings.TrimSpace(in.Kind))
+	switch kind {
+	case entity.CommissionSaleTier, entity.CommissionBrandBonus, entity.CommissionRental:
+	default:
+		return invalidRule("kind must be sale_tier, brand_bonus or rental")
+	}
+	if in.Rate < 0 || in.Rate > 100 {
+		return invalidRule("rate must be between 0 and 100")
+	}
+	if in.MinAmount < 0 || in.FixedAmount < 0 {
+		return invalidRule("min_amount and fixed_amount must not be negative")
+	}
+	if in.Rate == 0 && in.FixedAmount == 0 {
+		return invalidRule("rate or fixed_amount is required")
+	}
+	if kind == entity.CommissionBrandBonus {
+		if in.BrandID == nil {
+			return invalidRule("brand_id is required for brand_bonus")
+		}
+		var n int64
+		if err := s.db.Model(&entity.Brand{}).Where("id = ?", *in.BrandID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n == 0 {
+			return invalidRule("brand does not exist")
+		}
+	} else if in.BrandID != nil {
+		return invalidRule("brand_id is only used by brand_bonus")
+	}
+
+	rule.Name = strings.TrimSpace(in.Name)
+	rule.Kind = kind
+	rule.MinAmount = in.MinAmount
+	rule.Rate = in.Rate
+	rule.FixedAmount = in.FixedAmount
+	rule.BrandID = in.BrandID
+	rule.Active = in.Active == nil || *in.Active
+	return nil
+}
+
+// ListRules กฎทั้งหมด (รวมที่ปิดใช้)
+func (s *CommissionService) ListRules() ([]entity.CommissionRule, error) {
+	var rules []entity.CommissionRule
+	err := s.db.Preload("Brand").Order("kind ASC, min_amount ASC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (s *CommissionService) CreateRule(managerID uint, in CommissionRuleInput) (*entity.CommissionRule, error) {
+	rule := entity.CommissionRule{ManagerID: managerID}
+	if err := s.applyRuleInput(&rule, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateRule แก้กฎ มีผลกับใบสรุปที่สร้าง/คำนวณใหม่หลังจากนี้เท่านั้น
+func (s *CommissionService) UpdateRule(id uint, in CommissionRuleInput) (*entity.CommissionRule, error) {
+	var rule entity.CommissionRule
+	if err := s.db.First(&rule, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrCommissionRuleNotFound)
+	}
+	if err := s.applyRuleInput(&rule, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *CommissionService) DeleteRule(id uint) error {
+	res := s.db.Delete(&entity.CommissionRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCommissionRuleNotFound
+	}
+	return nil
+}
+
+// ช่วงวันที่ของเดือน YYYY-MM สำหรับเทียบกับคอลัมน์วันที่ (>= start AND < end)
+func monthRange(month string) (start, end string, err error) {
+	m, err := time.Parse("2006-01", month)
+	if err != nil {
+		return "", "", ErrInvalidCommissionMonth
+	}
+	return m.Format("2006-01-02"), m.AddDate(0, 1, 0).Format("2006-01-02"), nil
+}
+
+type commissionSale struct {
+	ID        uint
+	SalePrice float64
+	BrandID   uint
+	CarName   string
+}
+
+type commissionRentalPayment struct {
+	ID     uint
+	Amount float64
+}
+
+// roundBaht ปัดเป็นสตางค์
+func roundBaht(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Generate คำนวณใบสรุปของเดือนให้พนักงานทุกคนที่มียอดขายหรือรับค่าเช่า
+// ใบที่ยังเป็น draft จะถูกคำนวณใหม่ ใบที่อนุมัติแล้วจะไม่ถูกแก้
+func (s *CommissionService) Generate(month string) ([]entity.CommissionStatement, error) {
+	start, end, err := monthRange(month)
+	if err != nil {
+		return nil, err
+	}
+
+	var employeeIDs []uint
+	if err := s.db.Raw(`SELECT employee_id FROM sales_contracts WHERE deleted_at IS NULL AND created_at >= ? AND created_at < ?
+		UNION SELECT employee_id FROM payments WHERE deleted_at IS NULL AND rent_contract_id <> 0 AND status = ? AND payment_date >= ? AND payment_date < ?
+		UNION SELECT employee_id FROM commission_statements WHERE deleted_at IS NULL AND month = ?`,
+		start, end, PaymentStatusPaid, start, end, month).Scan(&employeeIDs).Error; err != nil {
+		return nil, err
+	}
+
+	var rules []entity.CommissionRule
+	if err := s.db.Where("active = ?", true).Order("min_amount DESC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	statements := make([]entity.CommissionStatement, 0, len(employeeIDs))
+	for _, employeeID := range employeeIDs {
+		if employeeID == 0 {
+			continue
+		}
+		var st entity.CommissionStatement
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			return s.generateOne(tx, &st, month, start, end, employeeID, rules)
+		})
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, st)
+	}
+	return statements, nil
+}
+
+func (s *CommissionService) generateOne(tx *gorm.DB, st *entity.CommissionStatement, month, start, end string, employeeID uint, rules []entity.CommissionRule) error {
+	if err := tx.Where("month = ? AND employee_id = ?", month, employeeID).
+		Attrs(entity.CommissionStatement{Month: month, EmployeeID: employeeID, Status: entity.CommissionStatementDraft}).
+		FirstOrCreate(st).Error; err != nil {
+		return err
+	}
+	if st.Status == entity.CommissionStatementApproved {
+		return nil
+	}
+
+	var sales []commissionSale
+	if err := tx.Model(&entity.SalesContract{}).
+		Select("sales_contracts.id, sale_lists.sale_price, COALESCE(details.brand_id, 0) AS brand_id, COALESCE(cars.car_name, '') AS car_name").
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id").
+		Joins("LEFT JOIN cars ON cars.id = sale_lists.car_id").
+		Joins("LEFT JOIN details ON details.id = cars.detail_id").
+		Where("sales_contracts.employee_id = ? AND sales_contracts.created_at >= ? AND sales_contracts.created_at < ?", employeeID, start, end).
+		Order("sales_contracts.id ASC").
+		Scan(&sales).Error; err != nil {
+		return err
+	}
+	var rentals []commissionRentalPayment
+	if err := tx.Model(&entity.Payment{}).
+		Select("payments.id, "+paymentAmountExpr+" AS amount").
+		Where("payments.employee_id = ? AND payments.rent_contract_id <> 0 AND payments.status = ? AND payments.payment_date >= ? AND payments.payment_date < ?",
+			employeeID, PaymentStatusPaid, start, end).
+		Scan(&rentals).Error; err != nil {
+		return err
+	}
+
+	st.SalesCount = int64(len(sales))
+	st.SalesRevenue, st.RentalRevenue = 0, 0
+	st.TierCommission, st.BrandBonus, st.RentalCommission = 0, 0, 0
+	for _, sale := range sales {
+		st.SalesRevenue += sale.SalePrice
+	}
+	for _, p := range rentals {
+		st.RentalRevenue += p.Amount
+	}
+
+	var lines []entity.CommissionStatementLine
+	tierApplied := false
+	for _, rule := range rules {
+		switch rule.Kind {
+		case entity.CommissionSaleTier:
+			// rules เรียง min_amount มากไปน้อย ขั้นแรกที่ถึงคือขั้นสูงสุด
+			if tierApplied || st.SalesCount == 0 || st.SalesRevenue < rule.MinAmount {
+				continue
+			}
+			tierApplied = true
+			amount := roundBaht(st.SalesRevenue*rule.Rate/100 + rule.FixedAmount)
+			st.TierCommission += amount
+			lines = append(lines, entity.CommissionStatementLine{
+				Kind: rule.Kind, RuleID: rule.ID, Description: rule.Name,
+				BaseAmount: st.SalesRevenue, Rate: rule.Rate, Amount: amount,
+			})
+		case entity.CommissionBrandBonus:
+			for _, sale := range sales {
+				if rule.BrandID == nil || sale.BrandID != *rule.BrandID {
+					continue
+				}
+				id := sale.ID
+				amount := roundBaht(sale.SalePrice*rule.Rate/100 + rule.FixedAmount)
+				st.BrandBonus += amount
+				lines = append(lines, entity.CommissionStatementLine{
+					Kind: rule.Kind, RuleID: rule.ID, SalesContractID: &id,
+					Description: rule.Name + ": " + sale.CarName,
+					BaseAmount:  sale.SalePrice, Rate: rule.Rate, Amount: amount,
+				})
+			}
+		case entity.CommissionRental:
+			for _, p := range rentals {
+				id := p.ID
+				amount := roundBaht(p.Amount * rule.Rate / 100)
+				st.RentalCommission += amount
+				lines = append(lines, entity.CommissionStatementLine{
+					Kind: rule.Kind, RuleID: rule.ID, PaymentID: &id, Description: rule.Name,
+					BaseAmount: p.Amount, Rate: rule.Rate, Amount: amount,
+				})
+			}
+		}
+	}
+	st.Total = roundBaht(st.TierCommission + st.BrandBonus + st.RentalCommission)
+
+	if err := tx.Unscoped().Where("statement_id = ?", st.ID).Delete(&entity.CommissionStatementLine{}).Error; err != nil {
+		return err
+	}
+	for i := range lines {
+		lines[i].StatementID = st.ID
+	}
+	if len(lines) > 0 {
+		if err := tx.Create(&lines).Error; err != nil {
+			return err
+		}
+	}
+	st.Lines = lines
+	return tx.Omit("Lines").Save(st).Error
+}
+
+// ListStatements ใบสรุปตาม filter เรียงเดือนล่าสุดก่อน
+func (s *CommissionService) ListStatements(f CommissionStatementFilter) ([]entity.CommissionStatement, error) {
+	q := s.db.Preload("Employee")
+	if f.Month != "" {
+		if _, _, err := monthRange(f.Month); err != nil {
+			return nil, err
+		}
+		q = q.Where("month = ?", f.Month)
+	}
+	if f.EmployeeID != 0 {
+		q = q.Where("employee_id = ?", f.EmployeeID)
+	}
+	switch f.Status {
+	case "":
+	case entity.CommissionStatementDraft, entity.CommissionStatementApproved:
+		q = q.Where("status = ?", f.Status)
+	default:
+		return nil, ErrInvalidCommissionStatusFilter
+	}
+	statements := []entity.CommissionStatement{}
+	err := q.Order("month DESC, employee_id ASC").Find(&statements).Error
+	return statements, err
+}
+
+// GetStatement ใบสรุปพร้อมรายการ
+func (s *CommissionService) GetStatement(id uint) (*entity.CommissionStatement, error) {
+	var st entity.CommissionStatement
+	if err := s.db.Preload("Employee").Preload("ApprovedBy").Preload("Lines").First(&st, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrCommissionStatementNotFound)
+	}
+	return &st, nil
+}
+
+// Approve ผู้จัดการอนุมัติใบสรุป หลังอนุมัติจะไม่ถูกคำนวณใหม่
+func (s *CommissionService) Approve(id, managerID uint, note string) (*entity.CommissionStatement, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var st entity.CommissionStatement
+		if err := tx.First(&st, id).Error; err != nil {
+			return notFoundAs(err, ErrCommissionStatementNotFound)
+		}
+		if st.Status == entity.CommissionStatementApproved {
+			return ErrCommissionStatementApproved
+		}
+		now := time.Now()
+		return tx.Model(&st).Updates(map[string]interface{}{
+			"status":         entity.CommissionStatementApproved,
+			"approved_by_id": managerID,
+			"approved_at":    now,
+			"note":           strings.TrimSpace(note),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetStatement(id)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+// ข้อมูลสำหรับพิมพ์สัญญา (ฝั่ง frontend นำไปจัดหน้า) รวมข้อมูลตามเล่มทะเบียนรถที่กฎหมายกำหนด
+
+// ContractParty คู่สัญญา
+type ContractParty struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+}
+
+// ContractVehicle รถในสัญญา
+type ContractVehicle struct {
+	CarID    uint   `json:"car_id"`
+	CarName  string `json:"car_name"`
+	Brand    string `json:"brand"`
+	Model    string `json:"model"`
+	SubModel string `json:"sub_model"`
+	Year     int    `json:"year_manufacture"`
+	Color    string `json:"color"`
+	Mileage  int    `json:"mileage"`
+	entity.CarIdentity
+}
+
+// ContractDocument เนื้อหาของสัญญาซื้อขายหรือสัญญาเช่า
+type ContractDocument struct {
+	Kind         string          `json:"kind"` // sale | rent
+	ContractID   uint            `json:"contract_id"`
+	ContractDate time.Time       `json:"contract_date"`
+	Customer     ContractParty   `json:"customer"`
+	Employee     *ContractParty  `json:"employee,omitempty"`
+	Price        *float64        `json:"price,omitempty"`
+	DateStart    *time.Time      `json:"date_start,omitempty"`
+	DateEnd      *time.Time      `json:"date_end,omitempty"`
+	Vehicle      ContractVehicle `json:"vehicle"`
+
+	// ข้อมูลรถที่ยังไม่ได้กรอก ต้องครบก่อนพิมพ์สัญญา
+	MissingFields []string `json:"missing_fields"`
+	// ทะเบียนหรือภาษีหมดอายุภายในช่วงสัญญา
+	Warnings []string `json:"warnings"`
+}
+
+type ContractDocumentService struct {
+	db *gorm.DB
+}
+
+func NewContractDocumentService(db *gorm.DB) *ContractDocumentService {
+	return &ContractDocumentService{db: db}
+}
+
+func preloadContractCar(db *gorm.DB, prefix string) *gorm.DB {
+	return db.Preload(prefix + ".Detail.Brand").
+		Preload(prefix + ".Detail.CarModel").
+		Preload(prefix + ".Detail.SubModel").
+		Preload(prefix + ".RegistrationProvince")
+}
+
+// SalesContract สัญญาซื้อขาย ต้องมี VIN เลขเครื่อง ทะเบียน และจังหวัดที่จดทะเบียน
+func (s *ContractDocumentService) SalesContract(id uint) (*ContractDocument, error) {
+	var sc entity.SalesContract
+	if err := preloadContractCar(s.db, "SaleList.Car").
+		Preload("Customer").
+		Preload("Employee").
+		First(&sc, id).Error; err != nil {
+		return nil, err
+	}
+
+	doc := &ContractDocument{
+		Kind:         "sale",
+		ContractID:   sc.ID,
+		ContractDate: sc.CreatedAt,
+		Customer:     customerParty(sc.Customer),
+	}
+	if sc.Employee != nil {
+		doc.Employee = &ContractParty{
+			ID:    sc.Employee.EmployeeID,
+			Name:  employeeName(sc.Employee),
+			Phone: sc.Employee.Phone,
+			Email: sc.Employee.Email,
+		}
+	}
+	if sc.SaleList != nil {
+		price := sc.SaleList.SalePrice
+		doc.Price = &price
+		if sc.SaleList.Car != nil {
+			doc.Vehicle = contractVehicle(*sc.SaleList.Car)
+		}
+	}
+	doc.MissingFields = missingVehicleFields(doc.Vehicle, true)
+	doc.Warnings = expiryWarnings(doc.Vehicle, sc.CreatedAt)
+	return doc, nil
+}
+
+// RentContract สัญญาเช่า ต้องมีทะเบียนและจังหวัดที่จดทะเบียน
+func (s *ContractDocumentService) RentContract(id uint) (*ContractDocument, error) {
+	var rc entity.RentContract
+	if err := preloadContractCar(s.db, "RentList.Car").
+		Preload("Customer").
+		First(&rc, id).Error; err != nil {
+		return nil, err
+	}
+
+	start, end := rc.DateStart, rc.DateEnd
+	doc := &ContractDocument{
+		Kind:         "rent",
+		ContractID:   rc.ID,
+		ContractDate: rc.CreatedAt,
+		Customer:     customerParty(rc.Customer),
+		DateStart:    &start,
+		DateEnd:      &end,
+	}
+	if rc.RentList != nil && rc.RentList.Car != nil {
+		doc.Vehicle = contractVehicle(*rc.RentList.Car)
+	}
+	doc.MissingFields = missingVehicleFields(doc.Vehicle, false)
+	doc.Warnings = expiryWarnings(doc.Vehicle, rc.DateEnd)
+	return doc, nil
+}
+
+func customerParty(c *entity.Customer) ContractParty {
+	if c == nil {
+		return ContractParty{}
+	}
+	return ContractParty{ID: c.ID, Name: customerName(c), Phone: c.Phone, Email: c.Email}
+}
+
+func contractVehicle(car entity.Car) ContractVehicle {
+	v := ContractVehicle{
+		CarID:       car.ID,
+		CarName:     car.CarName,
+		Year:        car.YearManufacture,
+		Color:       car.Color,
+		Mileage:     car.Mileage,
+		CarIdentity: CarIdentityOf(car),
+	}
+	if d := car.Detail; d != nil {
+		if d.Brand != nil {
+			v.Brand = d.Brand.BrandName
+		}
+		if d.CarModel != nil {
+			v.Model = d.CarModel.ModelName
+		}
+		if d.SubModel != nil {
+			v.SubModel = d.SubModel.SubModelName
+		}
+	}
+	return v
+}
+
+func missingVehicleFields(v ContractVehicle, sale bool) []string {
+	missing := []string{}
+	if sale && v.VIN == "" {
+		missing = append(missing, "vin")
+	}
+	if sale && v.EngineNumber == "" {
+		missing = append(missing, "engine_number")
+	}
+	if v.LicensePlate == "" {
+		missing = append(missing, "license_plate")
+	}
+	if v.RegistrationProvinceID == 0 {
+		missing = append(missing, "registration_province_id")
+	}
+	return missing
+}
+
+// expiryWarnings เตือนเมื่อทะเบียนหรือภาษีหมดอายุก่อนวันที่ until
+func expiryWarnings(v ContractVehicle, until time.Time) []string {
+	warnings := []string{}
+	check := func(name string, t *time.Time) {
+		if t != nil && t.Before(until) {
+			warnings = append(warnings, fmt.Sprintf("%s expires %s", name, t.Format("2006-01-02")))
+		}
+	}
+	check("registration", v.RegistrationExpiry)
+	check("tax", v.TaxExpiry)
+	return warnings
+}
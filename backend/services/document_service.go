@@ -0,0 +1,156 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxDocumentBytes = 20 << 20 // 20 MB
+	DocumentURLTTL   = 15 * time.Minute
+	documentPrefix   = "documents/"
+)
+
+// ชนิดเอกสารส่วนตัว (ใช้เป็น :kind ใน URL)
+const (
+	DocumentSalesContract = "sales-contracts"
+	DocumentRentContract  = "rent-contracts"
+	DocumentReceipt       = "receipts"
+	DocumentCarExpense    = "car-expenses"
+)
+
+var (
+	ErrDocumentTooLarge    = fmt.Errorf("document exceeds %d bytes", MaxDocumentBytes)
+	ErrUnsupportedDocument = errors.New("unsupported document type (allowed: pdf, jpeg, png)")
+	ErrUnknownDocumentKind = errors.New("unknown document kind")
+	ErrDocumentNotFound    = errors.New("document not found")
+)
+
+var allowedDocumentTypes = map[string]string{
+	"application/pdf": "pdf",
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+}
+
+// ตารางและคอลัมน์ที่เก็บ key ของเอกสารแต่ละชนิด
+var documentOwners = map[string]struct {
+	model  func() interface{}
+	column string
+}{
+	DocumentSalesContract: {func() interface{} { return &entity.SalesContract{} }, "document_key"},
+	DocumentRentContract:  {func() interface{} { return &entity.RentContract{} }, "document_key"},
+	DocumentReceipt:       {func() interface{} { return &entity.Receipt{} }, "link"},
+	DocumentCarExpense:    {func() interface{} { return &entity.CarExpense{} }, "receipt_key"},
+}
+
+// SignedDocument ลิงก์ดาวน์โหลดเอกสารที่หมดอายุ
+type SignedDocument struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DocumentService เก็บเอกสารส่วนตัว (สัญญา ใบเสร็จ) ที่ไม่เปิดให้เข้าถึงแบบสาธารณะ
+type DocumentService struct {
+	db      *gorm.DB
+	backend storage.Backend
+}
+
+func NewDocumentService(db *gorm.DB, backend storage.Backend) *DocumentService {
+	return &DocumentService{db: db, backend: backend}
+}
+
+func (s *DocumentService) WithContext(ctx context.Context) *DocumentService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// Attach บันทึกไฟล์แล้วผูก key ไว้กับเอกสาร (ไฟล์เดิมจะถูกลบถ้าเปลี่ยนไฟล์)
+func (s *DocumentService) Attach(kind string, id uint, r io.Reader) (string, error) {
+	owner, ok := documentOwners[kind]
+	if !ok {
+		return "", ErrUnknownDocumentKind
+	}
+	oldKey, err := s.currentKey(kind, id)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, MaxDocumentBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > MaxDocumentBytes {
+		return "", ErrDocumentTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedDocumentTypes[contentType]
+	if !ok {
+		return "", ErrUnsupportedDocument
+	}
+
+	sum := sha256.Sum256(data)
+	key := documentPrefix + kind + "/" + strconv.FormatUint(uint64(id), 10) + "/" + hex.EncodeToString(sum[:]) + "." + ext
+	ctx := context.Background()
+	if err := s.backend.Put(ctx, key, bytes.NewReader(data), contentType); err != nil {
+		return "", err
+	}
+	if err := s.db.Model(owner.model()).Where("id = ?", id).Update(owner.column, key).Error; err != nil {
+		return "", err
+	}
+	if oldKey != "" && oldKey != key && storage.ValidKey(oldKey) {
+		if err := s.backend.Delete(ctx, oldKey); err != nil {
+			return key, err
+		}
+	}
+	return key, nil
+}
+
+// SignedURL ลิงก์ดาวน์โหลดเอกสารที่ใช้ได้ DocumentURLTTL
+func (s *DocumentService) SignedURL(kind string, id uint) (*SignedDocument, error) {
+	key, err := s.currentKey(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" || !storage.ValidKey(key) {
+		return nil, ErrDocumentNotFound
+	}
+
+	url, err := s.backend.SignedURL(context.Background(), key, DocumentURLTTL)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SignedDocument{URL: url, ExpiresAt: time.Now().Add(DocumentURLTTL)}, nil
+}
+
+// key ที่ผูกกับเอกสารอยู่ตอนนี้ (ErrRecordNotFound ถ้าไม่มีเอกสาร id นี้)
+func (s *DocumentService) currentKey(kind string, id uint) (string, error) {
+	owner, ok := documentOwners[kind]
+	if !ok {
+		return "", ErrUnknownDocumentKind
+	}
+	var keys []sql.NullString // แถวเก่าที่เพิ่มคอลัมน์ทีหลังเป็น NULL
+	if err := s.db.Model(owner.model()).Where("id = ?", id).Pluck(owner.column, &keys).Error; err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return keys[0].String, nil
+}
@@ -2,6 +2,7 @@
 package services
 
 import (
+	"context"
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
 	"gorm.io/gorm"
 )
@@ -14,22 +15,78 @@ func NewEmployeeService(db *gorm.DB) *EmployeeService {
 	return &EmployeeService{db: db}
 }
 
-func (s *EmployeeService) List() ([]entity.Employee, error) {
+func (s *EmployeeService) WithContext(ctx context.Context) *EmployeeService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// fillSalesTotals ใส่จำนวนคันและยอดขายรวมจากสัญญาซื้อขายให้พนักงาน
+func (s *EmployeeService) fillSalesTotals(emps []entity.Employee) error {
+	if len(emps) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(emps))
+	for i := range emps {
+		ids[i] = emps[i].EmployeeID
+	}
+	var rows []struct {
+		EmployeeID uint
+		Count      int64
+		Revenue    float64
+	}
+	if err := s.db.Model(&entity.SalesContract{}).
+		Select("sales_contracts.employee_id, COUNT(*) AS count, COALESCE(SUM(sale_lists.sale_price), 0) AS revenue").
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id").
+		Where("sales_contracts.employee_id IN ?", ids).
+		Group("sales_contracts.employee_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]int, len(emps))
+	for i := range emps {
+		byID[emps[i].EmployeeID] = i
+	}
+	for _, r := range rows {
+		if i, ok := byID[r.EmployeeID]; ok {
+			emps[i].SalesCount = r.Count
+			emps[i].TotalSales = r.Revenue
+		}
+	}
+	return nil
+}
+
+func (s *EmployeeService) fillOne(emp *entity.Employee) error {
+	list := []entity.Employee{*emp}
+	if err := s.fillSalesTotals(list); err != nil {
+		return err
+	}
+	*emp = list[0]
+	return nil
+}
+
+func (s *EmployeeService) List(f EmployeeFilter) ([]entity.Employee, error) {
 	var emps []entity.Employee
-	err := s.db.Find(&emps).Error
-	return emps, err
+	if err := f.Apply(s.db).Find(&emps).Error; err != nil {
+		return emps, err
+	}
+	return emps, s.fillSalesTotals(emps)
 }
 
 func (s *EmployeeService) Get(id uint) (*entity.Employee, error) {
 	var emp entity.Employee
-	err := s.db.First(&emp, id).Error
-	return &emp, err
+	if err := s.db.First(&emp, id).Error; err != nil {
+		return &emp, err
+	}
+	return &emp, s.fillOne(&emp)
 }
 
 func (s *EmployeeService) GetByEmail(email string) (*entity.Employee, error) {
 	var emp entity.Employee
-	err := s.db.Where("email = ?", email).First(&emp).Error
-	return &emp, err
+	if err := s.db.Where("email = ?", email).First(&emp).Error; err != nil {
+		return &emp, err
+	}
+	return &emp, s.fillOne(&emp)
 }
 
 func (s *EmployeeService) Create(emp *entity.Employee) error {
@@ -44,6 +101,9 @@ func (s *EmployeeService) Update(id uint, patch map[string]any) (*entity.Employe
 	if err := s.db.Model(&emp).Updates(patch).Error; err != nil {
 		return nil, err
 	}
+	if err := s.fillOne(&emp); err != nil {
+		return nil, err
+	}
 	return &emp, nil
 }
 
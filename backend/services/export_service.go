@@ -0,0 +1,290 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/export"
+	"gorm.io/gorm"
+)
+
+// ดึงข้อมูลทีละชุดเพื่อไม่ให้ export ขนาดใหญ่กินหน่วยความจำ
+const exportBatchSize = 500
+
+// ExportService เขียนรายการต่างๆ ลง export.Writer โดยใช้ filter ชุดเดียวกับ list endpoint
+type ExportService struct {
+	db *gorm.DB
+}
+
+func NewExportService(db *gorm.DB) *ExportService {
+	return &ExportService{db: db}
+}
+
+// exportBatches ดึง q ทีละ exportBatchSize แถวตามลำดับที่ q กำหนด แล้วส่งให้ fn
+func exportBatches[T any](q *gorm.DB, fn func([]T) error) error {
+	for offset := 0; ; offset += exportBatchSize {
+		var batch []T
+		if err := q.Session(&gorm.Session{}).Limit(exportBatchSize).Offset(offset).Find(&batch).Error; err != nil {
+			return err
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+var carExportColumns = []string{
+	"ID", "Car Name", "Brand", "Model", "Sub Model", "Year", "Color", "Mileage", "Condition",
+	"Province", "Purchase Price", "Purchase Date", "Sale Price", "For Sale", "For Rent", "VIN", "Engine Number", "License Plate",
+	"Registration Province", "Registration Expiry", "Tax Expiry",
+}
+
+// Cars ?brand=&model=&...&sort= เหมือน GET /cars (ไม่แบ่งหน้า)
+func (s *ExportService) Cars(f CarFilter, w export.Writer) error {
+	if err := w.WriteHeader(carExportColumns); err != nil {
+		return err
+	}
+	q := ApplyCarSort(ApplyCarFilter(s.db.
+		Preload("Detail.Brand").
+		Preload("Detail.CarModel").
+		Preload("Detail.SubModel").
+		Preload("Province").
+		Preload("RegistrationProvince").
+		Preload("SaleList").
+		Preload("RentList"), f), f)
+
+	return exportBatches(q, func(cars []entity.Car) error {
+		for _, car := range cars {
+			var brand, model, subModel, province string
+			if d := car.Detail; d != nil {
+				if d.Brand != nil {
+					brand = d.Brand.BrandName
+				}
+				if d.CarModel != nil {
+					model = d.CarModel.ModelName
+				}
+				if d.SubModel != nil {
+					subModel = d.SubModel.SubModelName
+				}
+			}
+			if car.Province != nil {
+				province = car.Province.ProvinceName
+			}
+			identity := CarIdentityOf(car)
+			// ราคาขายต่ำสุดของประกาศขาย (ไม่มีประกาศ = ว่าง)
+			var salePrice interface{}
+			for _, sl := range car.SaleList {
+				if p, ok := salePrice.(float64); !ok || sl.SalePrice < p {
+					salePrice = sl.SalePrice
+				}
+			}
+			if err := w.WriteRow([]interface{}{
+				car.ID, car.CarName, brand, model, subModel, car.YearManufacture, car.Color, car.Mileage, car.Condition,
+				province, car.PurchasePrice, car.PurchaseDate, salePrice, len(car.SaleList) > 0, len(car.RentList) > 0,
+				car.VIN, car.EngineNumber, car.LicensePlate, identity.RegistrationProvince,
+				optionalTime(car.RegistrationExpiry), optionalTime(car.TaxExpiry),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var salesContractExportColumns = []string{
+	"ID", "Contract Date", "Car ID", "Car Name", "Sale Price", "Customer ID", "Customer Name",
+	"Employee ID", "Employee Name", "Paid Amount",
+}
+
+// SalesContracts ?employee_id=&customer_id=&date_from=&date_to=
+func (s *ExportService) SalesContracts(f SalesContractFilter, w export.Writer) error {
+	q, err := f.Apply(s.db)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteHeader(salesContractExportColumns); err != nil {
+		return err
+	}
+	q = q.Preload("SaleList.Car").
+		Preload("Customer").
+		Preload("Employee").
+		Preload("Payment").
+		Order("sales_contracts.id ASC")
+
+	return exportBatches(q, func(contracts []entity.SalesContract) error {
+		for _, sc := range contracts {
+			var carID, salePrice interface{}
+			var carName string
+			if sc.SaleList != nil {
+				salePrice = sc.SaleList.SalePrice
+				carID = sc.SaleList.CarID
+				if sc.SaleList.Car != nil {
+					carName = sc.SaleList.Car.CarName
+				}
+			}
+			if err := w.WriteRow([]interface{}{
+				sc.ID, sc.CreatedAt, carID, carName, salePrice, sc.CustomerID, customerName(sc.Customer),
+				sc.EmployeeID, employeeName(sc.Employee), paidAmount(sc.Payment),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var rentContractExportColumns = []string{
+	"ID", "Date Start", "Date End", "Days", "Car ID", "Car Name", "Customer ID", "Customer Name", "Paid Amount",
+}
+
+// RentContracts ?customer_id=&car_id=&date_from=&date_to=
+func (s *ExportService) RentContracts(f RentContractFilter, w export.Writer) error {
+	q, err := f.Apply(s.db)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteHeader(rentContractExportColumns); err != nil {
+		return err
+	}
+	q = q.Preload("RentList.Car").
+		Preload("Customer").
+		Preload("Payment").
+		Order("rent_contracts.id ASC")
+
+	return exportBatches(q, func(contracts []entity.RentContract) error {
+		for _, rc := range contracts {
+			var carID interface{}
+			var carName string
+			if rc.RentList != nil {
+				carID = rc.RentList.CarID
+				if rc.RentList.Car != nil {
+					carName = rc.RentList.Car.CarName
+				}
+			}
+			days := 0
+			if !rc.DateStart.IsZero() && !rc.DateEnd.IsZero() {
+				days = int(rc.DateEnd.Sub(rc.DateStart).Hours()/24) + 1
+			}
+			if err := w.WriteRow([]interface{}{
+				rc.ID, rc.DateStart, rc.DateEnd, days, carID, carName, rc.CustomerID, customerName(rc.Customer),
+				paidAmount(rc.Payment),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var paymentExportColumns = []string{
+	"ID", "Payment Date", "Amount", "Status", "Method", "Contract Type", "Contract ID",
+	"Customer ID", "Customer Name", "Employee ID", "Employee Name",
+}
+
+// Payments ?status=&customer_id=&employee_id=&contract=&date_from=&date_to=
+func (s *ExportService) Payments(f PaymentFilter, w export.Writer) error {
+	q, err := f.Apply(s.db)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteHeader(paymentExportColumns); err != nil {
+		return err
+	}
+	q = q.Preload("Customer").
+		Preload("Employee").
+		Preload("PaymentMethod").
+		Order("payments.id ASC")
+
+	return exportBatches(q, func(payments []entity.Payment) error {
+		for _, p := range payments {
+			var method, contractType string
+			var contractID interface{}
+			if p.PaymentMethod != nil {
+				method = p.PaymentMethod.MethodName
+			}
+			switch {
+			case p.SalesContractID != 0:
+				contractType, contractID = "sale", p.SalesContractID
+			case p.RentContractID != 0:
+				contractType, contractID = "rent", p.RentContractID
+			}
+			if err := w.WriteRow([]interface{}{
+				p.ID, p.PaymentDate, amountValue(p.Amount), p.Status, method, contractType, contractID,
+				p.CustomerID, customerName(p.Customer), p.EmployeeID, employeeName(p.Employee),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+var employeeExportColumns = []string{
+	"Employee ID", "First Name", "Last Name", "Email", "Phone", "Address", "Birthday", "Sex", "Position", "Job Type",
+}
+
+// Employees ?q=&position=&job_type= (ไม่ส่งออกรหัสผ่านและรูปโปรไฟล์)
+func (s *ExportService) Employees(f EmployeeFilter, w export.Writer) error {
+	if err := w.WriteHeader(employeeExportColumns); err != nil {
+		return err
+	}
+	q := f.Apply(s.db).Order("employee_id ASC")
+
+	return exportBatches(q, func(employees []entity.Employee) error {
+		for _, e := range employees {
+			if err := w.WriteRow([]interface{}{
+				e.EmployeeID, e.FirstName, e.LastName, e.Email, e.Phone, e.Address, e.Birthday, e.Sex, e.Position, e.JobType,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// optionalTime วันที่ที่ไม่ได้ระบุเป็นช่องว่าง
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func customerName(c *entity.Customer) string {
+	if c == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+func employeeName(e *entity.Employee) string {
+	if e == nil {
+		return ""
+	}
+	return strings.TrimSpace(e.FirstName + " " + e.LastName)
+}
+
+// amountValue Payment.Amount เก็บเป็นข้อความ ถ้าเป็นตัวเลขให้ส่งออกเป็นตัวเลข
+func amountValue(amount string) interface{} {
+	clean := strings.ReplaceAll(strings.TrimSpace(amount), ",", "")
+	if v, err := strconv.ParseFloat(clean, 64); err == nil {
+		return v
+	}
+	return amount
+}
+
+// paidAmount รวมยอดที่ชำระแล้วของสัญญา (ข้ามยอดที่ไม่ใช่ตัวเลข)
+func paidAmount(payments []*entity.Payment) float64 {
+	total := 0.0
+	for _, p := range payments {
+		if v, ok := amountValue(p.Amount).(float64); ok && p.Status == PaymentStatusPaid {
+			total += v
+		}
+	}
+	return total
+}
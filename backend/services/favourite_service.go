@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFavouriteCarNotFound = errors.New("car not found")
+	ErrFavouriteNotFound    = errors.New("car is not in favourites")
+	ErrSavedSearchNotFound  = errors.New("saved search not found")
+	ErrInvalidSavedSearch   = errors.New("invalid saved search")
+	ErrSavedSearchLimit     = errors.New("saved search limit reached")
+)
+
+// จำนวนชุดตัวกรองที่ลูกค้าหนึ่งคนบันทึกได้
+const MaxSavedSearches = 20
+
+// SavedSearchInput ชื่อและตัวกรองแบบเดียวกับ GET /cars (รวม sort)
+type SavedSearchInput struct {
+	Name   string    `json:"name" binding:"required"`
+	Filter CarFilter `json:"filter"`
+}
+
+// SavedSearchView ชุดตัวกรองพร้อม filter ที่อ่านได้
+type SavedSearchView struct {
+	entity.SavedSearch
+	Filter CarFilter `json:"filter"`
+}
+
+// FavouriteService รถที่ลูกค้าถูกใจและชุดตัวกรองที่บันทึกไว้
+type FavouriteService struct {
+	db *gorm.DB
+}
+
+func NewFavouriteService(db *gorm.DB) *FavouriteService {
+	return &FavouriteService{db: db}
+}
+
+func (s *FavouriteService) WithContext(ctx context.Context) *FavouriteService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidSavedSearch(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSavedSearch, reason)
+}
+
+// FavouriteCarIDs รถในชุด carIDs ที่ลูกค้าถูกใจ
+func FavouriteCarIDs(db *gorm.DB, customerID uint, carIDs []uint) (map[uint]bool, error) {
+	liked := map[uint]bool{}
+	if customerID == 0 || len(carIDs) == 0 {
+		return liked, nil
+	}
+	var ids []uint
+	err := db.Model(&entity.Favourite{}).
+		Where("customer_id = ? AND car_id IN ?", customerID, carIDs).
+		Pluck("car_id", &ids).Error
+	for _, id := range ids {
+		liked[id] = true
+	}
+	return liked, err
+}
+
+// Cars รถที่ลูกค้าถูกใจ ล่าสุดก่อน (preload เหมือน GET /cars)
+func (s *FavouriteService) Cars(customerID uint) ([]entity.Car, error) {
+	var ids []uint
+	err := s.db.Model(&entity.Favourite{}).Where("customer_id = ?", customerID).
+		Order("created_at DESC, id DESC").Pluck("car_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return []entity.Car{}, err
+	}
+	var cars []entity.Car
+	if err := PreloadCar(s.db).Where("id IN ?", ids).Find(&cars).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]entity.Car, len(cars))
+	for _, car := range cars {
+		byID[car.ID] = car
+	}
+	ordered := make([]entity.Car, 0, len(cars))
+	for _, id := range ids {
+		if car, ok := byID[id]; ok {
+			ordered = append(ordered, car)
+		}
+	}
+	return ordered, nil
+}
+
+// Add ถูกใจรถ (ถูกใจซ้ำไม่ error)
+func (s *FavouriteService) Add(customerID, carID uint) error {
+	if ok, err := recordExists(s.db, &entity.Car{}, "id = ?", carID); err != nil {
+		return err
+	} else if !ok {
+		return ErrFavouriteCarNotFound
+	}
+	liked, err := FavouriteCarIDs(s.db, customerID, []uint{carID})
+	if err != nil || liked[carID] {
+		return err
+	}
+	return s.db.Create(&entity.Favourite{CustomerID: customerID, CarID: carID}).Error
+}
+
+// Remove เลิกถูกใจ
+func (s *FavouriteService) Remove(customerID, carID uint) error {
+	res := s.db.Where("customer_id = ? AND car_id = ?", customerID, carID).Delete(&entity.Favourite{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrFavouriteNotFound
+	}
+	return nil
+}
+
+// Searches ชุดตัวกรองของลูกค้า เรียงตามที่สร้าง
+func (s *FavouriteService) Searches(customerID uint) ([]SavedSearchView, error) {
+	var searches []entity.SavedSearch
+	if err := s.db.Where("customer_id = ?", customerID).Order("id ASC").Find(&searches).Error; err != nil {
+		return nil, err
+	}
+	views := make([]SavedSearchView, len(searches))
+	for i, ss := range searches {
+		views[i] = SavedSearchView{SavedSearch: ss}
+		if err := json.Unmarshal([]byte(ss.Filter), &views[i].Filter); err != nil {
+			return nil, err
+		}
+	}
+	return views, nil
+}
+
+func (s *FavouriteService) findSearch(customerID, id uint) (*entity.SavedSearch, error) {
+	var ss entity.SavedSearch
+	if err := s.db.Where("customer_id = ?", customerID).First(&ss, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrSavedSearchNotFound)
+	}
+	return &ss, nil
+}
+
+func applySavedSearchInput(ss *entity.SavedSearch, in SavedSearchInput) error {
+	ss.Name = strings.TrimSpace(in.Name)
+	if ss.Name == "" {
+		return invalidSavedSearch("name is required")
+	}
+	if err := ValidateCarSort(in.Filter.Sort); err != nil {
+		return invalidSavedSearch(err.Error())
+	}
+	data, err := json.Marshal(in.Filter)
+	if err != nil {
+		return err
+	}
+	ss.Filter = string(data)
+	return nil
+}
+
+func (s *FavouriteService) searchView(ss *entity.SavedSearch, in SavedSearchInput) *SavedSearchView {
+	return &SavedSearchView{SavedSearch: *ss, Filter: in.Filter}
+}
+
+// CreateSearch บันทึกชุดตัวกรอง (ไม่เกิน MaxSavedSearches ต่อคน)
+func (s *FavouriteService) CreateSearch(customerID uint, in SavedSearchInput) (*SavedSearchView, error) {
+	var n int64
+	if err := s.db.Model(&entity.SavedSearch{}).Where("customer_id = ?", customerID).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n >= MaxSavedSearches {
+		return nil, ErrSavedSearchLimit
+	}
+	ss := entity.SavedSearch{CustomerID: customerID}
+	if err := applySavedSearchInput(&ss, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&ss).Error; err != nil {
+		return nil, err
+	}
+	return s.searchView(&ss, in), nil
+}
+
+// UpdateSearch เปลี่ยนชื่อ/ตัวกรอง
+func (s *FavouriteService) UpdateSearch(customerID, id uint, in SavedSearchInput) (*SavedSearchView, error) {
+	ss, err := s.findSearch(customerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySavedSearchInput(ss, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(ss).Error; err != nil {
+		return nil, err
+	}
+	return s.searchView(ss, in), nil
+}
+
+// DeleteSearch ลบชุดตัวกรองพร้อมการติดตามที่อ้างถึง
+func (s *FavouriteService) DeleteSearch(customerID, id uint) error {
+	ss, err := s.findSearch(customerID, id)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("saved_search_id = ?", ss.ID).Delete(&entity.PriceAlert{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(ss).Error
+	})
+}
@@ -0,0 +1,632 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLeadNotFound         = errors.New("lead not found")
+	ErrInvalidLead          = errors.New("invalid lead")
+	ErrInvalidLeadStage     = errors.New("stage must be new, contacted, qualified, negotiating or lost")
+	ErrLeadClosed           = errors.New("lead is already won")
+	ErrLeadTaskNotFound     = errors.New("lead task not found")
+	ErrLeadTaskCompleted    = errors.New("lead task is already completed")
+	ErrLeadContractMismatch = errors.New("contract does not match the lead's listing or customer")
+	ErrLeadContractLinked   = errors.New("contract is already linked to another lead")
+)
+
+// StaffActor ผู้ใช้ฝั่งร้านที่เรียก API (พนักงานหรือผู้จัดการ อย่างใดอย่างหนึ่ง)
+type StaffActor struct {
+	EmployeeID uint
+	ManagerID  uint
+}
+
+func (a StaffActor) IsManager() bool {
+	return a.ManagerID != 0
+}
+
+// ids สำหรับบันทึกว่าใครเป็นผู้ทำรายการ
+func (a StaffActor) ids() (employeeID, managerID *uint) {
+	if a.IsManager() {
+		id := a.ManagerID
+		return nil, &id
+	}
+	id := a.EmployeeID
+	return &id, nil
+}
+
+var openLeadStages = map[string]bool{
+	entity.LeadStageNew:         true,
+	entity.LeadStageContacted:   true,
+	entity.LeadStageQualified:   true,
+	entity.LeadStageNegotiating: true,
+}
+
+var leadNoteKinds = map[string]bool{
+	entity.LeadActivityNote:  true,
+	entity.LeadActivityCall:  true,
+	entity.LeadActivityVisit: true,
+}
+
+// LeadInput ข้อมูล lead ต้องมีลูกค้าในระบบหรือชื่อ+ช่องทางติดต่อ และประกาศขายหรือเช่าอย่างใดอย่างหนึ่ง
+type LeadInput struct {
+	CustomerID   *uint  `json:"customer_id"`
+	ContactName  string `json:"contact_name"`
+	ContactPhone string `json:"contact_phone"`
+	ContactEmail string `json:"contact_email"`
+	Source       string `json:"source"`
+	SaleListID   *uint  `json:"sale_list_id"`
+	RentListID   *uint  `json:"rent_list_id"`
+	EmployeeID   uint   `json:"employee_id"` // ผู้จัดการกำหนดได้ พนักงานจะเป็นตัวเองเสมอ
+}
+
+// LeadFilter ?stage=&employee_id=&customer_id=&open=true
+type LeadFilter struct {
+	Stage      string `form:"stage"`
+	EmployeeID uint   `form:"employee_id"`
+	CustomerID uint   `form:"customer_id"`
+	Open       bool   `form:"open"`
+}
+
+type LeadStageInput struct {
+	Stage  string `json:"stage" binding:"required"`
+	Reason string `json:"reason"` // จำเป็นเมื่อ stage = lost
+}
+
+type LeadActivityInput struct {
+	Kind string `json:"kind"` // note | call | visit (ไม่ระบุ = note)
+	Note string `json:"note" binding:"required"`
+}
+
+type LeadTaskInput struct {
+	Title string `json:"title" binding:"required"`
+	DueAt string `json:"due_at" binding:"required"` // RFC3339 หรือ YYYY-MM-DD HH:MM (เวลาท้องถิ่น)
+}
+
+// LeadTaskFilter ?employee_id=&open=true&due_before=YYYY-MM-DD
+type LeadTaskFilter struct {
+	EmployeeID uint   `form:"employee_id"`
+	Open       bool   `form:"open"`
+	DueBefore  string `form:"due_before"`
+}
+
+type LeadConvertInput struct {
+	SalesContractID *uint `json:"sales_contract_id"`
+	RentContractID  *uint `json:"rent_contract_id"`
+}
+
+// LeadEmployeeStats ผลการปิดการขายของพนักงานแต่ละคน
+type LeadEmployeeStats struct {
+	EmployeeID     uint    `json:"employee_id"`
+	EmployeeName   string  `json:"employee_name"`
+	Total          int64   `json:"total"`
+	Open           int64   `json:"open"`
+	Won            int64   `json:"won"`
+	Lost           int64   `json:"lost"`
+	ConversionRate float64 `json:"conversion_rate"` // won / (won + lost) เป็น %
+}
+
+// LeadStats สรุปจำนวน lead ตามขั้นและอัตราการเปลี่ยนเป็นสัญญา
+type LeadStats struct {
+	ByStage        map[string]int64    `json:"by_stage"`
+	Total          int64               `json:"total"`
+	ConversionRate float64             `json:"conversion_rate"`
+	Employees      []LeadEmployeeStats `json:"employees"`
+}
+
+// LeadService CRM ติดตามลูกค้าที่สนใจรถจนถึงการทำสัญญา
+type LeadService struct {
+	db *gorm.DB
+}
+
+func NewLeadService(db *gorm.DB) *LeadService {
+	return &LeadService{db: db}
+}
+
+func (s *LeadService) WithContext(ctx context.Context) *LeadService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidLead(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidLead, reason)
+}
+
+func recordExists(db *gorm.DB, model interface{}, where string, id uint) (bool, error) {
+	var n int64
+	err := db.Model(model).Where(where, id).Count(&n).Error
+	return n > 0, err
+}
+
+func (s *LeadService) applyInput(actor StaffActor, lead *entity.Lead, in LeadInput) error {
+	lead.ContactName = strings.TrimSpace(in.ContactName)
+	lead.ContactPhone = strings.TrimSpace(in.ContactPhone)
+	lead.ContactEmail = strings.TrimSpace(in.ContactEmail)
+	lead.Source = strings.ToLower(strings.TrimSpace(in.Source))
+
+	if in.CustomerID != nil {
+		ok, err := recordExists(s.db, &entity.Customer{}, "id = ?", *in.CustomerID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidLead("customer does not exist")
+		}
+	} else if lead.ContactName == "" || (lead.ContactPhone == "" && lead.ContactEmail == "") {
+		return invalidLead("customer_id or contact_name with contact_phone/contact_email is required")
+	}
+	lead.CustomerID = in.CustomerID
+
+	switch {
+	case (in.SaleListID == nil) == (in.RentListID == nil):
+		return invalidLead("exactly one of sale_list_id or rent_list_id is required")
+	case in.SaleListID != nil:
+		ok, err := recordExists(s.db, &entity.SaleList{}, "id = ?", *in.SaleListID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidLead("sale list does not exist")
+		}
+	default:
+		ok, err := recordExists(s.db, &entity.RentList{}, "id = ?", *in.RentListID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidLead("rent list does not exist")
+		}
+	}
+	lead.SaleListID = in.SaleListID
+	lead.RentListID = in.RentListID
+
+	if !actor.IsManager() {
+		// พนักงานสร้าง lead ให้ตัวเอง และโอนให้คนอื่นไม่ได้
+		if lead.EmployeeID == 0 {
+			lead.EmployeeID = actor.EmployeeID
+		}
+		return nil
+	}
+	if in.EmployeeID == 0 {
+		return invalidLead("employee_id is required")
+	}
+	ok, err := recordExists(s.db, &entity.Employee{}, "employee_id = ?", in.EmployeeID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return invalidLead("employee does not exist")
+	}
+	lead.EmployeeID = in.EmployeeID
+	return nil
+}
+
+func (s *LeadService) logActivity(tx *gorm.DB, actor StaffActor, leadID uint, kind, note string) error {
+	empID, mgrID := actor.ids()
+	return tx.Create(&entity.LeadActivity{
+		LeadID: leadID, Kind: kind, Note: note, EmployeeID: empID, ManagerID: mgrID,
+	}).Error
+}
+
+// find lead ที่ผู้ใช้มีสิทธิ์ดู (พนักงานเห็นเฉพาะ lead ของตัวเอง)
+func (s *LeadService) find(tx *gorm.DB, actor StaffActor, id uint) (*entity.Lead, error) {
+	var lead entity.Lead
+	if err := tx.First(&lead, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrLeadNotFound)
+	}
+	if !actor.IsManager() && lead.EmployeeID != actor.EmployeeID {
+		return nil, ErrLeadNotFound
+	}
+	return &lead, nil
+}
+
+func preloadLead(db *gorm.DB) *gorm.DB {
+	return db.Preload("Customer").Preload("Employee").Preload("SaleList.Car").Preload("RentList.Car")
+}
+
+// List lead ตาม filter เรียงตามที่อัปเดตล่าสุด
+func (s *LeadService) List(actor StaffActor, f LeadFilter) ([]entity.Lead, error) {
+	q := preloadLead(s.db)
+	if !actor.IsManager() {
+		f.EmployeeID = actor.EmployeeID
+	}
+	if f.EmployeeID != 0 {
+		q = q.Where("employee_id = ?", f.EmployeeID)
+	}
+	if f.CustomerID != 0 {
+		q = q.Where("customer_id = ?", f.CustomerID)
+	}
+	if f.Stage != "" {
+		q = q.Where("stage = ?", f.Stage)
+	}
+	if f.Open {
+		q = q.Where("stage NOT IN ?", []string{entity.LeadStageWon, entity.LeadStageLost})
+	}
+	leads := []entity.Lead{}
+	err := q.Order("updated_at DESC").Find(&leads).Error
+	return leads, err
+}
+
+// Get lead พร้อมงานติดตามและประวัติกิจกรรม
+func (s *LeadService) Get(actor StaffActor, id uint) (*entity.Lead, error) {
+	if _, err := s.find(s.db, actor, id); err != nil {
+		return nil, err
+	}
+	var lead entity.Lead
+	err := preloadLead(s.db).
+		Preload("Tasks", func(db *gorm.DB) *gorm.DB { return db.Order("due_at ASC") }).
+		Preload("Activities", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC, id DESC") }).
+		First(&lead, id).Error
+	return &lead, err
+}
+
+func (s *LeadService) Create(actor StaffActor, in LeadInput) (*entity.Lead, error) {
+	lead := entity.Lead{Stage: entity.LeadStageNew}
+	if err := s.applyInput(actor, &lead, in); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&lead).Error; err != nil {
+			return err
+		}
+		return s.logActivity(tx, actor, lead.ID, entity.LeadActivityNote, "lead created")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(actor, lead.ID)
+}
+
+// Update แก้ข้อมูลผู้ติดต่อ รถที่สนใจ หรือผู้ดูแล (เฉพาะผู้จัดการ)
+func (s *LeadService) Update(actor StaffActor, id uint, in LeadInput) (*entity.Lead, error) {
+	lead, err := s.find(s.db, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if lead.Stage == entity.LeadStageWon {
+		return nil, ErrLeadClosed
+	}
+	prevEmployee := lead.EmployeeID
+	if err := s.applyInput(actor, lead, in); err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(lead).Updates(map[string]interface{}{
+			"customer_id":   lead.CustomerID,
+			"contact_name":  lead.ContactName,
+			"contact_phone": lead.ContactPhone,
+			"contact_email": lead.ContactEmail,
+			"source":        lead.Source,
+			"sale_list_id":  lead.SaleListID,
+			"rent_list_id":  lead.RentListID,
+			"employee_id":   lead.EmployeeID,
+		}).Error; err != nil {
+			return err
+		}
+		if lead.EmployeeID != prevEmployee {
+			// งานที่ยังไม่เสร็จย้ายไปอยู่กับผู้ดูแลคนใหม่ด้วย
+			if err := tx.Model(&entity.LeadTask{}).
+				Where("lead_id = ? AND completed_at IS NULL", lead.ID).
+				Update("employee_id", lead.EmployeeID).Error; err != nil {
+				return err
+			}
+			return s.logActivity(tx, actor, lead.ID, entity.LeadActivityNote,
+				fmt.Sprintf("reassigned from employee %d to %d", prevEmployee, lead.EmployeeID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(actor, id)
+}
+
+// ChangeStage เปลี่ยนขั้นของ lead ที่ยังไม่ปิดการขาย (lost ต้องมีเหตุผล เปิดใหม่ได้)
+func (s *LeadService) ChangeStage(actor StaffActor, id uint, in LeadStageInput) (*entity.Lead, error) {
+	stage := strings.ToLower(strings.TrimSpace(in.Stage))
+	if !openLeadStages[stage] && stage != entity.LeadStageLost {
+		return nil, ErrInvalidLeadStage
+	}
+	reason := strings.TrimSpace(in.Reason)
+	if stage == entity.LeadStageLost && reason == "" {
+		return nil, invalidLead("reason is required when marking a lead as lost")
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		lead, err := s.find(tx, actor, id)
+		if err != nil {
+			return err
+		}
+		if lead.Stage == entity.LeadStageWon {
+			return ErrLeadClosed
+		}
+		if lead.Stage == stage {
+			return nil
+		}
+		prev := lead.Stage
+		lostReason := ""
+		if stage == entity.LeadStageLost {
+			lostReason = reason
+		}
+		if err := tx.Model(lead).Updates(map[string]interface{}{"stage": stage, "lost_reason": lostReason}).Error; err != nil {
+			return err
+		}
+		note := prev + " -> " + stage
+		if reason != "" {
+			note += ": " + reason
+		}
+		return s.logActivity(tx, actor, lead.ID, entity.LeadActivityStageChange, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(actor, id)
+}
+
+// AddActivity บันทึกการติดต่อลูกค้า
+func (s *LeadService) AddActivity(actor StaffActor, id uint, in LeadActivityInput) (*entity.LeadActivity, error) {
+	kind := strings.ToLower(strings.TrimSpace(in.Kind))
+	if kind == "" {
+		kind = entity.LeadActivityNote
+	}
+	if !leadNoteKinds[kind] {
+		return nil, invalidLead("kind must be note, call or visit")
+	}
+	lead, err := s.find(s.db, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	empID, mgrID := actor.ids()
+	activity := entity.LeadActivity{
+		LeadID: lead.ID, Kind: kind, Note: strings.TrimSpace(in.Note), EmployeeID: empID, ManagerID: mgrID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&activity).Error; err != nil {
+			return err
+		}
+		// ให้ lead ที่เพิ่งติดต่อขึ้นบนสุดของรายการ
+		return tx.Model(lead).Update("updated_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// parseLocalDateTime รับ RFC3339 หรือ YYYY-MM-DD HH:MM (เวลาท้องถิ่น)
+func parseLocalDateTime(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	var err error
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func parseDueAt(v string) (time.Time, error) {
+	t, err := parseLocalDateTime(v)
+	if err != nil {
+		return time.Time{}, invalidLead("due_at must be RFC3339 or YYYY-MM-DD HH:MM")
+	}
+	return t, nil
+}
+
+// AddTask เพิ่มงานติดตามให้ผู้ดูแล lead
+func (s *LeadService) AddTask(actor StaffActor, id uint, in LeadTaskInput) (*entity.LeadTask, error) {
+	due, err := parseDueAt(in.DueAt)
+	if err != nil {
+		return nil, err
+	}
+	var task entity.LeadTask
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		lead, err := s.find(tx, actor, id)
+		if err != nil {
+			return err
+		}
+		if lead.Stage == entity.LeadStageWon || lead.Stage == entity.LeadStageLost {
+			return invalidLead("cannot add tasks to a closed lead")
+		}
+		task = entity.LeadTask{
+			LeadID: lead.ID, Title: strings.TrimSpace(in.Title), DueAt: due, EmployeeID: lead.EmployeeID,
+		}
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		return s.logActivity(tx, actor, lead.ID, entity.LeadActivityTask,
+			"task added: "+task.Title+" (due "+due.Format("2006-01-02 15:04")+")")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CompleteTask ปิดงานติดตาม
+func (s *LeadService) CompleteTask(actor StaffActor, leadID, taskID uint) (*entity.LeadTask, error) {
+	var task entity.LeadTask
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.find(tx, actor, leadID); err != nil {
+			return err
+		}
+		if err := tx.Where("lead_id = ?", leadID).First(&task, taskID).Error; err != nil {
+			return notFoundAs(err, ErrLeadTaskNotFound)
+		}
+		if task.CompletedAt != nil {
+			return ErrLeadTaskCompleted
+		}
+		now := time.Now()
+		task.CompletedAt = &now
+		if err := tx.Model(&task).Update("completed_at", now).Error; err != nil {
+			return err
+		}
+		return s.logActivity(tx, actor, leadID, entity.LeadActivityTask, "task completed: "+task.Title)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Tasks งานติดตามตาม filter เรียงตามกำหนดส่ง (พนักงานเห็นเฉพาะของตัวเอง)
+func (s *LeadService) Tasks(actor StaffActor, f LeadTaskFilter) ([]entity.LeadTask, error) {
+	q := s.db.Model(&entity.LeadTask{})
+	if !actor.IsManager() {
+		f.EmployeeID = actor.EmployeeID
+	}
+	if f.EmployeeID != 0 {
+		q = q.Where("employee_id = ?", f.EmployeeID)
+	}
+	if f.Open {
+		q = q.Where("completed_at IS NULL")
+	}
+	var err error
+	if q, err = applyDateRange(q, "due_at", "", f.DueBefore); err != nil {
+		return nil, err
+	}
+	tasks := []entity.LeadTask{}
+	err = q.Order("due_at ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// Convert ผูก lead กับสัญญาซื้อขายหรือสัญญาเช่าที่เกิดขึ้นจริงและปิดเป็น won
+func (s *LeadService) Convert(actor StaffActor, id uint, in LeadConvertInput) (*entity.Lead, error) {
+	if (in.SalesContractID == nil) == (in.RentContractID == nil) {
+		return nil, invalidLead("exactly one of sales_contract_id or rent_contract_id is required")
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		lead, err := s.find(tx, actor, id)
+		if err != nil {
+			return err
+		}
+		if lead.Stage == entity.LeadStageWon {
+			return ErrLeadClosed
+		}
+
+		var customerID uint
+		var column string
+		var contractID uint
+		if in.SalesContractID != nil {
+			var sc entity.SalesContract
+			if err := tx.First(&sc, *in.SalesContractID).Error; err != nil {
+				return notFoundAs(err, invalidLead("sales contract does not exist"))
+			}
+			if lead.SaleListID == nil || *lead.SaleListID != sc.SaleListID {
+				return ErrLeadContractMismatch
+			}
+			customerID, column, contractID = sc.CustomerID, "sales_contract_id", sc.ID
+		} else {
+			var rc entity.RentContract
+			if err := tx.First(&rc, *in.RentContractID).Error; err != nil {
+				return notFoundAs(err, invalidLead("rent contract does not exist"))
+			}
+			if lead.RentListID == nil || *lead.RentListID != rc.RentListID {
+				return ErrLeadContractMismatch
+			}
+			customerID, column, contractID = rc.CustomerID, "rent_contract_id", rc.ID
+		}
+		if lead.CustomerID != nil && *lead.CustomerID != customerID {
+			return ErrLeadContractMismatch
+		}
+		var linked int64
+		if err := tx.Model(&entity.Lead{}).Where(column+" = ? AND id <> ?", contractID, lead.ID).Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return ErrLeadContractLinked
+		}
+
+		prev := lead.Stage
+		if err := tx.Model(lead).Updates(map[string]interface{}{
+			"stage":        entity.LeadStageWon,
+			"lost_reason":  "",
+			"customer_id":  customerID, // walk-in ที่มาทำสัญญาจะถูกผูกกับบัญชีลูกค้า
+			column:         contractID,
+			"converted_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return s.logActivity(tx, actor, lead.ID, entity.LeadActivityConversion,
+			fmt.Sprintf("%s -> won (%s %d)", prev, strings.TrimSuffix(column, "_id"), contractID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(actor, id)
+}
+
+// Stats จำนวน lead ตามขั้นและอัตราการปิดการขายรายพนักงาน นับตามวันที่สร้าง lead
+func (s *LeadService) Stats(dateFrom, dateTo string) (*LeadStats, error) {
+	q, err := applyDateRange(s.db.Model(&entity.Lead{}), "created_at", dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		EmployeeID uint
+		Stage      string
+		N          int64
+	}
+	if err := q.Select("employee_id, stage, COUNT(*) AS n").Group("employee_id, stage").
+		Order("employee_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &LeadStats{ByStage: map[string]int64{}, Employees: []LeadEmployeeStats{}}
+	byEmp := map[uint]int{}
+	ids := []uint{}
+	var won, lost int64
+	for _, r := range rows {
+		stats.ByStage[r.Stage] += r.N
+		stats.Total += r.N
+		i, ok := byEmp[r.EmployeeID]
+		if !ok {
+			i = len(stats.Employees)
+			byEmp[r.EmployeeID] = i
+			ids = append(ids, r.EmployeeID)
+			stats.Employees = append(stats.Employees, LeadEmployeeStats{EmployeeID: r.EmployeeID})
+		}
+		e := &stats.Employees[i]
+		e.Total += r.N
+		switch r.Stage {
+		case entity.LeadStageWon:
+			e.Won += r.N
+			won += r.N
+		case entity.LeadStageLost:
+			e.Lost += r.N
+			lost += r.N
+		default:
+			e.Open += r.N
+		}
+	}
+	names, err := employeeNames(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stats.Employees {
+		e := &stats.Employees[i]
+		e.EmployeeName = names[e.EmployeeID]
+		e.ConversionRate = conversionRate(e.Won, e.Lost)
+	}
+	stats.ConversionRate = conversionRate(won, lost)
+	return stats, nil
+}
+
+func conversionRate(won, lost int64) float64 {
+	if won+lost == 0 {
+		return 0
+	}
+	return math.Round(float64(won)*10000/float64(won+lost)) / 100
+}
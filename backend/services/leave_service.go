@@ -1,12 +1,60 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/PanuAutawo/CarTentManagement/backend/entity"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidLeaveType         = errors.New("type must be sick, personal or vacation")
+	ErrInvalidLeaveRange        = errors.New("endDate must not be before startDate")
+	ErrLeaveCrossesYear         = errors.New("leave must not span two calendar years")
+	ErrLeaveOverlap             = errors.New("leave overlaps an existing pending or approved request")
+	ErrInsufficientLeaveBalance = errors.New("insufficient leave balance")
+	ErrLeaveNotFound            = errors.New("leave request not found")
+	ErrLeaveAlreadyDecided      = errors.New("leave request has already been decided")
+	ErrInvalidLeaveStatus       = errors.New("status must be approved or denied")
+	ErrInvalidLeaveEntitlement  = errors.New("entitled must not be negative or less than days already used")
+)
+
+// สิทธิ์วันลาเริ่มต้นต่อปี (ตามขั้นต่ำของกฎหมายคุ้มครองแรงงาน) ผู้จัดการปรับรายคนได้
+var defaultLeaveEntitlement = map[string]int{
+	entity.LeaveTypeSick:     30,
+	entity.LeaveTypePersonal: 3,
+	entity.LeaveTypeVacation: 6,
+}
+
+var leaveTypeOrder = []string{entity.LeaveTypeSick, entity.LeaveTypePersonal, entity.LeaveTypeVacation}
+
+// ชื่อภาษาไทยที่หน้าเว็บใช้
+var leaveTypeAliases = map[string]string{
+	"ลาป่วย":    entity.LeaveTypeSick,
+	"ลากิจ":     entity.LeaveTypePersonal,
+	"ลาพักร้อน": entity.LeaveTypeVacation,
+}
+
+// LeaveInput คำขอลาที่พนักงานส่งมา วันที่เป็น YYYY-MM-DD
+type LeaveInput struct {
+	StartDate string `json:"startDate" binding:"required"`
+	EndDate   string `json:"endDate" binding:"required"`
+	Type      string `json:"type" binding:"required"`
+	Reason    string `json:"reason"`
+}
+
+// LeaveDecision ผลการพิจารณาของผู้จัดการ
+type LeaveDecision struct {
+	Status  string `json:"status" binding:"required"` // approved | denied
+	Comment string `json:"comment"`
+}
+
 type LeaveService struct {
 	db *gorm.DB
 }
@@ -15,12 +63,21 @@ func NewLeaveService(db *gorm.DB) *LeaveService {
 	return &LeaveService{db: db}
 }
 
-// ดึงคำขอลาตาม status
-func (s *LeaveService) List(filterStatus string) ([]entity.LeaveRequest, error) {
+func (s *LeaveService) WithContext(ctx context.Context) *LeaveService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// ดึงคำขอลาตาม status (empID = 0 คือทุกคน)
+func (s *LeaveService) List(filterStatus string, empID uint) ([]entity.LeaveRequest, error) {
 	q := s.db.Order("created_at desc")
 	if filterStatus != "" {
 		q = q.Where("status = ?", filterStatus)
 	}
+	if empID != 0 {
+		q = q.Where("employee_id = ?", empID)
+	}
 	var items []entity.LeaveRequest
 	if err := q.Find(&items).Error; err != nil {
 		return nil, err
@@ -37,18 +94,239 @@ func (s *LeaveService) ListByEmployee(empID uint) ([]entity.LeaveRequest, error)
 	return items, nil
 }
 
-// สร้างคำขอลาใหม่
-func (s *LeaveService) Create(l *entity.LeaveRequest) error {
-	if l.LeaveID == "" {
-		l.LeaveID = "L" + time.Now().Format("20060102150405")
+func normalizeLeaveType(t string) (string, error) {
+	t = strings.TrimSpace(t)
+	if alias, ok := leaveTypeAliases[t]; ok {
+		return alias, nil
 	}
-	if l.Status == "" {
-		l.Status = "pending"
+	t = strings.ToLower(t)
+	if _, ok := defaultLeaveEntitlement[t]; !ok {
+		return "", ErrInvalidLeaveType
+	}
+	return t, nil
+}
+
+// newLeaveID รหัสคำขอลา เช่น L20250919-3fa2c1d98e07 (ส่วนท้ายสุ่ม ไม่ชนกันเมื่อส่งพร้อมกัน)
+func newLeaveID(now time.Time) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	return s.db.Create(l).Error
+	return "L" + now.Format("20060102") + "-" + hex.EncodeToString(b), nil
+}
+
+// balance สิทธิ์วันลาของปี สร้างจากค่าเริ่มต้นถ้ายังไม่มี
+func (s *LeaveService) balance(tx *gorm.DB, empID uint, year int, leaveType string) (*entity.LeaveBalance, error) {
+	var b entity.LeaveBalance
+	err := tx.Where(entity.LeaveBalance{EmployeeID: empID, Year: year, Type: leaveType}).
+		Attrs(entity.LeaveBalance{Entitled: defaultLeaveEntitlement[leaveType]}).
+		FirstOrCreate(&b).Error
+	return &b, err
+}
+
+// pendingDays วันลาที่ยังรออนุมัติของปีและประเภทนั้น
+func pendingDays(tx *gorm.DB, empID uint, year int, leaveType string) (int, error) {
+	var days int
+	err := tx.Model(&entity.LeaveRequest{}).
+		Select("COALESCE(SUM(days), 0)").
+		Where("employee_id = ? AND type = ? AND status = ? AND substr(start_date, 1, 4) = ?",
+			empID, leaveType, entity.LeaveStatusPending, strconv.Itoa(year)).
+		Scan(&days).Error
+	return days, err
 }
 
-// อัปเดตสถานะคำขอลา
-func (s *LeaveService) UpdateStatus(id string, status string) error {
-	return s.db.Model(&entity.LeaveRequest{}).Where("leave_id = ?", id).Update("status", status).Error
+// Create สร้างคำขอลาใหม่ของ empID ตรวจช่วงวันที่ การลาซ้อน และวันลาคงเหลือ (รวมที่รออนุมัติ)
+func (s *LeaveService) Create(empID uint, in LeaveInput) (*entity.LeaveRequest, error) {
+	leaveType, err := normalizeLeaveType(in.Type)
+	if err != nil {
+		return nil, err
+	}
+	start, err := time.Parse("2006-01-02", in.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse("2006-01-02", in.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, ErrInvalidLeaveRange
+	}
+	if end.Year() != start.Year() {
+		return nil, ErrLeaveCrossesYear
+	}
+	days := int(end.Sub(start).Hours()/24) + 1
+
+	leaveID, err := newLeaveID(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	leave := entity.LeaveRequest{
+		LeaveID:    leaveID,
+		StartDate:  start,
+		EndDate:    end,
+		Days:       days,
+		Type:       leaveType,
+		Reason:     strings.TrimSpace(in.Reason),
+		Status:     entity.LeaveStatusPending,
+		EmployeeID: empID,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var emp entity.Employee
+		if err := tx.First(&emp, empID).Error; err != nil {
+			return err
+		}
+
+		var overlaps int64
+		if err := tx.Model(&entity.LeaveRequest{}).
+			Where("employee_id = ? AND status IN ? AND start_date <= ? AND end_date >= ?",
+				empID, []string{entity.LeaveStatusPending, entity.LeaveStatusApproved}, end, start).
+			Count(&overlaps).Error; err != nil {
+			return err
+		}
+		if overlaps > 0 {
+			return ErrLeaveOverlap
+		}
+
+		b, err := s.balance(tx, empID, start.Year(), leaveType)
+		if err != nil {
+			return err
+		}
+		pending, err := pendingDays(tx, empID, start.Year(), leaveType)
+		if err != nil {
+			return err
+		}
+		if b.Entitled-b.Used-pending < days {
+			return ErrInsufficientLeaveBalance
+		}
+		return tx.Create(&leave).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+// Decide ผู้จัดการอนุมัติหรือปฏิเสธคำขอที่รออยู่ การอนุมัติจะหักวันลาคงเหลือ
+func (s *LeaveService) Decide(leaveID string, managerID uint, d LeaveDecision) (*entity.LeaveRequest, error) {
+	status := strings.ToLower(strings.TrimSpace(d.Status))
+	if status == "rejected" {
+		status = entity.LeaveStatusDenied
+	}
+	if status != entity.LeaveStatusApproved && status != entity.LeaveStatusDenied {
+		return nil, ErrInvalidLeaveStatus
+	}
+
+	var leave entity.LeaveRequest
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("leave_id = ?", leaveID).First(&leave).Error; err != nil {
+			return notFoundAs(err, ErrLeaveNotFound)
+		}
+		if leave.Status != entity.LeaveStatusPending {
+			return ErrLeaveAlreadyDecided
+		}
+
+		if status == entity.LeaveStatusApproved {
+			year := leave.StartDate.Year()
+			b, err := s.balance(tx, leave.EmployeeID, year, leave.Type)
+			if err != nil {
+				return err
+			}
+			if b.Entitled-b.Used < leave.Days {
+				return ErrInsufficientLeaveBalance
+			}
+			if err := tx.Model(b).Update("used", gorm.Expr("used + ?", leave.Days)).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		leave.Status = status
+		leave.DecidedByID = &managerID
+		leave.DecidedAt = &now
+		leave.ManagerComment = strings.TrimSpace(d.Comment)
+		if err := tx.Model(&leave).Updates(map[string]interface{}{
+			"status":          leave.Status,
+			"decided_by_id":   managerID,
+			"decided_at":      now,
+			"manager_comment": leave.ManagerComment,
+		}).Error; err != nil {
+			return err
+		}
+
+		event := entity.NotificationLeaveApproved
+		if status == entity.LeaveStatusDenied {
+			event = entity.NotificationLeaveDenied
+		}
+		return Notify(tx, NotificationInput{
+			Role:   entity.AuditRoleEmployee,
+			UserID: leave.EmployeeID,
+			Event:  event,
+			Data: map[string]interface{}{
+				"Type":      leave.Type,
+				"StartDate": leave.StartDate,
+				"EndDate":   leave.EndDate,
+				"Days":      leave.Days,
+				"Comment":   leave.ManagerComment,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+// Balances สิทธิ์วันลาทุกประเภทของปี พร้อมวันที่รออนุมัติและคงเหลือ
+func (s *LeaveService) Balances(empID uint, year int) ([]entity.LeaveBalance, error) {
+	var emp entity.Employee
+	if err := s.db.First(&emp, empID).Error; err != nil {
+		return nil, err
+	}
+	balances := make([]entity.LeaveBalance, 0, len(leaveTypeOrder))
+	for _, t := range leaveTypeOrder {
+		b, err := s.balance(s.db, empID, year, t)
+		if err != nil {
+			return nil, err
+		}
+		if b.Pending, err = pendingDays(s.db, empID, year, t); err != nil {
+			return nil, err
+		}
+		b.Remaining = b.Entitled - b.Used - b.Pending
+		balances = append(balances, *b)
+	}
+	return balances, nil
+}
+
+// SetEntitlement ผู้จัดการกำหนดสิทธิ์วันลาของปี (ต้องไม่น้อยกว่าที่ใช้ไปแล้ว)
+func (s *LeaveService) SetEntitlement(empID uint, year int, leaveType string, entitled int) (*entity.LeaveBalance, error) {
+	leaveType, err := normalizeLeaveType(leaveType)
+	if err != nil {
+		return nil, err
+	}
+	var emp entity.Employee
+	if err := s.db.First(&emp, empID).Error; err != nil {
+		return nil, err
+	}
+	var b *entity.LeaveBalance
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if b, err = s.balance(tx, empID, year, leaveType); err != nil {
+			return err
+		}
+		if entitled < 0 || entitled < b.Used {
+			return ErrInvalidLeaveEntitlement
+		}
+		b.Entitled = entitled
+		return tx.Model(b).Update("entitled", entitled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b.Pending, err = pendingDays(s.db, empID, year, leaveType); err != nil {
+		return nil, err
+	}
+	b.Remaining = b.Entitled - b.Used - b.Pending
+	return b, nil
 }
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newLeaveTestService(t *testing.T) (*LeaveService, uint) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&entity.Employee{}, &entity.LeaveRequest{}, &entity.LeaveBalance{},
+		&entity.Notification{}, &entity.NotificationDelivery{}, &entity.NotificationPreference{}); err != nil {
+		t.Fatal(err)
+	}
+	emp := entity.Employee{FirstName: "สมชาย", LastName: "ใจดี", Email: "somchai@example.com"}
+	if err := db.Create(&emp).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewLeaveService(db), emp.EmployeeID
+}
+
+func TestLeaveDays(t *testing.T) {
+	svc, empID := newLeaveTestService(t)
+	cases := []struct {
+		start, end string
+		days       int
+		wantErr    error
+	}{
+		{"2026-03-02", "2026-03-02", 1, nil},
+		{"2026-03-04", "2026-03-05", 2, nil},
+		{"2026-03-30", "2026-04-01", 3, nil}, // ข้ามเดือน
+		{"2028-02-28", "2028-03-01", 3, nil}, // ปีอธิกสุรทิน
+		{"2026-10-24", "2026-10-25", 2, nil}, // นับเสาร์อาทิตย์ด้วย
+		{"2026-05-10", "2026-05-09", 0, ErrInvalidLeaveRange},
+		{"2026-12-31", "2027-01-01", 0, ErrLeaveCrossesYear},
+		{"2026-03-02", "2026-03-02", 0, ErrLeaveOverlap}, // ทับคำขอแรก
+		{"2026-03-01", "2026-03-03", 0, ErrLeaveOverlap},
+	}
+	for _, tc := range cases {
+		leave, err := svc.Create(empID, LeaveInput{StartDate: tc.start, EndDate: tc.end, Type: "sick"})
+		if tc.wantErr != nil {
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("%s..%s: got %v, want %v", tc.start, tc.end, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s..%s: %v", tc.start, tc.end, err)
+			continue
+		}
+		if leave.Days != tc.days || leave.Status != entity.LeaveStatusPending {
+			t.Errorf("%s..%s: days=%d status=%s, want %d pending", tc.start, tc.end, leave.Days, leave.Status, tc.days)
+		}
+	}
+
+	var parseErr *time.ParseError
+	if _, err := svc.Create(empID, LeaveInput{StartDate: "02/03/2026", EndDate: "2026-03-02", Type: "sick"}); !errors.As(err, &parseErr) {
+		t.Errorf("bad date format: got %v, want *time.ParseError", err)
+	}
+	if _, err := svc.Create(empID, LeaveInput{StartDate: "2026-06-01", EndDate: "2026-06-01", Type: "maternity"}); !errors.Is(err, ErrInvalidLeaveType) {
+		t.Errorf("unknown type: got %v, want ErrInvalidLeaveType", err)
+	}
+}
+
+func TestLeaveBalanceArithmetic(t *testing.T) {
+	svc, empID := newLeaveTestService(t)
+
+	// สิทธิ์เริ่มต้นของปีที่ยังไม่มีข้อมูล
+	balances, err := svc.Balances(empID, 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDefault := map[string]int{entity.LeaveTypeSick: 30, entity.LeaveTypePersonal: 3, entity.LeaveTypeVacation: 6}
+	if len(balances) != len(wantDefault) {
+		t.Fatalf("got %d balances, want %d", len(balances), len(wantDefault))
+	}
+	for _, b := range balances {
+		if b.Entitled != wantDefault[b.Type] || b.Remaining != wantDefault[b.Type] || b.Used != 0 || b.Pending != 0 {
+			t.Errorf("default %s: %+v", b.Type, b)
+		}
+	}
+
+	ids := map[string]string{}
+	steps := []struct {
+		name    string
+		run     func() error
+		wantErr error
+		// ยอดวันลาพักร้อนหลังทำขั้นนี้
+		entitled, used, pending, remaining int
+	}{
+		{"ขอ 3 วัน", createLeaveStep(svc, empID, ids, "a", entity.LeaveTypeVacation, "2026-07-01", "2026-07-03"), nil, 6, 0, 3, 3},
+		{"ขอเกินคงเหลือ (รวมที่รออนุมัติ)", createLeaveStep(svc, empID, ids, "x", entity.LeaveTypeVacation, "2026-08-03", "2026-08-06"), ErrInsufficientLeaveBalance, 6, 0, 3, 3},
+		{"ขออีก 3 วันด้วยชื่อภาษาไทย", createLeaveStep(svc, empID, ids, "b", "ลาพักร้อน", "2026-08-03", "2026-08-05"), nil, 6, 0, 6, 0},
+		{"อนุมัติคำขอแรก", decideLeaveStep(svc, ids, "a", "approved"), nil, 6, 3, 3, 0},
+		{"ปฏิเสธคำขอที่สอง", decideLeaveStep(svc, ids, "b", "rejected"), nil, 6, 3, 0, 3},
+		{"พิจารณาซ้ำ", decideLeaveStep(svc, ids, "a", "denied"), ErrLeaveAlreadyDecided, 6, 3, 0, 3},
+		{"ลดสิทธิ์ต่ำกว่าที่ใช้ไป", setEntitlementStep(svc, empID, 2), ErrInvalidLeaveEntitlement, 6, 3, 0, 3},
+		{"สิทธิ์ติดลบ", setEntitlementStep(svc, empID, -1), ErrInvalidLeaveEntitlement, 6, 3, 0, 3},
+		{"เพิ่มสิทธิ์", setEntitlementStep(svc, empID, 10), nil, 10, 3, 0, 7},
+		{"ขอ 7 วันพอดีคงเหลือ", createLeaveStep(svc, empID, ids, "c", entity.LeaveTypeVacation, "2026-09-07", "2026-09-13"), nil, 10, 3, 7, 0},
+	}
+	for _, st := range steps {
+		if err := st.run(); !errors.Is(err, st.wantErr) {
+			t.Fatalf("%s: got %v, want %v", st.name, err, st.wantErr)
+		}
+		balances, err := svc.Balances(empID, 2026)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range balances {
+			if b.Type != entity.LeaveTypeVacation {
+				continue
+			}
+			if b.Entitled != st.entitled || b.Used != st.used || b.Pending != st.pending || b.Remaining != st.remaining {
+				t.Errorf("%s: entitled=%d used=%d pending=%d remaining=%d, want %d/%d/%d/%d", st.name,
+					b.Entitled, b.Used, b.Pending, b.Remaining, st.entitled, st.used, st.pending, st.remaining)
+			}
+		}
+	}
+
+	// ปีอื่นไม่ได้รับผลกระทบ
+	next, err := svc.Balances(empID, 2027)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range next {
+		if b.Type == entity.LeaveTypeVacation && (b.Entitled != 6 || b.Remaining != 6) {
+			t.Errorf("2027 vacation: %+v", b)
+		}
+	}
+}
+
+// ขั้นตอนของ TestLeaveBalanceArithmetic เก็บ LeaveID ไว้ใน ids[key] ให้ขั้นถัดไปอ้างถึง
+func createLeaveStep(svc *LeaveService, empID uint, ids map[string]string, key, leaveType, start, end string) func() error {
+	return func() error {
+		leave, err := svc.Create(empID, LeaveInput{StartDate: start, EndDate: end, Type: leaveType})
+		if err == nil {
+			ids[key] = leave.LeaveID
+		}
+		return err
+	}
+}
+
+func decideLeaveStep(svc *LeaveService, ids map[string]string, key, status string) func() error {
+	return func() error {
+		_, err := svc.Decide(ids[key], 1, LeaveDecision{Status: status})
+		return err
+	}
+}
+
+func setEntitlementStep(svc *LeaveService, empID uint, entitled int) func() error {
+	return func() error {
+		_, err := svc.SetEntitlement(empID, 2026, entity.LeaveTypeVacation, entitled)
+		return err
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+// ตัวกรองของรายการต่างๆ ใช้ร่วมกันระหว่าง list endpoint และ export
+
+var ErrInvalidDateFilter = errors.New("date_from and date_to must be YYYY-MM-DD")
+
+// applyDateRange กรองคอลัมน์วันที่ระหว่าง from ถึง to (รวมทั้งวัน to)
+func applyDateRange(q *gorm.DB, column, from, to string) (*gorm.DB, error) {
+	if from != "" {
+		d, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return nil, ErrInvalidDateFilter
+		}
+		q = q.Where(column+" >= ?", d)
+	}
+	if to != "" {
+		d, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return nil, ErrInvalidDateFilter
+		}
+		q = q.Where(column+" < ?", d.AddDate(0, 0, 1))
+	}
+	return q, nil
+}
+
+// SalesContractFilter ?employee_id=&customer_id=&date_from=&date_to= (วันที่ทำสัญญา)
+type SalesContractFilter struct {
+	EmployeeID uint   `form:"employee_id"`
+	CustomerID uint   `form:"customer_id"`
+	DateFrom   string `form:"date_from"`
+	DateTo     string `form:"date_to"`
+}
+
+func (f SalesContractFilter) Apply(db *gorm.DB) (*gorm.DB, error) {
+	q := db.Model(&entity.SalesContract{})
+	if f.EmployeeID != 0 {
+		q = q.Where("sales_contracts.employee_id = ?", f.EmployeeID)
+	}
+	if f.CustomerID != 0 {
+		q = q.Where("sales_contracts.customer_id = ?", f.CustomerID)
+	}
+	return applyDateRange(q, "sales_contracts.created_at", f.DateFrom, f.DateTo)
+}
+
+// RentContractFilter ?customer_id=&car_id=&date_from=&date_to= (สัญญาที่ช่วงเช่าคาบเกี่ยวกับช่วงวันที่)
+type RentContractFilter struct {
+	CustomerID uint   `form:"customer_id"`
+	CarID      uint   `form:"car_id"`
+	DateFrom   string `form:"date_from"`
+	DateTo     string `form:"date_to"`
+}
+
+func (f RentContractFilter) Apply(db *gorm.DB) (*gorm.DB, error) {
+	q := db.Model(&entity.RentContract{})
+	if f.CustomerID != 0 {
+		q = q.Where("rent_contracts.customer_id = ?", f.CustomerID)
+	}
+	if f.CarID != 0 {
+		q = q.Where("rent_contracts.rent_list_id IN (SELECT id FROM rent_lists WHERE car_id = ?)", f.CarID)
+	}
+	// ช่วงเช่าคาบเกี่ยว: เริ่มก่อนสิ้นวัน to และสิ้นสุดหลัง from
+	if f.DateFrom != "" {
+		d, err := time.Parse("2006-01-02", f.DateFrom)
+		if err != nil {
+			return nil, ErrInvalidDateFilter
+		}
+		q = q.Where("rent_contracts.date_end >= ?", d)
+	}
+	if f.DateTo != "" {
+		d, err := time.Parse("2006-01-02", f.DateTo)
+		if err != nil {
+			return nil, ErrInvalidDateFilter
+		}
+		q = q.Where("rent_contracts.date_start < ?", d.AddDate(0, 0, 1))
+	}
+	return q, nil
+}
+
+// PaymentStatusPaid สถานะของการชำระที่เสร็จแล้ว (ตามข้อมูลตั้งต้น)
+const PaymentStatusPaid = "ชำระแล้ว"
+
+// Payment.Amount เก็บเป็นข้อความ แปลงเป็นตัวเลขใน SQL
+const paymentAmountExpr = "CAST(REPLACE(payments.amount, ',', '') AS REAL)"
+
+// PaymentFilter ?status=&customer_id=&employee_id=&contract=sale|rent&date_from=&date_to= (วันที่ชำระ)
+type PaymentFilter struct {
+	Status     string `form:"status"`
+	CustomerID uint   `form:"customer_id"`
+	EmployeeID uint   `form:"employee_id"`
+	Contract   string `form:"contract"`
+	DateFrom   string `form:"date_from"`
+	DateTo     string `form:"date_to"`
+}
+
+func (f PaymentFilter) Apply(db *gorm.DB) (*gorm.DB, error) {
+	q := db.Model(&entity.Payment{})
+	if f.Status != "" {
+		q = q.Where("LOWER(payments.status) = LOWER(?)", f.Status)
+	}
+	if f.CustomerID != 0 {
+		q = q.Where("payments.customer_id = ?", f.CustomerID)
+	}
+	if f.EmployeeID != 0 {
+		q = q.Where("payments.employee_id = ?", f.EmployeeID)
+	}
+	switch strings.ToLower(f.Contract) {
+	case "sale":
+		q = q.Where("payments.sales_contract_id <> 0")
+	case "rent":
+		q = q.Where("payments.rent_contract_id <> 0")
+	}
+	return applyDateRange(q, "payments.payment_date", f.DateFrom, f.DateTo)
+}
+
+// EmployeeFilter ?q=&position=&job_type=
+type EmployeeFilter struct {
+	Q        string `form:"q"` // ชื่อ นามสกุล หรืออีเมล
+	Position string `form:"position"`
+	JobType  string `form:"job_type"`
+}
+
+func (f EmployeeFilter) Apply(db *gorm.DB) *gorm.DB {
+	q := db.Model(&entity.Employee{})
+	if s := strings.TrimSpace(f.Q); s != "" {
+		like := "%" + strings.ToLower(s) + "%"
+		q = q.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(email) LIKE ?", like, like, like)
+	}
+	if f.Position != "" {
+		q = q.Where("LOWER(position) = LOWER(?)", f.Position)
+	}
+	if f.JobType != "" {
+		q = q.Where("LOWER(job_type) = LOWER(?)", f.JobType)
+	}
+	return q
+}
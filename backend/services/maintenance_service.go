@@ -0,0 +1,636 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMaintenanceNotFound     = errors.New("maintenance record not found")
+	ErrMaintenanceCarNotFound  = errors.New("car not found")
+	ErrInvalidMaintenance      = errors.New("invalid maintenance record")
+	ErrMaintenanceState        = errors.New("maintenance record is not in a state that allows this action")
+	ErrMaintenanceConflict     = errors.New("car is rented during the requested maintenance period")
+	ErrCarInMaintenance        = errors.New("car is blocked for maintenance")
+	ErrServiceReminderNotFound = errors.New("service reminder not found")
+	ErrInvalidServiceReminder  = errors.New("invalid service reminder")
+)
+
+// สถานะการครบรอบบริการ
+const (
+	ReminderOK      = "ok"
+	ReminderDueSoon = "due_soon"
+	ReminderOverdue = "overdue"
+)
+
+const (
+	// เตือนล่วงหน้า 30 วัน หรือ 1,000 กม. ก่อนถึงรอบ
+	reminderLeadDays = 30
+	reminderLeadKm   = 1000
+)
+
+var activeMaintenanceStatuses = []string{entity.MaintenancePlanned, entity.MaintenanceInProgress}
+
+var serviceTypes = map[string]bool{
+	entity.ServiceOilChange:  true,
+	entity.ServiceTires:      true,
+	entity.ServiceBrakes:     true,
+	entity.ServiceBattery:    true,
+	entity.ServiceInspection: true,
+	entity.ServiceRepair:     true,
+	entity.ServiceOther:      true,
+}
+
+// รถที่ติดซ่อมอยู่วันนี้ (ใช้ตัดออกจากรายการขาย/เช่า) args: วันนี้ 00:00 สองครั้ง
+const carInMaintenanceExpr = "EXISTS (SELECT 1 FROM maintenance_records WHERE maintenance_records.car_id = cars.id AND maintenance_records.deleted_at IS NULL AND " +
+	"(maintenance_records.status = 'in_progress' OR (maintenance_records.status = 'planned' AND maintenance_records.planned_start <= ? AND maintenance_records.planned_end >= ?)))"
+
+// MaintenanceInput ข้อมูลงานซ่อมบำรุงที่ผู้จัดการวางแผน
+type MaintenanceInput struct {
+	ServiceType  string  `json:"service_type" binding:"required"`
+	Description  string  `json:"description"`
+	Garage       string  `json:"garage"`
+	PlannedStart string  `json:"planned_start" binding:"required"` // YYYY-MM-DD
+	PlannedEnd   string  `json:"planned_end"`                      // YYYY-MM-DD (ไม่ระบุ = วันเดียว)
+	Odometer     int     `json:"odometer"`
+	Cost         float64 `json:"cost"` // ประมาณการ แก้ได้ตอนปิดงาน
+	Note         string  `json:"note"`
+}
+
+// MaintenanceCompleteInput ผลการซ่อมจริงตอนปิดงาน
+type MaintenanceCompleteInput struct {
+	Cost        *float64 `json:"cost"`
+	Odometer    *int     `json:"odometer"`
+	CompletedAt string   `json:"completed_at"` // YYYY-MM-DD (ไม่ระบุ = วันนี้)
+	Note        string   `json:"note"`
+}
+
+// MaintenanceFilter ?car_id=&status=&service_type=&date_from=&date_to= (กรองตามวันเริ่มที่วางแผน)
+type MaintenanceFilter struct {
+	CarID       uint   `form:"car_id"`
+	Status      string `form:"status"`
+	ServiceType string `form:"service_type"`
+	DateFrom    string `form:"date_from"`
+	DateTo      string `form:"date_to"`
+}
+
+// ServiceReminderInput รอบบริการ ต้องมีอย่างน้อยหนึ่งเกณฑ์ (ระยะทางหรือเวลา)
+type ServiceReminderInput struct {
+	ServiceType    string `json:"service_type" binding:"required"`
+	IntervalKm     int    `json:"interval_km"`
+	IntervalMonths int    `json:"interval_months"`
+	LastServiceAt  string `json:"last_service_at"` // YYYY-MM-DD (ไม่ระบุ = วันนี้)
+	LastOdometer   *int   `json:"last_odometer"`   // ไม่ระบุ = เลขไมล์ปัจจุบันของรถ
+	Active         *bool  `json:"active"`
+	Note           string `json:"note"`
+}
+
+// ServiceReminderStatus รอบบริการพร้อมกำหนดครั้งถัดไป
+type ServiceReminderStatus struct {
+	entity.ServiceReminder
+	CarName        string     `json:"car_name"`
+	CurrentMileage int        `json:"current_mileage"`
+	NextDueAt      *time.Time `json:"next_due_at"`
+	NextDueKm      *int       `json:"next_due_km"`
+	DaysLeft       *int       `json:"days_left"`
+	KmLeft         *int       `json:"km_left"`
+	State          string     `json:"state"`
+}
+
+// MaintenanceService งานซ่อมบำรุง การกันรถระหว่างซ่อม และรอบบริการ
+type MaintenanceService struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceService(db *gorm.DB) *MaintenanceService {
+	return &MaintenanceService{db: db}
+}
+
+func (s *MaintenanceService) WithContext(ctx context.Context) *MaintenanceService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidMaintenance(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidMaintenance, reason)
+}
+
+func invalidServiceReminder(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidServiceReminder, reason)
+}
+
+func localToday() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// MaintenanceBlock งานซ่อมที่กันรถไว้ในช่วง [start, end) (nil = ว่าง)
+// งานที่กำลังซ่อมกันรถต่อไปจนกว่าจะปิดงาน แม้เลยวันที่วางแผนไว้
+func MaintenanceBlock(tx *gorm.DB, carID uint, start, end time.Time) (*entity.MaintenanceRecord, error) {
+	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	var records []entity.MaintenanceRecord
+	if err := tx.Where("car_id = ? AND status IN ? AND planned_start < ? AND (planned_end >= ? OR status = ?)",
+		carID, activeMaintenanceStatuses, end, dayStart, entity.MaintenanceInProgress).
+		Order("planned_start ASC").Limit(1).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
+}
+
+// CarInMaintenance รถติดซ่อมอยู่วันนี้หรือไม่
+func CarInMaintenance(tx *gorm.DB, carID uint) (bool, error) {
+	today := localToday()
+	block, err := MaintenanceBlock(tx, carID, today, today.AddDate(0, 0, 1))
+	return block != nil, err
+}
+
+func (s *MaintenanceService) findCar(tx *gorm.DB, carID uint) (*entity.Car, error) {
+	var car entity.Car
+	if err := tx.First(&car, carID).Error; err != nil {
+		return nil, notFoundAs(err, ErrMaintenanceCarNotFound)
+	}
+	return &car, nil
+}
+
+func normalizeServiceType(v string) (string, bool) {
+	t := strings.ToLower(strings.TrimSpace(v))
+	return t, serviceTypes[t]
+}
+
+func applyMaintenanceInput(rec *entity.MaintenanceRecord, in MaintenanceInput) error {
+	serviceType, ok := normalizeServiceType(in.ServiceType)
+	if !ok {
+		return invalidMaintenance("service_type must be oil_change, tires, brakes, battery, inspection, repair or other")
+	}
+	start, err := parseLocalDay(in.PlannedStart)
+	if err != nil {
+		return invalidMaintenance("planned_start must be YYYY-MM-DD")
+	}
+	end := start
+	if in.PlannedEnd != "" {
+		if end, err = parseLocalDay(in.PlannedEnd); err != nil {
+			return invalidMaintenance("planned_end must be YYYY-MM-DD")
+		}
+	}
+	if end.Before(start) {
+		return invalidMaintenance("planned_end must not be before planned_start")
+	}
+	if in.Odometer < 0 || in.Cost < 0 {
+		return invalidMaintenance("odometer and cost must not be negative")
+	}
+	rec.ServiceType = serviceType
+	rec.Description = strings.TrimSpace(in.Description)
+	rec.Garage = strings.TrimSpace(in.Garage)
+	rec.PlannedStart = start
+	rec.PlannedEnd = end
+	rec.Odometer = in.Odometer
+	rec.Cost = roundBaht(in.Cost)
+	rec.Note = strings.TrimSpace(in.Note)
+	return nil
+}
+
+// rentalOverlap ตรวจว่ามีสัญญาเช่าทับช่วงที่จะเอารถเข้าอู่
+func rentalOverlap(tx *gorm.DB, carID uint, start, end time.Time) error {
+	var n int64
+	if err := tx.Model(&entity.RentContract{}).
+		Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ? AND rent_contracts.date_start < ? AND rent_contracts.date_end >= ?",
+			carID, end.AddDate(0, 0, 1), start).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrMaintenanceConflict
+	}
+	return nil
+}
+
+// List งานซ่อมบำรุงตามตัวกรอง ล่าสุดก่อน
+func (s *MaintenanceService) List(f MaintenanceFilter) ([]entity.MaintenanceRecord, error) {
+	q := s.db.Model(&entity.MaintenanceRecord{})
+	if f.CarID != 0 {
+		q = q.Where("car_id = ?", f.CarID)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.ServiceType != "" {
+		q = q.Where("service_type = ?", strings.ToLower(f.ServiceType))
+	}
+	q, err := applyDateRange(q, "planned_start", f.DateFrom, f.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	var records []entity.MaintenanceRecord
+	err = q.Order("planned_start DESC, id DESC").Find(&records).Error
+	return records, err
+}
+
+// ListForCar ประวัติการซ่อมบำรุงของรถหนึ่งคัน
+func (s *MaintenanceService) ListForCar(carID uint) ([]entity.MaintenanceRecord, error) {
+	if _, err := s.findCar(s.db, carID); err != nil {
+		return nil, err
+	}
+	return s.List(MaintenanceFilter{CarID: carID})
+}
+
+func (s *MaintenanceService) Get(id uint) (*entity.MaintenanceRecord, error) {
+	var rec entity.MaintenanceRecord
+	if err := s.db.First(&rec, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrMaintenanceNotFound)
+	}
+	return &rec, nil
+}
+
+// Create วางแผนงานซ่อม รถจะถูกกันตั้งแต่วันเริ่มถึงวันสิ้นสุด
+func (s *MaintenanceService) Create(carID, managerID uint, in MaintenanceInput) (*entity.MaintenanceRecord, error) {
+	rec := entity.MaintenanceRecord{CarID: carID, ManagerID: managerID, Status: entity.MaintenancePlanned}
+	if err := applyMaintenanceInput(&rec, in); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.findCar(tx, carID); err != nil {
+			return err
+		}
+		if err := rentalOverlap(tx, carID, rec.PlannedStart, rec.PlannedEnd); err != nil {
+			return err
+		}
+		return tx.Create(&rec).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Update แก้แผนงานซ่อมที่ยังไม่ปิด
+func (s *MaintenanceService) Update(id uint, in MaintenanceInput) (*entity.MaintenanceRecord, error) {
+	var rec entity.MaintenanceRecord
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&rec, id).Error; err != nil {
+			return notFoundAs(err, ErrMaintenanceNotFound)
+		}
+		if !containsString(activeMaintenanceStatuses, rec.Status) {
+			return ErrMaintenanceState
+		}
+		if err := applyMaintenanceInput(&rec, in); err != nil {
+			return err
+		}
+		if err := rentalOverlap(tx, rec.CarID, rec.PlannedStart, rec.PlannedEnd); err != nil {
+			return err
+		}
+		return tx.Save(&rec).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Start รถเข้าอู่แล้ว (ห้ามเริ่มถ้ารถยังอยู่กับผู้เช่า)
+func (s *MaintenanceService) Start(id uint) (*entity.MaintenanceRecord, error) {
+	var rec entity.MaintenanceRecord
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&rec, id).Error; err != nil {
+			return notFoundAs(err, ErrMaintenanceNotFound)
+		}
+		if rec.Status != entity.MaintenancePlanned {
+			return ErrMaintenanceState
+		}
+		today := localToday()
+		if err := rentalOverlap(tx, rec.CarID, today, today); err != nil {
+			return err
+		}
+		// เข้าอู่ก่อนกำหนด ให้เริ่มกันรถตั้งแต่วันนี้
+		if today.Before(rec.PlannedStart) {
+			rec.PlannedStart = today
+		}
+		if err := TransitionCar(tx, rec.CarID, entity.CarInMaintenance, fmt.Sprintf("maintenance %d started", rec.ID), nil); err != nil {
+			return err
+		}
+		now := time.Now()
+		rec.Status = entity.MaintenanceInProgress
+		rec.StartedAt = &now
+		return tx.Save(&rec).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Complete ปิดงานซ่อม: บันทึกค่าใช้จ่ายของรถ อัปเดตเลขไมล์ และเริ่มรอบบริการใหม่ของประเภทเดียวกัน
+func (s *MaintenanceService) Complete(id, managerID uint, in MaintenanceCompleteInput) (*entity.MaintenanceRecord, error) {
+	completedAt := localToday()
+	if in.CompletedAt != "" {
+		d, err := parseLocalDay(in.CompletedAt)
+		if err != nil {
+			return nil, invalidMaintenance("completed_at must be YYYY-MM-DD")
+		}
+		completedAt = d
+	}
+	if (in.Cost != nil && *in.Cost < 0) || (in.Odometer != nil && *in.Odometer < 0) {
+		return nil, invalidMaintenance("odometer and cost must not be negative")
+	}
+
+	var rec entity.MaintenanceRecord
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&rec, id).Error; err != nil {
+			return notFoundAs(err, ErrMaintenanceNotFound)
+		}
+		if rec.Status != entity.MaintenanceInProgress {
+			return ErrMaintenanceState
+		}
+		if rec.StartedAt != nil && completedAt.Before(localDayOf(*rec.StartedAt)) {
+			return invalidMaintenance("completed_at must not be before the start of the work")
+		}
+		car, err := s.findCar(tx, rec.CarID)
+		if err != nil {
+			return err
+		}
+
+		if in.Cost != nil {
+			rec.Cost = roundBaht(*in.Cost)
+		}
+		if in.Odometer != nil {
+			rec.Odometer = *in.Odometer
+		}
+		if note := strings.TrimSpace(in.Note); note != "" {
+			rec.Note = note
+		}
+		rec.Status = entity.MaintenanceCompleted
+		rec.CompletedAt = &completedAt
+
+		if rec.Cost > 0 {
+			expense := entity.CarExpense{
+				CarID:       rec.CarID,
+				ManagerID:   managerID,
+				Category:    entity.ExpenseMaintenance,
+				Amount:      rec.Cost,
+				ExpenseDate: completedAt,
+				Vendor:      rec.Garage,
+				Description: fmt.Sprintf("maintenance #%d: %s", rec.ID, rec.ServiceType),
+			}
+			if err := tx.Create(&expense).Error; err != nil {
+				return err
+			}
+			rec.CarExpenseID = &expense.ID
+		}
+		if err := tx.Save(&rec).Error; err != nil {
+			return err
+		}
+
+		if err := ResumeCarAfterMaintenance(tx, rec.CarID, fmt.Sprintf("maintenance %d completed", rec.ID)); err != nil {
+			return err
+		}
+
+		odometer := car.Mileage
+		if rec.Odometer > car.Mileage {
+			odometer = rec.Odometer
+			if err := tx.Model(car).Update("mileage", odometer).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&entity.ServiceReminder{}).
+			Where("car_id = ? AND service_type = ? AND active = ?", rec.CarID, rec.ServiceType, true).
+			Updates(map[string]interface{}{"last_service_at": completedAt, "last_odometer": odometer}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Cancel ยกเลิกงานที่ยังไม่ปิด รถกลับมาเปิดขาย/เช่าได้ทันที
+func (s *MaintenanceService) Cancel(id uint, reason string) (*entity.MaintenanceRecord, error) {
+	var rec entity.MaintenanceRecord
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&rec, id).Error; err != nil {
+			return notFoundAs(err, ErrMaintenanceNotFound)
+		}
+		if !containsString(activeMaintenanceStatuses, rec.Status) {
+			return ErrMaintenanceState
+		}
+		if rec.Status == entity.MaintenanceInProgress {
+			if err := ResumeCarAfterMaintenance(tx, rec.CarID, fmt.Sprintf("maintenance %d cancelled", rec.ID)); err != nil {
+				return err
+			}
+		}
+		rec.Status = entity.MaintenanceCancelled
+		if reason = strings.TrimSpace(reason); reason != "" {
+			rec.Note = reason
+		}
+		return tx.Save(&rec).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func localDayOf(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func (s *MaintenanceService) applyReminderInput(car *entity.Car, r *entity.ServiceReminder, in ServiceReminderInput) error {
+	serviceType, ok := normalizeServiceType(in.ServiceType)
+	if !ok {
+		return invalidServiceReminder("service_type must be oil_change, tires, brakes, battery, inspection, repair or other")
+	}
+	if in.IntervalKm < 0 || in.IntervalMonths < 0 {
+		return invalidServiceReminder("intervals must not be negative")
+	}
+	if in.IntervalKm == 0 && in.IntervalMonths == 0 {
+		return invalidServiceReminder("interval_km or interval_months is required")
+	}
+	last := localToday()
+	if in.LastServiceAt != "" {
+		d, err := parseLocalDay(in.LastServiceAt)
+		if err != nil {
+			return invalidServiceReminder("last_service_at must be YYYY-MM-DD")
+		}
+		last = d
+	}
+	odometer := car.Mileage
+	if in.LastOdometer != nil {
+		if *in.LastOdometer < 0 {
+			return invalidServiceReminder("last_odometer must not be negative")
+		}
+		odometer = *in.LastOdometer
+	}
+	r.ServiceType = serviceType
+	r.IntervalKm = in.IntervalKm
+	r.IntervalMonths = in.IntervalMonths
+	r.LastServiceAt = last
+	r.LastOdometer = odometer
+	r.Active = in.Active == nil || *in.Active
+	r.Note = strings.TrimSpace(in.Note)
+	return nil
+}
+
+// รถหนึ่งคันมีรอบบริการของแต่ละประเภทได้รอบเดียว
+func reminderTypeFree(tx *gorm.DB, r entity.ServiceReminder) error {
+	var n int64
+	if err := tx.Model(&entity.ServiceReminder{}).
+		Where("car_id = ? AND service_type = ? AND id <> ?", r.CarID, r.ServiceType, r.ID).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return invalidServiceReminder("the car already has a reminder for " + r.ServiceType)
+	}
+	return nil
+}
+
+// CarReminders รอบบริการทั้งหมดของรถพร้อมสถานะ
+func (s *MaintenanceService) CarReminders(carID uint) ([]ServiceReminderStatus, error) {
+	car, err := s.findCar(s.db, carID)
+	if err != nil {
+		return nil, err
+	}
+	var reminders []entity.ServiceReminder
+	if err := s.db.Where("car_id = ?", carID).Order("service_type ASC, id ASC").Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	out := make([]ServiceReminderStatus, 0, len(reminders))
+	for _, r := range reminders {
+		out = append(out, reminderStatus(r, car))
+	}
+	return out, nil
+}
+
+// CreateReminder ตั้งรอบบริการให้รถ (ประเภทเดียวกันมีได้รอบเดียว)
+func (s *MaintenanceService) CreateReminder(carID uint, in ServiceReminderInput) (*ServiceReminderStatus, error) {
+	var status ServiceReminderStatus
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		car, err := s.findCar(tx, carID)
+		if err != nil {
+			return err
+		}
+		r := entity.ServiceReminder{CarID: carID}
+		if err := s.applyReminderInput(car, &r, in); err != nil {
+			return err
+		}
+		if err := reminderTypeFree(tx, r); err != nil {
+			return err
+		}
+		if err := tx.Create(&r).Error; err != nil {
+			return err
+		}
+		status = reminderStatus(r, car)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// UpdateReminder แก้รอบบริการ
+func (s *MaintenanceService) UpdateReminder(id uint, in ServiceReminderInput) (*ServiceReminderStatus, error) {
+	var status ServiceReminderStatus
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var r entity.ServiceReminder
+		if err := tx.First(&r, id).Error; err != nil {
+			return notFoundAs(err, ErrServiceReminderNotFound)
+		}
+		car, err := s.findCar(tx, r.CarID)
+		if err != nil {
+			return err
+		}
+		if err := s.applyReminderInput(car, &r, in); err != nil {
+			return err
+		}
+		if err := reminderTypeFree(tx, r); err != nil {
+			return err
+		}
+		if err := tx.Save(&r).Error; err != nil {
+			return err
+		}
+		status = reminderStatus(r, car)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (s *MaintenanceService) DeleteReminder(id uint) error {
+	res := s.db.Delete(&entity.ServiceReminder{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrServiceReminderNotFound
+	}
+	return nil
+}
+
+// DueReminders รอบบริการที่ใกล้ถึงหรือเลยกำหนดของทุกคัน (เลยกำหนดก่อน)
+func (s *MaintenanceService) DueReminders() ([]ServiceReminderStatus, error) {
+	var reminders []entity.ServiceReminder
+	if err := s.db.Preload("Car").Where("active = ?", true).Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	out := []ServiceReminderStatus{}
+	for _, r := range reminders {
+		if r.Car == nil {
+			continue // รถถูกลบไปแล้ว
+		}
+		st := reminderStatus(r, r.Car)
+		if st.State != ReminderOK {
+			out = append(out, st)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].State != out[j].State {
+			return out[i].State == ReminderOverdue
+		}
+		return out[i].CarID < out[j].CarID
+	})
+	return out, nil
+}
+
+// reminderStatus คำนวณกำหนดครั้งถัดไป ถึงเกณฑ์ใดเกณฑ์หนึ่งก่อนถือว่าถึงรอบ
+func reminderStatus(r entity.ServiceReminder, car *entity.Car) ServiceReminderStatus {
+	r.Car = nil
+	st := ServiceReminderStatus{ServiceReminder: r, CarName: car.CarName, CurrentMileage: car.Mileage, State: ReminderOK}
+
+	due := func(overdue, soon bool) {
+		switch {
+		case overdue:
+			st.State = ReminderOverdue
+		case soon && st.State == ReminderOK:
+			st.State = ReminderDueSoon
+		}
+	}
+	if r.IntervalMonths > 0 {
+		next := localDayOf(r.LastServiceAt).AddDate(0, r.IntervalMonths, 0)
+		days := int(next.Sub(localToday()).Hours() / 24)
+		st.NextDueAt, st.DaysLeft = &next, &days
+		due(days < 0, days <= reminderLeadDays)
+	}
+	if r.IntervalKm > 0 {
+		next := r.LastOdometer + r.IntervalKm
+		left := next - car.Mileage
+		st.NextDueKm, st.KmLeft = &next, &left
+		due(left < 0, left <= reminderLeadKm)
+	}
+	if !r.Active {
+		st.State = ReminderOK
+	}
+	return st
+}
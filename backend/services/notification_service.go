@@ -0,0 +1,485 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/notify"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotificationNotFound         = errors.New("notification not found")
+	ErrNotificationDeliveryNotFound = errors.New("notification delivery not found")
+	ErrNotificationDeliverySent     = errors.New("notification has already been delivered")
+	ErrInvalidNotificationPref      = errors.New("invalid notification preference")
+	ErrInvalidNotificationFilter    = errors.New("invalid notification filter")
+	ErrUnknownNotificationEvent     = errors.New("unknown notification event")
+)
+
+const (
+	DefaultNotificationPageLimit = 20
+	MaxNotificationPageLimit     = 100
+
+	// ส่งไม่สำเร็จครบจำนวนนี้ย้ายไป dead-letter รอบถัดไปห่างขึ้นเท่าตัว: 1, 2, 4, 8 นาที
+	MaxNotificationAttempts = 5
+	notificationRetryBase   = time.Minute
+	notificationBatchSize   = 100
+	notificationSendTimeout = 30 * time.Second
+)
+
+// ข้อความของแต่ละเหตุการณ์ Data มาจาก NotificationInput (CarName เติมให้จาก CarID)
+var notificationTemplates = map[string]struct{ title, body string }{
+	entity.NotificationBookingConfirmed: {
+		"ยืนยันการจองรถเช่า",
+		"การจองรถ {{.CarName}} วันที่ {{.Dates}} ได้รับการยืนยันแล้ว",
+	},
+	entity.NotificationTestDriveConfirmed: {
+		"ยืนยันนัดทดลองขับ",
+		"นัดทดลองขับ {{.CarName}} วันที่ {{datetime .StartAt}} ได้รับการยืนยันแล้ว พนักงานที่ดูแล: {{.Employee}}",
+	},
+	entity.NotificationInspectionScheduled: {
+		"นัดตรวจสภาพรถ",
+		"นัดตรวจสภาพรถตามสัญญาเลขที่ {{.SalesContractID}} วันที่ {{datetime .DateTime}}",
+	},
+	entity.NotificationDeliveryAssigned: {
+		"งานรับ/ส่งรถใหม่",
+		"คุณได้รับมอบหมายงานรับ/ส่งรถตามสัญญาเลขที่ {{.SalesContractID}} ({{.Type}}) วันที่ {{datetime .DateTime}}{{if .Address}} ที่ {{.Address}}{{end}}",
+	},
+	entity.NotificationLeaveApproved: {
+		"อนุมัติการลา",
+		"คำขอ{{leaveType .Type}} {{date .StartDate}} - {{date .EndDate}} ({{.Days}} วัน) ได้รับการอนุมัติ{{if .Comment}} หมายเหตุ: {{.Comment}}{{end}}",
+	},
+	entity.NotificationLeaveDenied: {
+		"ไม่อนุมัติการลา",
+		"คำขอ{{leaveType .Type}} {{date .StartDate}} - {{date .EndDate}} ไม่ได้รับการอนุมัติ{{if .Comment}} เหตุผล: {{.Comment}}{{end}}",
+	},
+	entity.NotificationPriceDrop: {
+		"รถที่คุณติดตามลดราคา",
+		"{{.CarName}} ลดราคาจาก {{baht .OldPrice}} เหลือ {{baht .NewPrice}} บาท",
+	},
+	entity.NotificationNewListing: {
+		"มีรถใหม่ตรงกับที่คุณค้นหา",
+		"{{.CarName}} ประกาศขายราคา {{baht .NewPrice}} บาท",
+	},
+}
+
+var notificationFuncs = template.FuncMap{
+	"date":     func(t time.Time) string { return t.In(time.Local).Format("02/01/2006") },
+	"datetime": func(t time.Time) string { return t.In(time.Local).Format("02/01/2006 15:04") },
+	"baht":     formatBaht,
+	"leaveType": func(t string) string {
+		switch t {
+		case entity.LeaveTypeSick:
+			return "ลาป่วย"
+		case entity.LeaveTypePersonal:
+			return "ลากิจ"
+		case entity.LeaveTypeVacation:
+			return "ลาพักร้อน"
+		}
+		return "ลา"
+	},
+}
+
+var compiledNotificationTemplates = func() map[string][2]*template.Template {
+	compiled := make(map[string][2]*template.Template, len(notificationTemplates))
+	for event, t := range notificationTemplates {
+		compiled[event] = [2]*template.Template{
+			template.Must(template.New(event + ".title").Funcs(notificationFuncs).Parse(t.title)),
+			template.Must(template.New(event + ".body").Funcs(notificationFuncs).Parse(t.body)),
+		}
+	}
+	return compiled
+}()
+
+// formatBaht 1234567.5 -> "1,234,568"
+func formatBaht(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 0, 64)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// NotificationInput เหตุการณ์ที่จะแจ้งผู้ใช้หนึ่งคน
+type NotificationInput struct {
+	Role   string // entity.AuditRoleCustomer / AuditRoleEmployee / AuditRoleManager
+	UserID uint
+	Event  string
+	CarID  uint // ถ้าระบุจะเติม CarName ให้ template
+	Data   map[string]interface{}
+}
+
+// Notify สร้างข้อความในกล่องแจ้งเตือนและคิวส่งตามช่องทางที่ผู้ใช้เลือก
+// เรียกใน transaction เดียวกับการเปลี่ยนสถานะ เพื่อให้แจ้งเฉพาะเมื่อบันทึกสำเร็จ
+func Notify(tx *gorm.DB, in NotificationInput) error {
+	if in.UserID == 0 {
+		return nil
+	}
+	tpl, ok := compiledNotificationTemplates[in.Event]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNotificationEvent, in.Event)
+	}
+	data := map[string]interface{}{}
+	for k, v := range in.Data {
+		data[k] = v
+	}
+	if in.CarID != 0 {
+		names, err := carNames(tx, []uint{in.CarID})
+		if err != nil {
+			return err
+		}
+		data["CarName"] = names[in.CarID]
+	}
+	var title, body strings.Builder
+	if err := tpl[0].Execute(&title, data); err != nil {
+		return err
+	}
+	if err := tpl[1].Execute(&body, data); err != nil {
+		return err
+	}
+
+	n := entity.Notification{
+		RecipientRole: in.Role,
+		RecipientID:   in.UserID,
+		Event:         in.Event,
+		Title:         title.String(),
+		Body:          body.String(),
+	}
+	if err := tx.Create(&n).Error; err != nil {
+		return err
+	}
+
+	addresses, err := notificationAddresses(tx, in.Role, in.UserID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, channel := range []string{entity.NotificationChannelEmail, entity.NotificationChannelLine, entity.NotificationChannelSMS} {
+		if addresses[channel] == "" {
+			continue
+		}
+		d := entity.NotificationDelivery{
+			NotificationID: n.ID,
+			Channel:        channel,
+			Address:        addresses[channel],
+			Status:         entity.NotificationDeliveryPending,
+			NextAttemptAt:  now,
+		}
+		if err := tx.Create(&d).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recipientContact อีเมลและเบอร์โทรจากข้อมูลผู้ใช้ (ผู้จัดการไม่มีเบอร์โทร)
+func recipientContact(db *gorm.DB, role string, userID uint) (email, phone string, err error) {
+	var row struct {
+		Email string
+		Phone string
+	}
+	switch role {
+	case entity.AuditRoleCustomer:
+		err = db.Model(&entity.Customer{}).Select("email", "phone").Where("id = ?", userID).Limit(1).Find(&row).Error
+	case entity.AuditRoleEmployee:
+		err = db.Model(&entity.Employee{}).Select("email", "phone").Where("employee_id = ?", userID).Limit(1).Find(&row).Error
+	case entity.AuditRoleManager:
+		err = db.Model(&entity.Manager{}).Select("email").Where("id = ?", userID).Limit(1).Find(&row).Error
+	}
+	return strings.TrimSpace(row.Email), strings.TrimSpace(row.Phone), err
+}
+
+// notificationAddresses ที่อยู่ปลายทางของช่องทางที่ผู้ใช้เปิดไว้
+func notificationAddresses(db *gorm.DB, role string, userID uint) (map[string]string, error) {
+	pref, err := findNotificationPreference(db, role, userID)
+	if err != nil || pref == nil {
+		return nil, err
+	}
+	addresses := map[string]string{}
+	if pref.Email || pref.SMS {
+		email, phone, err := recipientContact(db, role, userID)
+		if err != nil {
+			return nil, err
+		}
+		if pref.Email {
+			addresses[entity.NotificationChannelEmail] = email
+		}
+		if pref.SMS {
+			addresses[entity.NotificationChannelSMS] = phone
+		}
+	}
+	if pref.Line {
+		addresses[entity.NotificationChannelLine] = pref.LineUserID
+	}
+	return addresses, nil
+}
+
+func findNotificationPreference(db *gorm.DB, role string, userID uint) (*entity.NotificationPreference, error) {
+	var prefs []entity.NotificationPreference
+	if err := db.Where("role = ? AND user_id = ?", role, userID).Limit(1).Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	if len(prefs) == 0 {
+		return nil, nil
+	}
+	return &prefs[0], nil
+}
+
+// NotificationFilter ?unread=true&event=&page=&limit=
+type NotificationFilter struct {
+	Unread bool   `form:"unread"`
+	Event  string `form:"event"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+// NotificationDeliveryFilter ?status=pending|sent|dead&channel=&page=&limit=
+type NotificationDeliveryFilter struct {
+	Status  string `form:"status"`
+	Channel string `form:"channel"`
+	Page    int    `form:"page"`
+	Limit   int    `form:"limit"`
+}
+
+// NotificationPreferenceInput ช่องทางที่ต้องการรับ (line ต้องระบุ line_user_id)
+type NotificationPreferenceInput struct {
+	Email      bool   `json:"email"`
+	Line       bool   `json:"line"`
+	SMS        bool   `json:"sms"`
+	LineUserID string `json:"line_user_id"`
+}
+
+// NotificationService กล่องแจ้งเตือน การตั้งค่าช่องทาง และการส่งออกนอกระบบ
+type NotificationService struct {
+	db       *gorm.DB
+	channels map[string]notify.Channel
+}
+
+func NewNotificationService(db *gorm.DB, channels map[string]notify.Channel) *NotificationService {
+	return &NotificationService{db: db, channels: channels}
+}
+
+func (s *NotificationService) WithContext(ctx context.Context) *NotificationService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func pageBounds(page, limit int) (int, int) {
+	if limit <= 0 || limit > MaxNotificationPageLimit {
+		limit = DefaultNotificationPageLimit
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return limit, (page - 1) * limit
+}
+
+// Inbox ข้อความของผู้ใช้ ใหม่สุดก่อน คืนจำนวนทั้งหมดตามตัวกรองและจำนวนที่ยังไม่อ่าน
+func (s *NotificationService) Inbox(role string, userID uint, f NotificationFilter) ([]entity.Notification, int64, int64, error) {
+	mine := func() *gorm.DB {
+		return s.db.Model(&entity.Notification{}).Where("recipient_role = ? AND recipient_id = ?", role, userID)
+	}
+	var unread int64
+	if err := mine().Where("read_at IS NULL").Count(&unread).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	q := mine()
+	if f.Unread {
+		q = q.Where("read_at IS NULL")
+	}
+	if f.Event != "" {
+		if _, ok := notificationTemplates[f.Event]; !ok {
+			return nil, 0, 0, fmt.Errorf("%w: unknown event %q", ErrInvalidNotificationFilter, f.Event)
+		}
+		q = q.Where("event = ?", f.Event)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, 0, err
+	}
+	limit, offset := pageBounds(f.Page, f.Limit)
+	var items []entity.Notification
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		return nil, 0, 0, err
+	}
+	return items, total, unread, nil
+}
+
+// MarkRead ทำเครื่องหมายว่าอ่านแล้ว (อ่านซ้ำไม่เปลี่ยนเวลาเดิม)
+func (s *NotificationService) MarkRead(role string, userID, id uint) (*entity.Notification, error) {
+	var n entity.Notification
+	if err := s.db.Where("recipient_role = ? AND recipient_id = ?", role, userID).First(&n, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrNotificationNotFound)
+	}
+	if n.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&n).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		n.ReadAt = &now
+	}
+	return &n, nil
+}
+
+// MarkAllRead อ่านทั้งหมด คืนจำนวนที่เปลี่ยน
+func (s *NotificationService) MarkAllRead(role string, userID uint) (int64, error) {
+	res := s.db.Model(&entity.Notification{}).
+		Where("recipient_role = ? AND recipient_id = ? AND read_at IS NULL", role, userID).
+		Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+// Preference ช่องทางที่ผู้ใช้เลือก (ยังไม่เคยตั้ง = ในระบบอย่างเดียว)
+func (s *NotificationService) Preference(role string, userID uint) (*entity.NotificationPreference, error) {
+	pref, err := findNotificationPreference(s.db, role, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pref == nil {
+		pref = &entity.NotificationPreference{Role: role, UserID: userID}
+	}
+	return pref, nil
+}
+
+// SetPreference ตั้งช่องทาง ตรวจว่ามีอีเมล/เบอร์โทรให้ส่งถึงจริง
+func (s *NotificationService) SetPreference(role string, userID uint, in NotificationPreferenceInput) (*entity.NotificationPreference, error) {
+	in.LineUserID = strings.TrimSpace(in.LineUserID)
+	if in.Line && in.LineUserID == "" {
+		return nil, fmt.Errorf("%w: line_user_id is required for LINE", ErrInvalidNotificationPref)
+	}
+	if in.Email || in.SMS {
+		email, phone, err := recipientContact(s.db, role, userID)
+		if err != nil {
+			return nil, err
+		}
+		if in.Email && email == "" {
+			return nil, fmt.Errorf("%w: no email address on your profile", ErrInvalidNotificationPref)
+		}
+		if in.SMS && phone == "" {
+			return nil, fmt.Errorf("%w: no phone number on your profile", ErrInvalidNotificationPref)
+		}
+	}
+
+	pref, err := s.Preference(role, userID)
+	if err != nil {
+		return nil, err
+	}
+	pref.Email, pref.Line, pref.SMS, pref.LineUserID = in.Email, in.Line, in.SMS, in.LineUserID
+	if err := s.db.Save(pref).Error; err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+// Deliveries รายการส่งออกนอกระบบ ใหม่สุดก่อน (ใช้ดู dead-letter: ?status=dead)
+func (s *NotificationService) Deliveries(f NotificationDeliveryFilter) ([]entity.NotificationDelivery, int64, error) {
+	q := s.db.Model(&entity.NotificationDelivery{})
+	if f.Status != "" {
+		if !containsString([]string{entity.NotificationDeliveryPending, entity.NotificationDeliverySent, entity.NotificationDeliveryDead}, f.Status) {
+			return nil, 0, fmt.Errorf("%w: status must be pending, sent or dead", ErrInvalidNotificationFilter)
+		}
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.Channel != "" {
+		q = q.Where("channel = ?", f.Channel)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	limit, offset := pageBounds(f.Page, f.Limit)
+	var items []entity.NotificationDelivery
+	err := q.Preload("Notification").Order("id DESC").Limit(limit).Offset(offset).Find(&items).Error
+	return items, total, err
+}
+
+// RetryDelivery นำรายการที่ dead (หรือรออยู่) กลับเข้าคิวส่งทันทีโดยเริ่มนับครั้งใหม่
+func (s *NotificationService) RetryDelivery(id uint) (*entity.NotificationDelivery, error) {
+	var d entity.NotificationDelivery
+	if err := s.db.First(&d, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrNotificationDeliveryNotFound)
+	}
+	if d.Status == entity.NotificationDeliverySent {
+		return nil, ErrNotificationDeliverySent
+	}
+	err := s.db.Model(&d).Updates(map[string]interface{}{
+		"status":          entity.NotificationDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// กันไม่ให้ worker กับการสั่งส่งทันทีของผู้จัดการส่งรายการเดียวกันซ้ำ
+var notificationDispatchMu sync.Mutex
+
+// ProcessDue ส่งรายการที่ถึงเวลา คืนจำนวนรายการที่ลองส่งในรอบนี้
+func (s *NotificationService) ProcessDue(ctx context.Context) (int, error) {
+	notificationDispatchMu.Lock()
+	defer notificationDispatchMu.Unlock()
+
+	var due []entity.NotificationDelivery
+	err := s.db.Preload("Notification").
+		Where("status = ? AND next_attempt_at <= ?", entity.NotificationDeliveryPending, time.Now()).
+		Order("id ASC").Limit(notificationBatchSize).Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+	for i := range due {
+		if err := s.deliver(ctx, &due[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
+// deliver ส่งหนึ่งครั้ง: สำเร็จ = sent, error ถาวรหรือครบจำนวนครั้ง = dead, นอกนั้นเลื่อนไปรอบถัดไป
+func (s *NotificationService) deliver(ctx context.Context, d *entity.NotificationDelivery) error {
+	var err error
+	channel, ok := s.channels[d.Channel]
+	switch {
+	case !ok:
+		err = notify.Permanent(fmt.Errorf("channel %s is not configured", d.Channel))
+	case d.Notification == nil:
+		err = notify.Permanent(errors.New("notification has been deleted"))
+	default:
+		sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+		err = channel.Send(sendCtx, notify.Message{To: d.Address, Subject: d.Notification.Title, Body: d.Notification.Body})
+		cancel()
+	}
+
+	now := time.Now()
+	attempts := d.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+	switch {
+	case err == nil:
+		updates["status"] = entity.NotificationDeliverySent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	case notify.IsPermanent(err) || attempts >= MaxNotificationAttempts:
+		updates["status"] = entity.NotificationDeliveryDead
+		updates["last_error"] = err.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(notificationRetryBase << (attempts - 1))
+		updates["last_error"] = err.Error()
+	}
+	return s.db.Model(d).Updates(updates).Error
+}
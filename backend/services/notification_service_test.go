@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"github.com/PanuAutawo/CarTentManagement/backend/notify"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newNotificationTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&entity.Notification{}, &entity.NotificationDelivery{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// smsGateway ตอบตาม statuses ทีละครั้ง (ครั้งที่เกินใช้ค่าสุดท้าย)
+type smsGateway struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    int
+}
+
+func newSMSGateway(t *testing.T, statuses ...int) (*smsGateway, notify.Channel) {
+	t.Helper()
+	g := &smsGateway{statuses: statuses}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.mu.Lock()
+		status := g.statuses[min(g.calls, len(g.statuses)-1)]
+		g.calls++
+		g.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	ch, err := notify.NewSMSChannel(notify.SMSConfig{Endpoint: srv.URL, Sender: "CarTent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g, ch
+}
+
+func queueTestDelivery(t *testing.T, db *gorm.DB, channel string) *entity.NotificationDelivery {
+	t.Helper()
+	n := entity.Notification{
+		RecipientRole: entity.AuditRoleCustomer,
+		RecipientID:   1,
+		Event:         entity.NotificationPriceDrop,
+		Title:         "รถที่คุณติดตามลดราคา",
+		Body:          "Toyota Camry ลดราคาจาก 900,000 เหลือ 850,000 บาท",
+	}
+	if err := db.Create(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	d := entity.NotificationDelivery{
+		NotificationID: n.ID,
+		Channel:        channel,
+		Address:        "0812345678",
+		Status:         entity.NotificationDeliveryPending,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := db.Create(&d).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &d
+}
+
+func reloadDelivery(t *testing.T, db *gorm.DB, id uint) entity.NotificationDelivery {
+	t.Helper()
+	var d entity.NotificationDelivery
+	if err := db.First(&d, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func processDue(t *testing.T, svc *NotificationService, want int) {
+	t.Helper()
+	n, err := svc.ProcessDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != want {
+		t.Fatalf("ProcessDue processed %d deliveries, want %d", n, want)
+	}
+}
+
+func TestProcessDueRetriesWithBackoffThenDeadLetters(t *testing.T) {
+	db := newNotificationTestDB(t)
+	gateway, sms := newSMSGateway(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusBadGateway)
+	svc := NewNotificationService(db, map[string]notify.Channel{entity.NotificationChannelSMS: sms})
+	d := queueTestDelivery(t, db, entity.NotificationChannelSMS)
+
+	for attempt := 1; attempt < MaxNotificationAttempts; attempt++ {
+		before := time.Now()
+		processDue(t, svc, 1)
+		got := reloadDelivery(t, db, d.ID)
+		if got.Status != entity.NotificationDeliveryPending || got.Attempts != attempt {
+			t.Fatalf("attempt %d: status=%s attempts=%d", attempt, got.Status, got.Attempts)
+		}
+		if got.LastError == "" {
+			t.Errorf("attempt %d: last_error is empty", attempt)
+		}
+		wait := notificationRetryBase << (attempt - 1)
+		if got.NextAttemptAt.Before(before.Add(wait)) || got.NextAttemptAt.After(time.Now().Add(wait)) {
+			t.Errorf("attempt %d: next attempt in %s, want %s", attempt, got.NextAttemptAt.Sub(before).Round(time.Second), wait)
+		}
+
+		// ยังไม่ถึงเวลา รอบนี้ต้องไม่ส่ง
+		processDue(t, svc, 0)
+		if err := db.Model(&got).Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	processDue(t, svc, 1)
+	got := reloadDelivery(t, db, d.ID)
+	if got.Status != entity.NotificationDeliveryDead || got.Attempts != MaxNotificationAttempts {
+		t.Fatalf("after %d attempts: status=%s attempts=%d, want dead", MaxNotificationAttempts, got.Status, got.Attempts)
+	}
+	if !strings.Contains(got.LastError, "502") {
+		t.Errorf("last_error = %q", got.LastError)
+	}
+	processDue(t, svc, 0)
+	if gateway.calls != MaxNotificationAttempts {
+		t.Errorf("gateway called %d times, want %d", gateway.calls, MaxNotificationAttempts)
+	}
+}
+
+func TestProcessDuePermanentErrorDeadLettersImmediately(t *testing.T) {
+	db := newNotificationTestDB(t)
+	gateway, sms := newSMSGateway(t, http.StatusBadRequest)
+	svc := NewNotificationService(db, map[string]notify.Channel{entity.NotificationChannelSMS: sms})
+	d := queueTestDelivery(t, db, entity.NotificationChannelSMS)
+	unconfigured := queueTestDelivery(t, db, entity.NotificationChannelLine)
+
+	processDue(t, svc, 2)
+	if got := reloadDelivery(t, db, d.ID); got.Status != entity.NotificationDeliveryDead || got.Attempts != 1 {
+		t.Errorf("400 from gateway: status=%s attempts=%d, want dead after 1", got.Status, got.Attempts)
+	}
+	if got := reloadDelivery(t, db, unconfigured.ID); got.Status != entity.NotificationDeliveryDead ||
+		!strings.Contains(got.LastError, "not configured") {
+		t.Errorf("unconfigured channel: status=%s last_error=%q", got.Status, got.LastError)
+	}
+	if gateway.calls != 1 {
+		t.Errorf("gateway called %d times, want 1", gateway.calls)
+	}
+}
+
+func TestProcessDueSendsAfterTransientError(t *testing.T) {
+	db := newNotificationTestDB(t)
+	_, sms := newSMSGateway(t, http.StatusTooManyRequests, http.StatusOK)
+	svc := NewNotificationService(db, map[string]notify.Channel{entity.NotificationChannelSMS: sms})
+	d := queueTestDelivery(t, db, entity.NotificationChannelSMS)
+
+	processDue(t, svc, 1)
+	if got := reloadDelivery(t, db, d.ID); got.Status != entity.NotificationDeliveryPending || got.Attempts != 1 {
+		t.Fatalf("429: status=%s attempts=%d, want pending", got.Status, got.Attempts)
+	}
+	db.Model(&entity.NotificationDelivery{}).Where("id = ?", d.ID).Update("next_attempt_at", time.Now().Add(-time.Second))
+
+	processDue(t, svc, 1)
+	got := reloadDelivery(t, db, d.ID)
+	if got.Status != entity.NotificationDeliverySent || got.Attempts != 2 || got.SentAt == nil || got.LastError != "" {
+		t.Errorf("after retry: status=%s attempts=%d sent_at=%v last_error=%q", got.Status, got.Attempts, got.SentAt, got.LastError)
+	}
+}
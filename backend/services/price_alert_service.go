@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPriceAlertNotFound    = errors.New("price alert not found")
+	ErrPriceAlertCarNotFound = errors.New("car not found")
+	ErrInvalidPriceAlert     = errors.New("invalid price alert")
+	ErrPriceAlertExists      = errors.New("already subscribed")
+)
+
+// PriceAlertInput ระบุอย่างใดอย่างหนึ่ง: car_id (ติดตามราคารถ) หรือ saved_search_id (ติดตามรถใหม่ที่ตรงตัวกรองที่บันทึกไว้)
+type PriceAlertInput struct {
+	CarID         *uint `json:"car_id"`
+	SavedSearchID *uint `json:"saved_search_id"`
+}
+
+// PriceAlertView การติดตามพร้อมชื่อรถ หรือชื่อและตัวกรองของ SavedSearch
+type PriceAlertView struct {
+	entity.PriceAlert
+	Filter     *CarFilter `json:"filter,omitempty"`
+	CarName    string     `json:"car_name,omitempty"`
+	SearchName string     `json:"search_name,omitempty"`
+}
+
+// PriceAlertHitView การแจ้งเตือนพร้อมชื่อรถ
+type PriceAlertHitView struct {
+	entity.PriceAlertHit
+	CarName string `json:"car_name"`
+}
+
+// PriceAlertService การติดตามราคาของลูกค้า
+type PriceAlertService struct {
+	db *gorm.DB
+}
+
+func NewPriceAlertService(db *gorm.DB) *PriceAlertService {
+	return &PriceAlertService{db: db}
+}
+
+func (s *PriceAlertService) WithContext(ctx context.Context) *PriceAlertService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidPriceAlert(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPriceAlert, reason)
+}
+
+// List การติดตามทั้งหมดของลูกค้า ใหม่สุดก่อน
+func (s *PriceAlertService) List(customerID uint) ([]PriceAlertView, error) {
+	var alerts []entity.PriceAlert
+	if err := s.db.Where("customer_id = ?", customerID).Order("id DESC").Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	names, err := carNames(s.db, alertCarIDs(alerts))
+	if err != nil {
+		return nil, err
+	}
+	var searches []entity.SavedSearch
+	if err := s.db.Where("customer_id = ?", customerID).Find(&searches).Error; err != nil {
+		return nil, err
+	}
+	searchByID := make(map[uint]entity.SavedSearch, len(searches))
+	for _, ss := range searches {
+		searchByID[ss.ID] = ss
+	}
+	views := make([]PriceAlertView, len(alerts))
+	for i, a := range alerts {
+		views[i] = PriceAlertView{PriceAlert: a}
+		if a.CarID != nil {
+			views[i].CarName = names[*a.CarID]
+		}
+		if a.SavedSearchID != nil {
+			ss, ok := searchByID[*a.SavedSearchID]
+			if !ok {
+				continue
+			}
+			var f CarFilter
+			if err := json.Unmarshal([]byte(ss.Filter), &f); err != nil {
+				return nil, err
+			}
+			views[i].Filter = &f
+			views[i].SearchName = ss.Name
+		}
+	}
+	return views, nil
+}
+
+// Create ติดตามรถหรือ SavedSearch ของลูกค้าเอง (ซ้ำกับที่มีอยู่ = ErrPriceAlertExists)
+func (s *PriceAlertService) Create(customerID uint, in PriceAlertInput) (*PriceAlertView, error) {
+	alert := entity.PriceAlert{CustomerID: customerID}
+	switch {
+	case in.CarID != nil && in.SavedSearchID != nil:
+		return nil, invalidPriceAlert("use either car_id or saved_search_id")
+	case in.CarID != nil:
+		if ok, err := recordExists(s.db, &entity.Car{}, "id = ?", *in.CarID); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, ErrPriceAlertCarNotFound
+		}
+		alert.Kind = entity.PriceAlertCar
+		alert.CarID = in.CarID
+	case in.SavedSearchID != nil:
+		var ss entity.SavedSearch
+		if err := s.db.Where("customer_id = ?", customerID).First(&ss, *in.SavedSearchID).Error; err != nil {
+			return nil, notFoundAs(err, ErrSavedSearchNotFound)
+		}
+		var f CarFilter
+		if err := json.Unmarshal([]byte(ss.Filter), &f); err != nil {
+			return nil, err
+		}
+		f.Status, f.Sort = "", ""
+		if data, err := json.Marshal(f); err != nil {
+			return nil, err
+		} else if string(data) == "{}" {
+			return nil, invalidPriceAlert("saved search needs at least one condition")
+		}
+		alert.Kind = entity.PriceAlertSearch
+		alert.SavedSearchID = &ss.ID
+	default:
+		return nil, invalidPriceAlert("car_id or saved_search_id is required")
+	}
+
+	var n int64
+	q := s.db.Model(&entity.PriceAlert{}).Where("customer_id = ? AND kind = ?", customerID, alert.Kind)
+	if alert.CarID != nil {
+		q = q.Where("car_id = ?", *alert.CarID)
+	} else {
+		q = q.Where("saved_search_id = ?", *alert.SavedSearchID)
+	}
+	if err := q.Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, ErrPriceAlertExists
+	}
+
+	if err := s.db.Create(&alert).Error; err != nil {
+		return nil, err
+	}
+	return s.get(customerID, alert.ID)
+}
+
+func (s *PriceAlertService) get(customerID, id uint) (*PriceAlertView, error) {
+	views, err := s.List(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range views {
+		if views[i].ID == id {
+			return &views[i], nil
+		}
+	}
+	return nil, ErrPriceAlertNotFound
+}
+
+// Delete เลิกติดตาม
+func (s *PriceAlertService) Delete(customerID, id uint) error {
+	res := s.db.Where("id = ? AND customer_id = ?", id, customerID).Delete(&entity.PriceAlert{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPriceAlertNotFound
+	}
+	return nil
+}
+
+// Hits การแจ้งเตือนของลูกค้า ใหม่สุดก่อน
+func (s *PriceAlertService) Hits(customerID uint) ([]PriceAlertHitView, error) {
+	var hits []entity.PriceAlertHit
+	if err := s.db.Where("customer_id = ?", customerID).Order("id DESC").Limit(100).Find(&hits).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.CarID
+	}
+	names, err := carNames(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]PriceAlertHitView, len(hits))
+	for i, h := range hits {
+		views[i] = PriceAlertHitView{PriceAlertHit: h, CarName: names[h.CarID]}
+	}
+	return views, nil
+}
+
+func alertCarIDs(alerts []entity.PriceAlert) []uint {
+	var ids []uint
+	for _, a := range alerts {
+		if a.CarID != nil {
+			ids = append(ids, *a.CarID)
+		}
+	}
+	return ids
+}
+
+func carNames(db *gorm.DB, ids []uint) (map[uint]string, error) {
+	names := map[uint]string{}
+	if len(ids) > 0 {
+		var cars []entity.Car
+		if err := db.Unscoped().Select("id", "car_name").Where("id IN ?", ids).Find(&cars).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range cars {
+			names[c.ID] = c.CarName
+		}
+	}
+	return names, nil
+}
+
+// RecordSalePrice บันทึกประวัติราคาของประกาศ (oldPrice = 0 คือเพิ่งเปิดขาย)
+// และสร้างการแจ้งเตือน: ราคาลด → ผู้ติดตามรถคันนั้น, เปิดขาย/ราคาลด → ผู้ติดตามเงื่อนไขที่รถตรงแล้ว
+func RecordSalePrice(tx *gorm.DB, sale *entity.SaleList, oldPrice float64, managerID *uint) error {
+	if oldPrice == sale.SalePrice {
+		return nil
+	}
+	change := entity.SalePriceChange{
+		SaleListID: sale.ID,
+		CarID:      sale.CarID,
+		OldPrice:   oldPrice,
+		NewPrice:   sale.SalePrice,
+		ManagerID:  managerID,
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return err
+	}
+	if sale.Status != entity.SaleListAvailable {
+		return nil
+	}
+
+	opened := oldPrice == 0
+	dropped := !opened && sale.SalePrice < oldPrice
+	if !opened && !dropped {
+		return nil
+	}
+
+	var alerts []entity.PriceAlert
+	q := tx.Where("kind = ?", entity.PriceAlertSearch)
+	if dropped {
+		q = tx.Where("kind = ? OR (kind = ? AND car_id = ?)", entity.PriceAlertSearch, entity.PriceAlertCar, sale.CarID)
+	}
+	if err := q.Find(&alerts).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, alert := range alerts {
+		event := entity.PriceAlertPriceDrop
+		if alert.Kind == entity.PriceAlertSearch {
+			event = entity.PriceAlertNewListing
+			ok, err := searchAlertMatches(tx, alert, sale.CarID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		hit := entity.PriceAlertHit{
+			PriceAlertID: alert.ID,
+			CustomerID:   alert.CustomerID,
+			Event:        event,
+			CarID:        sale.CarID,
+			SaleListID:   sale.ID,
+			OldPrice:     oldPrice,
+			NewPrice:     sale.SalePrice,
+		}
+		if err := tx.Create(&hit).Error; err != nil {
+			return err
+		}
+		notification := entity.NotificationPriceDrop
+		if event == entity.PriceAlertNewListing {
+			notification = entity.NotificationNewListing
+		}
+		err := Notify(tx, NotificationInput{
+			Role:   entity.AuditRoleCustomer,
+			UserID: alert.CustomerID,
+			Event:  notification,
+			CarID:  sale.CarID,
+			Data:   map[string]interface{}{"OldPrice": oldPrice, "NewPrice": sale.SalePrice},
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&alert).Update("last_triggered_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchAlertMatches รถตรงตัวกรองของ SavedSearch และยังไม่เคยแจ้งคันนี้ให้การติดตามนี้
+func searchAlertMatches(tx *gorm.DB, alert entity.PriceAlert, carID uint) (bool, error) {
+	if alert.SavedSearchID == nil {
+		return false, nil
+	}
+	var n int64
+	err := tx.Model(&entity.PriceAlertHit{}).
+		Where("price_alert_id = ? AND car_id = ? AND event = ?", alert.ID, carID, entity.PriceAlertNewListing).
+		Count(&n).Error
+	if err != nil || n > 0 {
+		return false, err
+	}
+	var searches []entity.SavedSearch
+	if err := tx.Where("id = ? AND customer_id = ?", *alert.SavedSearchID, alert.CustomerID).
+		Limit(1).Find(&searches).Error; err != nil || len(searches) == 0 {
+		return false, err
+	}
+	var f CarFilter
+	if err := json.Unmarshal([]byte(searches[0].Filter), &f); err != nil {
+		return false, err
+	}
+	f.Status = "sale"
+	err = ApplyCarFilter(tx, f).Where("cars.id = ?", carID).Count(&n).Error
+	return n > 0, err
+}
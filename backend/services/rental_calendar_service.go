@@ -0,0 +1,228 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+// สถานะของรถเช่าในแต่ละวัน
+const (
+	CalendarAvailable   = "available"   // เปิดให้เช่าและว่าง
+	CalendarBooked      = "booked"      // มีสัญญาเช่าหรือถูกจองแล้ว
+	CalendarBuffer      = "buffer"      // วันเตรียมรถหลังคืน หรือวันนัดส่งมอบรถ
+	CalendarClosed      = "closed"      // ไม่ได้เปิดให้เช่า (ไม่มี DateforRent)
+	CalendarMaintenance = "maintenance" // รถอยู่อู่
+	CalendarPast        = "past"
+)
+
+const (
+	// ดูปฏิทินได้ครั้งละไม่เกิน ~3 เดือน และไม่เกิน 50 คัน
+	maxCalendarDays = 93
+	maxCalendarCars = 50
+	// หลังคืนรถเช่าต้องเว้นไว้ตรวจ/ทำความสะอาด 1 วัน
+	rentalTurnaroundDays = 1
+)
+
+// ErrRentalDatesBooked ช่วงที่ขอทับสัญญาเช่าเดิมหรือวันเตรียมรถหลังคืน
+var ErrRentalDatesBooked = errors.New("car is already booked for these dates")
+
+// RentalOverlap มีสัญญาเช่ารถคันนี้ (รวมวันเตรียมรถหลังคืน) ทับช่วง [start, end] หรือไม่
+// สัญญาใหม่ก็ต้องเว้นวันเตรียมรถก่อนสัญญาถัดไปเช่นกัน
+func RentalOverlap(tx *gorm.DB, carID uint, start, end time.Time) (bool, error) {
+	var n int64
+	err := tx.Model(&entity.RentContract{}).
+		Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ? AND rent_contracts.date_start < ? AND rent_contracts.date_end >= ?",
+			carID, end.AddDate(0, 0, 1+rentalTurnaroundDays), start.AddDate(0, 0, -rentalTurnaroundDays)).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// RentalCalendarFilter ?start_date=&end_date=&car_id=1&car_id=2 หรือใช้ตัวกรองเดียวกับหน้ารายการรถ
+type RentalCalendarFilter struct {
+	CarFilter
+	CarIDs    []uint `form:"car_id"`
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+}
+
+// CalendarDay สถานะและราคาของรถหนึ่งวัน
+type CalendarDay struct {
+	Date           string   `json:"date"`
+	State          string   `json:"state"`
+	Available      bool     `json:"available"`
+	Price          *float64 `json:"price"` // nil = ไม่มีราคาสำหรับวันนั้น
+	Adjustments    []string `json:"adjustments,omitempty"`
+	RentContractID *uint    `json:"rent_contract_id,omitempty"`
+}
+
+// CarCalendar ปฏิทินรายวันของรถหนึ่งคัน
+type CarCalendar struct {
+	CarID   uint          `json:"car_id"`
+	CarName string        `json:"car_name"`
+	Days    []CalendarDay `json:"days"`
+}
+
+type RentalCalendar struct {
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	Cars      []CarCalendar `json:"cars"`
+}
+
+// RentalCalendarService ปฏิทินว่าง/ไม่ว่างรายวันของรถเช่า
+type RentalCalendarService struct {
+	db      *gorm.DB
+	pricing *RentalPricingService
+}
+
+func NewRentalCalendarService(db *gorm.DB) *RentalCalendarService {
+	return &RentalCalendarService{db: db, pricing: NewRentalPricingService(db)}
+}
+
+type calendarContract struct {
+	ID        uint
+	DateStart time.Time
+	DateEnd   time.Time
+}
+
+// Calendar ปฏิทินของรถที่มีประกาศเช่า (ระบุ car_id หรือกรองแบบหน้ารายการรถ)
+func (s *RentalCalendarService) Calendar(f RentalCalendarFilter) (*RentalCalendar, error) {
+	start, end, _, err := parseRentalRange(f.StartDate, f.EndDate, maxCalendarDays)
+	if err != nil {
+		return nil, err
+	}
+
+	q := ApplyCarFilter(s.db, f.CarFilter).
+		Where("EXISTS (SELECT 1 FROM rent_lists WHERE rent_lists.car_id = cars.id AND rent_lists.deleted_at IS NULL)").
+		Preload("Detail").Order("cars.id ASC").Limit(maxCalendarCars)
+	if len(f.CarIDs) > 0 {
+		q = q.Where("cars.id IN ?", f.CarIDs)
+	}
+	var cars []entity.Car
+	if err := q.Find(&cars).Error; err != nil {
+		return nil, err
+	}
+
+	cal := &RentalCalendar{StartDate: f.StartDate, EndDate: f.EndDate, Cars: []CarCalendar{}}
+	for i := range cars {
+		days, err := s.carDays(&cars[i], start, end)
+		if err != nil {
+			return nil, err
+		}
+		cal.Cars = append(cal.Cars, CarCalendar{CarID: cars[i].ID, CarName: cars[i].CarName, Days: days})
+	}
+	return cal, nil
+}
+
+func (s *RentalCalendarService) carDays(car *entity.Car, start, end time.Time) ([]CalendarDay, error) {
+	pricer, err := s.pricing.pricerFor(car, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// สัญญาที่จบก่อนช่วงไม่เกินวันเว้นว่าง ยังมีผลกับวันแรกของช่วง
+	var contracts []calendarContract
+	if err := s.db.Model(&entity.RentContract{}).
+		Select("rent_contracts.id, rent_contracts.date_start, rent_contracts.date_end").
+		Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ? AND rent_contracts.date_start < ? AND rent_contracts.date_end >= ?",
+			car.ID, end.AddDate(0, 0, 1), start.AddDate(0, 0, -rentalTurnaroundDays)).
+		Scan(&contracts).Error; err != nil {
+		return nil, err
+	}
+
+	var deliveries []time.Time
+	if err := s.db.Model(&entity.PickupDelivery{}).
+		Joins("JOIN sales_contracts ON sales_contracts.id = pickup_deliveries.sales_contract_id").
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id").
+		Where("sale_lists.car_id = ? AND pickup_deliveries.status <> ? AND pickup_deliveries.date_time >= ? AND pickup_deliveries.date_time < ?",
+			car.ID, pickupDeliveryCancelled, start, end.AddDate(0, 0, 1)).
+		Pluck("pickup_deliveries.date_time", &deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	var blocks []entity.MaintenanceRecord
+	if err := s.db.Where("car_id = ? AND status IN ? AND planned_start < ? AND (planned_end >= ? OR status = ?)",
+		car.ID, activeMaintenanceStatuses, end.AddDate(0, 0, 1), start, entity.MaintenanceInProgress).
+		Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	days := []CalendarDay{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		d := CalendarDay{Date: day.Format("2006-01-02")}
+		if line, ok := pricer.line(day); ok {
+			price := line.Amount
+			d.Price = &price
+			d.Adjustments = line.Adjustments
+		}
+
+		window := pricer.window(day)
+		switch {
+		case d.Date < today:
+			d.State = CalendarPast
+		case bookedBy(contracts, day) != nil:
+			d.State = CalendarBooked
+			d.RentContractID = bookedBy(contracts, day)
+		case inMaintenance(blocks, day):
+			d.State = CalendarMaintenance
+		case window != nil && window.Status == entity.DateforRentBooked:
+			d.State = CalendarBooked
+		case inTurnaround(contracts, day) || deliveryOn(deliveries, day):
+			d.State = CalendarBuffer
+		case window == nil:
+			d.State = CalendarClosed
+		default:
+			d.State = CalendarAvailable
+		}
+		d.Available = d.State == CalendarAvailable && d.Price != nil
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+func bookedBy(contracts []calendarContract, day time.Time) *uint {
+	for i := range contracts {
+		if sameOrBetween(day, contracts[i].DateStart, contracts[i].DateEnd) {
+			return &contracts[i].ID
+		}
+	}
+	return nil
+}
+
+func inTurnaround(contracts []calendarContract, day time.Time) bool {
+	for _, c := range contracts {
+		after := c.DateEnd.AddDate(0, 0, 1)
+		if sameOrBetween(day, after, c.DateEnd.AddDate(0, 0, rentalTurnaroundDays)) {
+			return true
+		}
+	}
+	return false
+}
+
+func deliveryOn(deliveries []time.Time, day time.Time) bool {
+	d := day.Format("2006-01-02")
+	for _, t := range deliveries {
+		if t.Format("2006-01-02") == d {
+			return true
+		}
+	}
+	return false
+}
+
+// งานที่กำลังซ่อมกันรถไปจนกว่าจะปิดงาน
+func inMaintenance(blocks []entity.MaintenanceRecord, day time.Time) bool {
+	for _, b := range blocks {
+		if b.Status == entity.MaintenanceInProgress && day.Format("2006-01-02") >= b.PlannedStart.Format("2006-01-02") {
+			return true
+		}
+		if sameOrBetween(day, b.PlannedStart, b.PlannedEnd) {
+			return true
+		}
+	}
+	return false
+}
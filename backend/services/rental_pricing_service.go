@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRentalRule        = errors.New("invalid rental price rule")
+	ErrRentalRuleNotFound       = errors.New("rental price rule not found")
+	ErrRentalRuleConflict       = errors.New("an active rule already exists for this car, model or brand")
+	ErrInvalidRentalAdjustment  = errors.New("invalid rental price adjustment")
+	ErrRentalAdjustmentNotFound = errors.New("rental price adjustment not found")
+	ErrInvalidQuoteRange        = errors.New("start_date and end_date must be YYYY-MM-DD with end_date on or after start_date")
+	ErrRentalBelowMinimum       = errors.New("rental is shorter than the minimum length")
+	ErrRentalPriceNotSet        = errors.New("no rental price is set for this date")
+	ErrRentalNotOpen            = errors.New("car is not open for rent on this date")
+	ErrRentalCarNotFound        = errors.New("car not found")
+)
+
+// ใบเสนอราคาคำนวณได้ไม่เกิน 1 ปีต่อครั้ง
+const maxQuoteDays = 366
+
+// RentalDiscountInput ส่วนลดขั้นบันได
+type RentalDiscountInput struct {
+	MinDays int     `json:"min_days" binding:"required"`
+	Percent float64 `json:"percent" binding:"required"`
+}
+
+// RentalRuleInput กฎราคาเช่า ระบุ car_id หรือ car_model_id หรือ brand_id ได้อย่างมากหนึ่งอย่าง
+type RentalRuleInput struct {
+	Name        string                `json:"name" binding:"required"`
+	CarID       *uint                 `json:"car_id"`
+	CarModelID  *uint                 `json:"car_model_id"`
+	BrandID     *uint                 `json:"brand_id"`
+	WeekdayRate float64               `json:"weekday_rate" binding:"required"`
+	WeekendRate float64               `json:"weekend_rate"` // ไม่ระบุ = เท่ากับวันธรรมดา
+	MinDays     int                   `json:"min_days"`
+	Discounts   []RentalDiscountInput `json:"discounts"`
+	Active      *bool                 `json:"active"` // ไม่ระบุ = เปิดใช้
+}
+
+// RentalAdjustmentInput ตัวคูณราคาช่วงวันหยุด/ฤดูกาล
+type RentalAdjustmentInput struct {
+	Name       string  `json:"name" binding:"required"`
+	Kind       string  `json:"kind" binding:"required"`       // holiday | season
+	StartDate  string  `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate    string  `json:"end_date" binding:"required"`   // YYYY-MM-DD (รวมวันสุดท้าย)
+	Multiplier float64 `json:"multiplier" binding:"required"`
+}
+
+// RentalQuoteLine ราคาของแต่ละวัน
+type RentalQuoteLine struct {
+	Date        string   `json:"date"`
+	DayType     string   `json:"day_type"` // weekday, weekend
+	BaseRate    float64  `json:"base_rate"`
+	Multiplier  float64  `json:"multiplier"`
+	Adjustments []string `json:"adjustments,omitempty"`
+	Amount      float64  `json:"amount"`
+}
+
+// RentalQuote ใบเสนอราคาเช่าแยกรายวัน
+type RentalQuote struct {
+	CarID           uint              `json:"car_id"`
+	RuleID          *uint             `json:"rule_id"`
+	RuleName        string            `json:"rule_name"` // ว่าง = ใช้ราคาช่วงเช่า (DateforRent)
+	StartDate       string            `json:"start_date"`
+	EndDate         string            `json:"end_date"`
+	Days            int               `json:"days"`
+	Lines           []RentalQuoteLine `json:"lines"`
+	Subtotal        float64           `json:"subtotal"`
+	DiscountPercent float64           `json:"discount_percent"`
+	Discount        float64           `json:"discount"`
+	Total           float64           `json:"total"`
+}
+
+// RentalPricingService กฎราคาเช่าและการคำนวณใบเสนอราคา
+type RentalPricingService struct {
+	db *gorm.DB
+}
+
+func NewRentalPricingService(db *gorm.DB) *RentalPricingService {
+	return &RentalPricingService{db: db}
+}
+
+func (s *RentalPricingService) WithContext(ctx context.Context) *RentalPricingService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidRentalRule(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRentalRule, reason)
+}
+
+func invalidRentalAdjustment(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRentalAdjustment, reason)
+}
+
+func (s *RentalPricingService) applyRuleInput(rule *entity.RentalPriceRule, in RentalRuleInput) error {
+	scopes := 0
+	for _, scope := range []struct {
+		id    *uint
+		model interface{}
+		name  string
+	}{
+		{in.CarID, &entity.Car{}, "car"},
+		{in.CarModelID, &entity.CarModel{}, "car model"},
+		{in.BrandID, &entity.Brand{}, "brand"},
+	} {
+		if scope.id == nil {
+			continue
+		}
+		scopes++
+		ok, err := recordExists(s.db, scope.model, "id = ?", *scope.id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidRentalRule(scope.name + " does not exist")
+		}
+	}
+	if scopes > 1 {
+		return invalidRentalRule("use only one of car_id, car_model_id or brand_id")
+	}
+	if in.WeekdayRate <= 0 || in.WeekendRate < 0 {
+		return invalidRentalRule("weekday_rate must be greater than 0 and weekend_rate must not be negative")
+	}
+	if in.MinDays < 0 {
+		return invalidRentalRule("min_days must not be negative")
+	}
+	seen := map[int]bool{}
+	for _, d := range in.Discounts {
+		if d.MinDays < 2 || d.Percent <= 0 || d.Percent >= 100 {
+			return invalidRentalRule("discount min_days must be at least 2 and percent between 0 and 100")
+		}
+		if seen[d.MinDays] {
+			return invalidRentalRule("discount min_days must be unique")
+		}
+		seen[d.MinDays] = true
+	}
+
+	rule.Name = strings.TrimSpace(in.Name)
+	rule.CarID = in.CarID
+	rule.CarModelID = in.CarModelID
+	rule.BrandID = in.BrandID
+	rule.WeekdayRate = roundBaht(in.WeekdayRate)
+	rule.WeekendRate = roundBaht(in.WeekendRate)
+	if rule.WeekendRate == 0 {
+		rule.WeekendRate = rule.WeekdayRate
+	}
+	rule.MinDays = in.MinDays
+	rule.Active = in.Active == nil || *in.Active
+	return nil
+}
+
+// ruleScope เงื่อนไขหา rule ที่ใช้กับขอบเขตเดียวกัน
+func ruleScope(q *gorm.DB, rule *entity.RentalPriceRule) *gorm.DB {
+	for col, id := range map[string]*uint{"car_id": rule.CarID, "car_model_id": rule.CarModelID, "brand_id": rule.BrandID} {
+		if id == nil {
+			q = q.Where(col + " IS NULL")
+		} else {
+			q = q.Where(col+" = ?", *id)
+		}
+	}
+	return q
+}
+
+// saveRule บันทึกกฎพร้อมแทนที่ส่วนลดทั้งหมด ขอบเขตหนึ่งมีกฎที่เปิดใช้ได้กฎเดียว
+func (s *RentalPricingService) saveRule(rule *entity.RentalPriceRule, discounts []RentalDiscountInput) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if rule.Active {
+			var n int64
+			if err := ruleScope(tx.Model(&entity.RentalPriceRule{}), rule).
+				Where("active = ? AND id <> ?", true, rule.ID).Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				return ErrRentalRuleConflict
+			}
+		}
+		if err := tx.Save(rule).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("rule_id = ?", rule.ID).Delete(&entity.RentalDiscountTier{}).Error; err != nil {
+			return err
+		}
+		rule.Discounts = nil
+		for _, d := range discounts {
+			rule.Discounts = append(rule.Discounts, entity.RentalDiscountTier{RuleID: rule.ID, MinDays: d.MinDays, Percent: d.Percent})
+		}
+		if len(rule.Discounts) > 0 {
+			return tx.Create(&rule.Discounts).Error
+		}
+		return nil
+	})
+}
+
+// ListRules กฎทั้งหมด (รวมที่ปิดใช้)
+func (s *RentalPricingService) ListRules() ([]entity.RentalPriceRule, error) {
+	rules := []entity.RentalPriceRule{}
+	err := s.db.Preload("Discounts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_days ASC")
+	}).Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (s *RentalPricingService) CreateRule(managerID uint, in RentalRuleInput) (*entity.RentalPriceRule, error) {
+	rule := entity.RentalPriceRule{ManagerID: managerID}
+	if err := s.applyRuleInput(&rule, in); err != nil {
+		return nil, err
+	}
+	if err := s.saveRule(&rule, in.Discounts); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateRule แก้กฎ มีผลกับใบเสนอราคาที่คำนวณหลังจากนี้
+func (s *RentalPricingService) UpdateRule(id uint, in RentalRuleInput) (*entity.RentalPriceRule, error) {
+	var rule entity.RentalPriceRule
+	if err := s.db.First(&rule, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrRentalRuleNotFound)
+	}
+	if err := s.applyRuleInput(&rule, in); err != nil {
+		return nil, err
+	}
+	if err := s.saveRule(&rule, in.Discounts); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *RentalPricingService) DeleteRule(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&entity.RentalPriceRule{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRentalRuleNotFound
+		}
+		return tx.Where("rule_id = ?", id).Delete(&entity.RentalDiscountTier{}).Error
+	})
+}
+
+func applyAdjustmentInput(adj *entity.RentalPriceAdjustment, in RentalAdjustmentInput) error {
+	kind := strings.ToLower(strings.TrimSpace(in.Kind))
+	if kind != entity.RentalAdjustmentHoliday && kind != entity.RentalAdjustmentSeason {
+		return invalidRentalAdjustment("kind must be holiday or season")
+	}
+	start, err := time.Parse("2006-01-02", in.StartDate)
+	if err != nil {
+		return invalidRentalAdjustment("start_date must be YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", in.EndDate)
+	if err != nil || end.Before(start) {
+		return invalidRentalAdjustment("end_date must be YYYY-MM-DD on or after start_date")
+	}
+	if in.Multiplier <= 0 || in.Multiplier > 10 {
+		return invalidRentalAdjustment("multiplier must be greater than 0 and at most 10")
+	}
+	adj.Name = strings.TrimSpace(in.Name)
+	adj.Kind = kind
+	adj.StartDate = start
+	adj.EndDate = end
+	adj.Multiplier = in.Multiplier
+	return nil
+}
+
+// ListAdjustments ตัวคูณทั้งหมด เรียงตามวันเริ่ม
+func (s *RentalPricingService) ListAdjustments() ([]entity.RentalPriceAdjustment, error) {
+	adjs := []entity.RentalPriceAdjustment{}
+	err := s.db.Order("start_date ASC, id ASC").Find(&adjs).Error
+	return adjs, err
+}
+
+func (s *RentalPricingService) CreateAdjustment(in RentalAdjustmentInput) (*entity.RentalPriceAdjustment, error) {
+	var adj entity.RentalPriceAdjustment
+	if err := applyAdjustmentInput(&adj, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&adj).Error; err != nil {
+		return nil, err
+	}
+	return &adj, nil
+}
+
+func (s *RentalPricingService) UpdateAdjustment(id uint, in RentalAdjustmentInput) (*entity.RentalPriceAdjustment, error) {
+	var adj entity.RentalPriceAdjustment
+	if err := s.db.First(&adj, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrRentalAdjustmentNotFound)
+	}
+	if err := applyAdjustmentInput(&adj, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&adj).Error; err != nil {
+		return nil, err
+	}
+	return &adj, nil
+}
+
+func (s *RentalPricingService) DeleteAdjustment(id uint) error {
+	res := s.db.Delete(&entity.RentalPriceAdjustment{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRentalAdjustmentNotFound
+	}
+	return nil
+}
+
+// ruleForCar กฎที่เจาะจงที่สุด: รถคันนี้ > รุ่น > ยี่ห้อ > กฎตั้งต้น (nil = ไม่มีกฎ)
+func (s *RentalPricingService) ruleForCar(car *entity.Car) (*entity.RentalPriceRule, error) {
+	candidates := []*entity.RentalPriceRule{
+		{CarID: &car.ID},
+	}
+	if car.Detail != nil {
+		candidates = append(candidates,
+			&entity.RentalPriceRule{CarModelID: &car.Detail.CarModelID},
+			&entity.RentalPriceRule{BrandID: &car.Detail.BrandID})
+	}
+	candidates = append(candidates, &entity.RentalPriceRule{})
+
+	for _, scope := range candidates {
+		var rule entity.RentalPriceRule
+		err := ruleScope(s.db.Preload("Discounts"), scope).Where("active = ?", true).First(&rule).Error
+		if err == nil {
+			return &rule, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// windowRates ช่วงเปิดให้เช่า (DateforRent) ของรถ ราคาในช่วงใช้เมื่อไม่มีกฎราคา
+func (s *RentalPricingService) windowRates(carID uint, start, end time.Time) ([]entity.DateforRent, error) {
+	var windows []entity.DateforRent
+	err := s.db.Model(&entity.DateforRent{}).
+		Joins("JOIN rent_able_dates ON rent_able_dates.datefor_rent_id = datefor_rents.id AND rent_able_dates.deleted_at IS NULL").
+		Joins("JOIN rent_lists ON rent_lists.id = rent_able_dates.rent_list_id AND rent_lists.deleted_at IS NULL").
+		Where("rent_lists.car_id = ? AND datefor_rents.open_date < ? AND datefor_rents.close_date >= ?", carID, end.AddDate(0, 0, 1), start).
+		Order("datefor_rents.open_date ASC").
+		Find(&windows).Error
+	return windows, err
+}
+
+func sameOrBetween(day, from, to time.Time) bool {
+	d := day.Format("2006-01-02")
+	return d >= from.Format("2006-01-02") && d <= to.Format("2006-01-02")
+}
+
+// carPricer ราคาต่อวันของรถหนึ่งคันในช่วงที่ขอ
+type carPricer struct {
+	rule    *entity.RentalPriceRule // nil = ใช้ราคาช่วงเช่า
+	windows []entity.DateforRent    // ช่วงเปิดให้เช่าที่ทับช่วงที่ขอ
+	adjs    []entity.RentalPriceAdjustment
+}
+
+func (s *RentalPricingService) pricerFor(car *entity.Car, start, end time.Time) (*carPricer, error) {
+	rule, err := s.ruleForCar(car)
+	if err != nil {
+		return nil, err
+	}
+	p := &carPricer{rule: rule}
+	if p.windows, err = s.windowRates(car.ID, start, end); err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("start_date <= ? AND end_date >= ?", end, start).Find(&p.adjs).Error; err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// window ช่วงเช่าที่ครอบวันนั้น (nil = ไม่ได้เปิดให้เช่า)
+func (p *carPricer) window(day time.Time) *entity.DateforRent {
+	for i := range p.windows {
+		if sameOrBetween(day, p.windows[i].OpenDate, p.windows[i].CloseDate) {
+			return &p.windows[i]
+		}
+	}
+	return nil
+}
+
+// line ราคาของวันหนึ่ง false = ไม่มีราคาสำหรับวันนั้น
+func (p *carPricer) line(day time.Time) (RentalQuoteLine, bool) {
+	line := RentalQuoteLine{Date: day.Format("2006-01-02"), DayType: "weekday", Multiplier: 1}
+	weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+	if weekend {
+		line.DayType = "weekend"
+	}
+
+	if p.rule != nil {
+		line.BaseRate = p.rule.WeekdayRate
+		if weekend {
+			line.BaseRate = p.rule.WeekendRate
+		}
+	} else if w := p.window(day); w != nil {
+		line.BaseRate = roundBaht(w.RentPrice)
+	}
+	if line.BaseRate <= 0 {
+		return line, false
+	}
+
+	// ตัวคูณชนิดเดียวกันที่ซ้อนกันใช้ค่าสูงสุด ต่างชนิดคูณกัน (เช่น วันหยุดในช่วง high season)
+	best := map[string]entity.RentalPriceAdjustment{}
+	for _, a := range p.adjs {
+		if sameOrBetween(day, a.StartDate, a.EndDate) && a.Multiplier > best[a.Kind].Multiplier {
+			best[a.Kind] = a
+		}
+	}
+	kinds := make([]string, 0, len(best))
+	for kind := range best {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		line.Multiplier *= best[kind].Multiplier
+		line.Adjustments = append(line.Adjustments, fmt.Sprintf("%s x%g", best[kind].Name, best[kind].Multiplier))
+	}
+	line.Multiplier = math.Round(line.Multiplier*10000) / 10000
+	line.Amount = roundBaht(line.BaseRate * line.Multiplier)
+	return line, true
+}
+
+// parseRentalRange ช่วงวันที่ YYYY-MM-DD ที่นับรวมวันสุดท้าย ยาวไม่เกิน maxDays
+func parseRentalRange(startDate, endDate string, maxDays int) (start, end time.Time, days int, err error) {
+	start, err = time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return start, end, 0, ErrInvalidQuoteRange
+	}
+	end, err = time.Parse("2006-01-02", endDate)
+	if err != nil || end.Before(start) {
+		return start, end, 0, ErrInvalidQuoteRange
+	}
+	days = int(end.Sub(start).Hours()/24) + 1
+	if days > maxDays {
+		return start, end, 0, fmt.Errorf("%w (at most %d days)", ErrInvalidQuoteRange, maxDays)
+	}
+	return start, end, days, nil
+}
+
+// Quote ราคาเช่ารถตั้งแต่ startDate ถึง endDate (นับรวมทั้งสองวัน เหมือนหน้าจองรถ)
+func (s *RentalPricingService) Quote(carID uint, startDate, endDate string) (*RentalQuote, error) {
+	start, end, days, err := parseRentalRange(startDate, endDate, maxQuoteDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var car entity.Car
+	if err := s.db.Preload("Detail").First(&car, carID).Error; err != nil {
+		return nil, notFoundAs(err, ErrRentalCarNotFound)
+	}
+	pricer, err := s.pricerFor(&car, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &RentalQuote{CarID: car.ID, StartDate: startDate, EndDate: endDate, Days: days, Lines: []RentalQuoteLine{}}
+	if rule := pricer.rule; rule != nil {
+		if rule.MinDays > 0 && days < rule.MinDays {
+			return nil, fmt.Errorf("%w (%d days)", ErrRentalBelowMinimum, rule.MinDays)
+		}
+		quote.RuleID = &rule.ID
+		quote.RuleName = rule.Name
+	}
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		// กฎราคาไม่ได้เปิดให้เช่า ทุกวันต้องอยู่ในช่วง DateforRent
+		if pricer.window(day) == nil {
+			return nil, fmt.Errorf("%w (%s)", ErrRentalNotOpen, day.Format("2006-01-02"))
+		}
+		line, ok := pricer.line(day)
+		if !ok {
+			return nil, fmt.Errorf("%w (%s)", ErrRentalPriceNotSet, line.Date)
+		}
+		quote.Subtotal += line.Amount
+		quote.Lines = append(quote.Lines, line)
+	}
+	quote.Subtotal = roundBaht(quote.Subtotal)
+
+	if pricer.rule != nil {
+		for _, tier := range pricer.rule.Discounts {
+			if days >= tier.MinDays && tier.Percent > quote.DiscountPercent {
+				quote.DiscountPercent = tier.Percent
+			}
+		}
+	}
+	quote.Discount = roundBaht(quote.Subtotal * quote.DiscountPercent / 100)
+	quote.Total = roundBaht(quote.Subtotal - quote.Discount)
+	return quote, nil
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+// รายงานสำหรับผู้จัดการ คำนวณด้วย SQL aggregate ทั้งหมด (ไม่โหลดทุกแถวมารวมใน Go)
+// วันที่ใน SQLite เก็บเป็นข้อความตามเขตเวลาที่บันทึก ใช้ substr(…, 1, 10) เพื่อได้วันที่ตามเวลาท้องถิ่นนั้น
+
+const (
+	DefaultReportLimit = 10
+	MaxReportLimit     = 100
+)
+
+var (
+	ErrInvalidReportPeriod = errors.New("period must be day, week, month or year")
+	ErrInvalidReportRange  = errors.New("date_from must not be after date_to")
+)
+
+// ช่วงเวลาที่ใช้จัดกลุ่มยอดขาย
+var reportPeriodExpr = map[string]string{
+	"day":   "substr(sales_contracts.created_at, 1, 10)",
+	"week":  "strftime('%Y-W%W', substr(sales_contracts.created_at, 1, 10))",
+	"month": "substr(sales_contracts.created_at, 1, 7)",
+	"year":  "substr(sales_contracts.created_at, 1, 4)",
+}
+
+// ReportFilter ?date_from=&date_to=&period=&group=&limit=
+// ไม่ระบุวันที่ = 12 เดือนล่าสุดถึงวันนี้
+type ReportFilter struct {
+	DateFrom string `form:"date_from"`
+	DateTo   string `form:"date_to"`
+	Period   string `form:"period"` // day | week | month (ค่าเริ่มต้น) | year
+	Group    string `form:"group"`  // brand | model (ค่าเริ่มต้น)
+	Limit    int    `form:"limit"`
+}
+
+// reportRange ช่วงวันที่ของรายงาน (YYYY-MM-DD) end คือวันถัดจากวันสุดท้าย ใช้เทียบแบบ < end
+type reportRange struct {
+	from, to, end string
+	days          int
+}
+
+func (f ReportFilter) reportRange() (reportRange, error) {
+	y, m, d := time.Now().Date()
+	last := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	var err error
+	if f.DateTo != "" {
+		if last, err = time.Parse("2006-01-02", f.DateTo); err != nil {
+			return reportRange{}, ErrInvalidDateFilter
+		}
+	}
+	first := last.AddDate(-1, 0, 1)
+	if f.DateFrom != "" {
+		if first, err = time.Parse("2006-01-02", f.DateFrom); err != nil {
+			return reportRange{}, ErrInvalidDateFilter
+		}
+	}
+	if first.After(last) {
+		return reportRange{}, ErrInvalidReportRange
+	}
+	return reportRange{
+		from: first.Format("2006-01-02"),
+		to:   last.Format("2006-01-02"),
+		end:  last.AddDate(0, 0, 1).Format("2006-01-02"),
+		days: int(last.Sub(first).Hours()/24) + 1,
+	}, nil
+}
+
+func (f ReportFilter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultReportLimit
+	}
+	if f.Limit > MaxReportLimit {
+		return MaxReportLimit
+	}
+	return f.Limit
+}
+
+type ReportService struct {
+	db *gorm.DB
+}
+
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// สัญญาซื้อขายในช่วงวันที่ พร้อม join ราคาขาย
+func (s *ReportService) salesInRange(r reportRange) *gorm.DB {
+	return s.db.Model(&entity.SalesContract{}).
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id AND sale_lists.deleted_at IS NULL").
+		Where("sales_contracts.created_at >= ? AND sales_contracts.created_at < ?", r.from, r.end)
+}
+
+// ReportSummary ตัวเลขหลักของช่วงเวลา
+type ReportSummary struct {
+	DateFrom           string  `json:"date_from"`
+	DateTo             string  `json:"date_to"`
+	SalesCount         int64   `json:"sales_count"`
+	SalesRevenue       float64 `json:"sales_revenue"`
+	AverageSalePrice   float64 `json:"average_sale_price"`
+	AverageDaysInStock float64 `json:"average_days_in_stock"` // ของรถที่ขายในช่วงนี้
+	CarsInStock        int64   `json:"cars_in_stock"`
+	AverageStockAge    float64 `json:"average_stock_age_days"` // อายุสต็อกของรถที่ยังไม่ขาย ณ วันนี้
+	RentContracts      int64   `json:"rent_contracts"`
+	PaymentsReceived   float64 `json:"payments_received"`
+	OutstandingAmount  float64 `json:"outstanding_amount"` // ยอดค้างชำระของสัญญาซื้อขายทั้งหมด
+}
+
+// Summary ภาพรวมของช่วงเวลา
+func (s *ReportService) Summary(f ReportFilter) (*ReportSummary, error) {
+	rr, err := f.reportRange()
+	if err != nil {
+		return nil, err
+	}
+	r := &ReportSummary{DateFrom: rr.from, DateTo: rr.to}
+
+	var sales struct {
+		Count   int64
+		Revenue float64
+		Average float64
+		Days    float64
+	}
+	if err := s.salesInRange(rr).
+		Joins("JOIN cars ON cars.id = sale_lists.car_id AND cars.deleted_at IS NULL").
+		Select("COUNT(*) AS count, COALESCE(SUM(sale_lists.sale_price), 0) AS revenue, " +
+			"COALESCE(AVG(sale_lists.sale_price), 0) AS average, " +
+			"COALESCE(AVG(CASE WHEN cars.purchase_date > '1900' THEN " + daysBetween("cars.purchase_date", "sales_contracts.created_at") + " END), 0) AS days").
+		Scan(&sales).Error; err != nil {
+		return nil, err
+	}
+	r.SalesCount, r.SalesRevenue, r.AverageSalePrice, r.AverageDaysInStock = sales.Count, sales.Revenue, sales.Average, sales.Days
+
+	var stock struct {
+		Count int64
+		Age   float64
+	}
+	if err := s.db.Model(&entity.Car{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(CASE WHEN purchase_date > '1900' THEN "+daysBetween("cars.purchase_date", "date('now')")+" END), 0) AS age").
+		Where("cars.id NOT IN (?)", soldCarIDs(s.db)).
+		Scan(&stock).Error; err != nil {
+		return nil, err
+	}
+	r.CarsInStock, r.AverageStockAge = stock.Count, stock.Age
+
+	if err := s.db.Model(&entity.RentContract{}).
+		Where("created_at >= ? AND created_at < ?", rr.from, rr.end).
+		Count(&r.RentContracts).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&entity.Payment{}).
+		Select("COALESCE(SUM("+paymentAmountExpr+"), 0)").
+		Where("payments.status = ? AND payments.payment_date >= ? AND payments.payment_date < ?", PaymentStatusPaid, rr.from, rr.end).
+		Scan(&r.PaymentsReceived).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Table("(?) AS o", s.outstandingQuery()).
+		Select("COALESCE(SUM(o.balance), 0)").
+		Scan(&r.OutstandingAmount).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// daysBetween จำนวนวัน (ตามวันที่ท้องถิ่นที่บันทึก) จาก start ถึง end
+func daysBetween(start, end string) string {
+	return "(julianday(substr(" + end + ", 1, 10)) - julianday(substr(" + start + ", 1, 10)))"
+}
+
+// soldCarIDs รถที่มีสัญญาซื้อขายแล้ว
+func soldCarIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&entity.SalesContract{}).
+		Select("sale_lists.car_id").
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id AND sale_lists.deleted_at IS NULL")
+}
+
+// SalesPeriod ยอดขายในแต่ละช่วง
+type SalesPeriod struct {
+	Period  string  `json:"period"`
+	Count   int64   `json:"count"`
+	Revenue float64 `json:"revenue"`
+}
+
+// SalesByPeriod จำนวนและยอดขายแยกตามวัน/สัปดาห์/เดือน/ปี
+func (s *ReportService) SalesByPeriod(f ReportFilter) ([]SalesPeriod, error) {
+	rr, err := f.reportRange()
+	if err != nil {
+		return nil, err
+	}
+	period := f.Period
+	if period == "" {
+		period = "month"
+	}
+	expr, ok := reportPeriodExpr[period]
+	if !ok {
+		return nil, ErrInvalidReportPeriod
+	}
+	rows := []SalesPeriod{}
+	err = s.salesInRange(rr).
+		Select(expr + " AS period, COUNT(*) AS count, COALESCE(SUM(sale_lists.sale_price), 0) AS revenue").
+		Group("period").
+		Order("period ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// TopSeller ยี่ห้อ/รุ่นที่ขายได้มาก
+type TopSeller struct {
+	Brand   string  `json:"brand"`
+	Model   string  `json:"model,omitempty"`
+	Count   int64   `json:"count"`
+	Revenue float64 `json:"revenue"`
+}
+
+// TopSellers ยี่ห้อ (group=brand) หรือยี่ห้อ+รุ่น (group=model) ที่ขายได้มากที่สุด
+func (s *ReportService) TopSellers(f ReportFilter) ([]TopSeller, error) {
+	rr, err := f.reportRange()
+	if err != nil {
+		return nil, err
+	}
+	q := s.salesInRange(rr).
+		Joins("JOIN cars ON cars.id = sale_lists.car_id AND cars.deleted_at IS NULL").
+		Joins("JOIN details ON details.id = cars.detail_id").
+		Joins("JOIN brands ON brands.id = details.brand_id")
+	if f.Group == "brand" {
+		q = q.Select("brands.brand_name AS brand, COUNT(*) AS count, COALESCE(SUM(sale_lists.sale_price), 0) AS revenue").
+			Group("brands.id, brands.brand_name")
+	} else {
+		q = q.Joins("JOIN car_models ON car_models.id = details.car_model_id").
+			Select("brands.brand_name AS brand, car_models.model_name AS model, COUNT(*) AS count, COALESCE(SUM(sale_lists.sale_price), 0) AS revenue").
+			Group("brands.id, brands.brand_name, car_models.id, car_models.model_name")
+	}
+	rows := []TopSeller{}
+	err = q.Order("count DESC, revenue DESC").Limit(f.limit()).Scan(&rows).Error
+	return rows, err
+}
+
+// CarUtilization สัดส่วนวันที่รถถูกเช่าในช่วงเวลา
+type CarUtilization struct {
+	CarID         uint    `json:"car_id"`
+	CarName       string  `json:"car_name"`
+	RentContracts int64   `json:"rent_contracts"`
+	RentedDays    float64 `json:"rented_days"`
+	PeriodDays    int     `json:"period_days"`
+	Utilization   float64 `json:"utilization"` // 0–1
+}
+
+// RentalUtilization อัตราการใช้งานของรถที่เปิดให้เช่า (นับวันที่สัญญาเช่าคาบเกี่ยวกับช่วงเวลา)
+func (s *ReportService) RentalUtilization(f ReportFilter) ([]CarUtilization, error) {
+	rr, err := f.reportRange()
+	if err != nil {
+		return nil, err
+	}
+	// วันเช่าที่อยู่ในช่วง: min(วันสิ้นสุด, วันสุดท้าย) - max(วันเริ่ม, วันแรก) + 1
+	overlap := "MAX(0, MIN(julianday(substr(rent_contracts.date_end, 1, 10)), julianday(?) - 1) - " +
+		"MAX(julianday(substr(rent_contracts.date_start, 1, 10)), julianday(?)) + 1)"
+
+	rows := []CarUtilization{}
+	err = s.db.Model(&entity.Car{}).
+		Select("cars.id AS car_id, cars.car_name, COUNT(rent_contracts.id) AS rent_contracts, "+
+			"COALESCE(SUM("+overlap+"), 0) AS rented_days", rr.end, rr.from).
+		Joins("JOIN rent_lists ON rent_lists.car_id = cars.id AND rent_lists.deleted_at IS NULL").
+		Joins("LEFT JOIN rent_contracts ON rent_contracts.rent_list_id = rent_lists.id AND rent_contracts.deleted_at IS NULL "+
+			"AND rent_contracts.date_start < ? AND rent_contracts.date_end >= ?", rr.end, rr.from).
+		Group("cars.id, cars.car_name").
+		Order("rented_days DESC, cars.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].PeriodDays = rr.days
+		rows[i].Utilization = rows[i].RentedDays / float64(rr.days)
+	}
+	return rows, nil
+}
+
+// OutstandingContract สัญญาซื้อขายที่ยังชำระไม่ครบ
+type OutstandingContract struct {
+	SalesContractID uint    `json:"sales_contract_id"`
+	CustomerID      uint    `json:"customer_id"`
+	CustomerName    string  `json:"customer_name"`
+	CarID           uint    `json:"car_id"`
+	SalePrice       float64 `json:"sale_price"`
+	Paid            float64 `json:"paid"`
+	Balance         float64 `json:"balance"`
+	PendingPayments int64   `json:"pending_payments"` // งวดที่บันทึกไว้แต่ยังไม่ชำระ
+}
+
+// ยอดค้างรายสัญญา: ราคาขาย - ยอดที่ชำระแล้ว
+func (s *ReportService) outstandingQuery() *gorm.DB {
+	paid := "COALESCE(SUM(CASE WHEN payments.status = ? THEN " + paymentAmountExpr + " END), 0)"
+	return s.db.Model(&entity.SalesContract{}).
+		Select("sales_contracts.id AS sales_contract_id, sales_contracts.customer_id, "+
+			"TRIM(COALESCE(customers.first_name, '') || ' ' || COALESCE(customers.last_name, '')) AS customer_name, "+
+			"sale_lists.car_id, sale_lists.sale_price, "+paid+" AS paid, "+
+			"sale_lists.sale_price - "+paid+" AS balance, "+
+			"COUNT(CASE WHEN payments.status <> ? THEN 1 END) AS pending_payments",
+			PaymentStatusPaid, PaymentStatusPaid, PaymentStatusPaid).
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id AND sale_lists.deleted_at IS NULL").
+		Joins("LEFT JOIN customers ON customers.id = sales_contracts.customer_id").
+		Joins("LEFT JOIN payments ON payments.sales_contract_id = sales_contracts.id AND payments.deleted_at IS NULL").
+		Group("sales_contracts.id, sales_contracts.customer_id, customers.first_name, customers.last_name, sale_lists.car_id, sale_lists.sale_price").
+		Having("balance > 0")
+}
+
+// OutstandingPayments สัญญาซื้อขายที่ยังค้างชำระ เรียงตามยอดค้างมากไปน้อย
+func (s *ReportService) OutstandingPayments(f ReportFilter) ([]OutstandingContract, error) {
+	rows := []OutstandingContract{}
+	err := s.outstandingQuery().Order("balance DESC").Limit(f.limit()).Scan(&rows).Error
+	return rows, err
+}
+
+// EmployeePerformance ผลงานการขายของพนักงานในช่วงเวลา
+type EmployeePerformance struct {
+	EmployeeID       uint    `json:"employee_id"`
+	Name             string  `json:"name"`
+	Position         string  `json:"position"`
+	SalesCount       int64   `json:"sales_count"`
+	SalesRevenue     float64 `json:"sales_revenue"`
+	AverageSalePrice float64 `json:"average_sale_price"`
+	PaymentsHandled  int64   `json:"payments_handled"`
+}
+
+// EmployeePerformance ยอดขายและจำนวนการรับชำระของพนักงานทุกคน (รวมคนที่ยังไม่มียอด)
+func (s *ReportService) EmployeePerformance(f ReportFilter) ([]EmployeePerformance, error) {
+	rr, err := f.reportRange()
+	if err != nil {
+		return nil, err
+	}
+	sales := s.salesInRange(rr).
+		Select("sales_contracts.employee_id, COUNT(*) AS sales_count, SUM(sale_lists.sale_price) AS revenue").
+		Group("sales_contracts.employee_id")
+	payments := s.db.Model(&entity.Payment{}).
+		Select("employee_id, COUNT(*) AS handled").
+		Where("payment_date >= ? AND payment_date < ?", rr.from, rr.end).
+		Group("employee_id")
+
+	rows := []EmployeePerformance{}
+	err = s.db.Model(&entity.Employee{}).
+		Select("employees.employee_id, TRIM(employees.first_name || ' ' || employees.last_name) AS name, employees.position, "+
+			"COALESCE(s.sales_count, 0) AS sales_count, COALESCE(s.revenue, 0) AS sales_revenue, "+
+			"COALESCE(s.revenue / s.sales_count, 0) AS average_sale_price, COALESCE(p.handled, 0) AS payments_handled").
+		Joins("LEFT JOIN (?) AS s ON s.employee_id = employees.employee_id", sales).
+		Joins("LEFT JOIN (?) AS p ON p.employee_id = employees.employee_id", payments).
+		Order("sales_revenue DESC, employees.employee_id ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// PopularCar จำนวนลูกค้าที่ถูกใจ/ติดตามราคารถ
+type PopularCar struct {
+	CarID           uint   `json:"car_id"`
+	CarName         string `json:"car_name"`
+	Lifecycle       string `json:"lifecycle"`
+	Favourites      int64  `json:"favourites"` // ถูกใจในช่วงเวลา
+	TotalFavourites int64  `json:"total_favourites"`
+	PriceAlerts     int64  `json:"price_alerts"` // ลูกค้าที่ติดตามราคารถคันนี้อยู่
+}
+
+// PopularCars รถที่ถูกใจมากที่สุดในช่วงเวลา
+func (s *ReportService) PopularCars(f ReportFilter) ([]PopularCar, error) {
+	rr, err := f.reportRange()
+	if err != nil {
+		return nil, err
+	}
+	inRange := "COALESCE(SUM(CASE WHEN favourites.created_at >= ? AND favourites.created_at < ? THEN 1 END), 0)"
+	alerts := "(SELECT COUNT(*) FROM price_alerts WHERE price_alerts.car_id = cars.id AND price_alerts.deleted_at IS NULL)"
+
+	rows := []PopularCar{}
+	err = s.db.Model(&entity.Car{}).
+		Select("cars.id AS car_id, cars.car_name, cars.lifecycle, "+inRange+" AS favourites, "+
+			"COUNT(favourites.id) AS total_favourites, "+alerts+" AS price_alerts", rr.from, rr.end).
+		Joins("JOIN favourites ON favourites.car_id = cars.id").
+		Group("cars.id, cars.car_name, cars.lifecycle").
+		Order("favourites DESC, total_favourites DESC, cars.id ASC").
+		Limit(f.limit()).
+		Scan(&rows).Error
+	return rows, err
+}
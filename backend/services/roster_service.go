@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidShiftTemplate  = errors.New("invalid shift template")
+	ErrShiftTemplateNotFound = errors.New("shift template not found")
+	ErrInvalidWeekStart      = errors.New("week_start must be a Monday in YYYY-MM-DD")
+	ErrRosterNotFound        = errors.New("roster not found")
+	ErrRosterPublished       = errors.New("roster is already published")
+	ErrRosterShiftNotFound   = errors.New("roster shift not found")
+	ErrShiftOutsideWeek      = errors.New("date must fall within the roster week")
+	ErrShiftOverlap          = errors.New("employee already has an overlapping shift")
+)
+
+// ShiftTemplateInput ข้อมูลรูปแบบกะ
+type ShiftTemplateInput struct {
+	Name         string `json:"name" binding:"required"`
+	StartTime    string `json:"start_time" binding:"required"` // HH:MM
+	EndTime      string `json:"end_time" binding:"required"`   // HH:MM
+	BreakMinutes int    `json:"break_minutes"`
+	GraceMinutes int    `json:"grace_minutes"`
+	Active       *bool  `json:"active"` // ไม่ระบุ = เปิดใช้
+}
+
+// RosterShiftInput จัดพนักงานลงกะในวันหนึ่งของสัปดาห์
+type RosterShiftInput struct {
+	EmployeeID      uint   `json:"employee_id" binding:"required"`
+	ShiftTemplateID uint   `json:"shift_template_id" binding:"required"`
+	Date            string `json:"date" binding:"required"` // YYYY-MM-DD
+}
+
+// RosterService รูปแบบกะและตารางกะรายสัปดาห์
+type RosterService struct {
+	db *gorm.DB
+}
+
+func NewRosterService(db *gorm.DB) *RosterService {
+	return &RosterService{db: db}
+}
+
+func (s *RosterService) WithContext(ctx context.Context) *RosterService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidShiftTemplate(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidShiftTemplate, reason)
+}
+
+func applyShiftTemplateInput(tpl *entity.ShiftTemplate, in ShiftTemplateInput) error {
+	start, err := time.Parse("15:04", strings.TrimSpace(in.StartTime))
+	if err != nil {
+		return invalidShiftTemplate("start_time must be HH:MM")
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(in.EndTime))
+	if err != nil {
+		return invalidShiftTemplate("end_time must be HH:MM")
+	}
+	if start.Equal(end) {
+		return invalidShiftTemplate("end_time must differ from start_time")
+	}
+	if in.BreakMinutes < 0 || in.GraceMinutes < 0 {
+		return invalidShiftTemplate("break_minutes and grace_minutes must not be negative")
+	}
+	tpl.Name = strings.TrimSpace(in.Name)
+	tpl.StartTime = start.Format("15:04")
+	tpl.EndTime = end.Format("15:04")
+	tpl.BreakMinutes = in.BreakMinutes
+	tpl.GraceMinutes = in.GraceMinutes
+	tpl.Active = in.Active == nil || *in.Active
+	return nil
+}
+
+// shiftWindow เวลาเริ่ม-เลิกกะจริงของวัน date ตามเวลาท้องถิ่น
+func shiftWindow(date time.Time, tpl entity.ShiftTemplate) (time.Time, time.Time) {
+	s, _ := time.Parse("15:04", tpl.StartTime)
+	e, _ := time.Parse("15:04", tpl.EndTime)
+	y, m, d := date.Date()
+	start := time.Date(y, m, d, s.Hour(), s.Minute(), 0, 0, time.Local)
+	end := time.Date(y, m, d, e.Hour(), e.Minute(), 0, 0, time.Local)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+func (s *RosterService) ListTemplates() ([]entity.ShiftTemplate, error) {
+	var templates []entity.ShiftTemplate
+	err := s.db.Order("start_time ASC, id ASC").Find(&templates).Error
+	return templates, err
+}
+
+func (s *RosterService) CreateTemplate(managerID uint, in ShiftTemplateInput) (*entity.ShiftTemplate, error) {
+	tpl := entity.ShiftTemplate{ManagerID: managerID}
+	if err := applyShiftTemplateInput(&tpl, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&tpl).Error; err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// UpdateTemplate แก้รูปแบบกะ ไม่กระทบกะที่จัดไปแล้ว
+func (s *RosterService) UpdateTemplate(id uint, in ShiftTemplateInput) (*entity.ShiftTemplate, error) {
+	var tpl entity.ShiftTemplate
+	if err := s.db.First(&tpl, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrShiftTemplateNotFound)
+	}
+	if err := applyShiftTemplateInput(&tpl, in); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&tpl).Error; err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+func (s *RosterService) DeleteTemplate(id uint) error {
+	res := s.db.Delete(&entity.ShiftTemplate{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrShiftTemplateNotFound
+	}
+	return nil
+}
+
+func parseWeekStart(v string) (time.Time, error) {
+	d, err := time.Parse("2006-01-02", v)
+	if err != nil || d.Weekday() != time.Monday {
+		return time.Time{}, ErrInvalidWeekStart
+	}
+	return d, nil
+}
+
+// CreateRoster สร้างตารางของสัปดาห์ (ถ้ามีอยู่แล้วคืนตารางเดิม)
+func (s *RosterService) CreateRoster(weekStart string, managerID uint) (*entity.Roster, error) {
+	week, err := parseWeekStart(weekStart)
+	if err != nil {
+		return nil, err
+	}
+	var roster entity.Roster
+	if err := s.db.Where(entity.Roster{WeekStart: week}).
+		Attrs(entity.Roster{Status: entity.RosterStatusDraft, ManagerID: managerID}).
+		FirstOrCreate(&roster).Error; err != nil {
+		return nil, err
+	}
+	return s.GetRoster(roster.ID)
+}
+
+// GetRosterByWeek ตารางของสัปดาห์ที่เริ่มวันจันทร์ weekStart
+func (s *RosterService) GetRosterByWeek(weekStart string) (*entity.Roster, error) {
+	week, err := parseWeekStart(weekStart)
+	if err != nil {
+		return nil, err
+	}
+	var roster entity.Roster
+	if err := s.db.Where("week_start = ?", week).First(&roster).Error; err != nil {
+		return nil, notFoundAs(err, ErrRosterNotFound)
+	}
+	return s.GetRoster(roster.ID)
+}
+
+// GetRoster ตารางพร้อมกะทั้งหมด กะที่ตรงกับวันลาที่อนุมัติแล้วจะถูก flag
+func (s *RosterService) GetRoster(id uint) (*entity.Roster, error) {
+	var roster entity.Roster
+	if err := s.db.Preload("Shifts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC, start_at ASC, employee_id ASC")
+	}).Preload("Shifts.Employee").Preload("Shifts.ShiftTemplate").First(&roster, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrRosterNotFound)
+	}
+	if err := flagLeaveShifts(s.db, roster.Shifts); err != nil {
+		return nil, err
+	}
+	return &roster, nil
+}
+
+func (s *RosterService) draftRoster(tx *gorm.DB, id uint) (*entity.Roster, error) {
+	var roster entity.Roster
+	if err := tx.First(&roster, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrRosterNotFound)
+	}
+	if roster.Status == entity.RosterStatusPublished {
+		return nil, ErrRosterPublished
+	}
+	return &roster, nil
+}
+
+// AddShift จัดกะในตารางที่ยังไม่เผยแพร่ กะของพนักงานคนเดียวกันต้องไม่ทับกัน
+func (s *RosterService) AddShift(rosterID uint, in RosterShiftInput) (*entity.RosterShift, error) {
+	date, err := time.Parse("2006-01-02", in.Date)
+	if err != nil {
+		return nil, err
+	}
+	var shift entity.RosterShift
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		roster, err := s.draftRoster(tx, rosterID)
+		if err != nil {
+			return err
+		}
+		if date.Before(roster.WeekStart) || !date.Before(roster.WeekStart.AddDate(0, 0, 7)) {
+			return ErrShiftOutsideWeek
+		}
+		var tpl entity.ShiftTemplate
+		if err := tx.Where("active = ?", true).First(&tpl, in.ShiftTemplateID).Error; err != nil {
+			return notFoundAs(err, ErrShiftTemplateNotFound)
+		}
+		var emp entity.Employee
+		if err := tx.First(&emp, in.EmployeeID).Error; err != nil {
+			return err
+		}
+
+		start, end := shiftWindow(date, tpl)
+		var overlaps int64
+		if err := tx.Model(&entity.RosterShift{}).
+			Where("employee_id = ? AND start_at < ? AND end_at > ?", in.EmployeeID, end, start).
+			Count(&overlaps).Error; err != nil {
+			return err
+		}
+		if overlaps > 0 {
+			return ErrShiftOverlap
+		}
+
+		shift = entity.RosterShift{
+			RosterID:        roster.ID,
+			EmployeeID:      in.EmployeeID,
+			ShiftTemplateID: tpl.ID,
+			Date:            date,
+			StartAt:         start,
+			EndAt:           end,
+		}
+		if err := tx.Create(&shift).Error; err != nil {
+			return err
+		}
+		shift.ShiftTemplate = &tpl
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	shifts := []entity.RosterShift{shift}
+	if err := flagLeaveShifts(s.db, shifts); err != nil {
+		return nil, err
+	}
+	return &shifts[0], nil
+}
+
+// DeleteShift ลบกะออกจากตารางที่ยังไม่เผยแพร่
+func (s *RosterService) DeleteShift(rosterID, shiftID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.draftRoster(tx, rosterID); err != nil {
+			return err
+		}
+		res := tx.Where("roster_id = ?", rosterID).Delete(&entity.RosterShift{}, shiftID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRosterShiftNotFound
+		}
+		return nil
+	})
+}
+
+// Publish เผยแพร่ตารางให้พนักงานเห็นและใช้ลงเวลาได้ หลังเผยแพร่แก้ไขกะไม่ได้
+func (s *RosterService) Publish(rosterID, managerID uint) (*entity.Roster, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		roster, err := s.draftRoster(tx, rosterID)
+		if err != nil {
+			return err
+		}
+		return tx.Model(roster).Updates(map[string]interface{}{
+			"status":          entity.RosterStatusPublished,
+			"published_at":    time.Now(),
+			"published_by_id": managerID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRoster(rosterID)
+}
+
+// EmployeeShifts กะของพนักงานจากตารางที่เผยแพร่แล้ว ตั้งแต่ from ถึง to (YYYY-MM-DD)
+func (s *RosterService) EmployeeShifts(empID uint, from, to string) ([]entity.RosterShift, error) {
+	q := s.db.Model(&entity.RosterShift{}).
+		Joins("JOIN rosters ON rosters.id = roster_shifts.roster_id AND rosters.deleted_at IS NULL").
+		Where("roster_shifts.employee_id = ? AND rosters.status = ?", empID, entity.RosterStatusPublished)
+	q, err := applyDateRange(q, "roster_shifts.date", from, to)
+	if err != nil {
+		return nil, err
+	}
+	shifts := []entity.RosterShift{}
+	if err := q.Preload("ShiftTemplate").Order("roster_shifts.start_at ASC").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	if err := flagLeaveShifts(s.db, shifts); err != nil {
+		return nil, err
+	}
+	return shifts, nil
+}
+
+// flagLeaveShifts ทำเครื่องหมายกะที่ตรงกับวันลาที่อนุมัติแล้วของพนักงาน
+func flagLeaveShifts(db *gorm.DB, shifts []entity.RosterShift) error {
+	if len(shifts) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(shifts))
+	first, last := shifts[0].Date, shifts[0].Date
+	for _, sh := range shifts {
+		ids = append(ids, sh.EmployeeID)
+		if sh.Date.Before(first) {
+			first = sh.Date
+		}
+		if sh.Date.After(last) {
+			last = sh.Date
+		}
+	}
+	var leaves []entity.LeaveRequest
+	if err := db.Where("employee_id IN ? AND status = ? AND start_date <= ? AND end_date >= ?",
+		ids, entity.LeaveStatusApproved, last, first).Find(&leaves).Error; err != nil {
+		return err
+	}
+	for i := range shifts {
+		day := shifts[i].Date.Format("2006-01-02")
+		for _, l := range leaves {
+			if l.EmployeeID == shifts[i].EmployeeID &&
+				l.StartDate.Format("2006-01-02") <= day && day <= l.EndDate.Format("2006-01-02") {
+				shifts[i].OnLeave = true
+				shifts[i].LeaveID = l.LeaveID
+				break
+			}
+		}
+	}
+	return nil
+}
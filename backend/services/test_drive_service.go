@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTestDriveNotFound      = errors.New("test drive not found")
+	ErrInvalidTestDrive       = errors.New("invalid test drive")
+	ErrTestDriveUnavailable   = errors.New("car is not available for this slot")
+	ErrTestDriveTransition    = errors.New("test drive cannot change to this status")
+	ErrTestDriveListingClosed = errors.New("sale list is not open for test drives")
+	ErrTestDriveEmployeeBusy  = errors.New("employee already has a test drive in this slot")
+)
+
+// ช่วงเวลาให้ทดลองขับ (เวลาท้องถิ่น) แบ่งเป็นช่องละ testDriveSlotLength
+const (
+	testDriveOpenHour   = 9
+	testDriveCloseHour  = 18
+	testDriveSlotLength = time.Hour
+	// การส่งมอบรถถือว่ากันรถไว้ 2 ชั่วโมงนับจากเวลานัด
+	deliveryBlockLength = 2 * time.Hour
+	// สถานะยกเลิกของ PickupDelivery (ค่าที่หน้าเว็บส่งมา)
+	pickupDeliveryCancelled = "ยกเลิก"
+)
+
+// นัดที่ยังกันรถ/พนักงานไว้
+var activeTestDriveStatuses = []string{entity.TestDriveRequested, entity.TestDriveConfirmed}
+
+// การเปลี่ยนสถานะที่อนุญาต
+var testDriveTransitions = map[string][]string{
+	entity.TestDriveRequested: {entity.TestDriveConfirmed, entity.TestDriveCancelled},
+	entity.TestDriveConfirmed: {entity.TestDriveCompleted, entity.TestDriveNoShow, entity.TestDriveCancelled},
+}
+
+// TestDriveInput ลูกค้าขอนัดทดลองขับ
+type TestDriveInput struct {
+	SaleListID    uint   `json:"sale_list_id" binding:"required"`
+	StartAt       string `json:"start_at" binding:"required"`       // RFC3339 หรือ YYYY-MM-DD HH:MM (เวลาท้องถิ่น)
+	LicenseNumber string `json:"license_number" binding:"required"` // เลขใบขับขี่
+	LicenseExpiry string `json:"license_expiry" binding:"required"` // YYYY-MM-DD
+	Note          string `json:"note"`
+}
+
+// TestDriveStatusInput เปลี่ยนสถานะนัด
+type TestDriveStatusInput struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
+}
+
+// TestDriveFilter ?status=&employee_id=&sale_list_id=&date=YYYY-MM-DD
+type TestDriveFilter struct {
+	Status     string `form:"status"`
+	EmployeeID uint   `form:"employee_id"`
+	SaleListID uint   `form:"sale_list_id"`
+	Date       string `form:"date"`
+}
+
+// TestDriveSlot ช่องเวลาหนึ่งของวัน พร้อมเหตุผลถ้าจองไม่ได้
+type TestDriveSlot struct {
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+	Available bool      `json:"available"`
+	Reason    string    `json:"reason,omitempty"` // past, test_drive, rental, delivery
+}
+
+// TestDriveService นัดทดลองขับรถที่ประกาศขาย
+type TestDriveService struct {
+	db *gorm.DB
+}
+
+func NewTestDriveService(db *gorm.DB) *TestDriveService {
+	return &TestDriveService{db: db}
+}
+
+func (s *TestDriveService) WithContext(ctx context.Context) *TestDriveService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidTestDrive(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidTestDrive, reason)
+}
+
+func parseLocalDay(v string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", strings.TrimSpace(v), time.Local)
+}
+
+// openSaleList ประกาศขายที่ยังไม่ถูกขาย
+func openSaleList(tx *gorm.DB, id uint) (*entity.SaleList, error) {
+	var sale entity.SaleList
+	if err := tx.First(&sale, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrTestDriveListingClosed)
+	}
+	if sale.Status != entity.SaleListAvailable {
+		return nil, ErrTestDriveListingClosed
+	}
+	return &sale, nil
+}
+
+// carConflict คืนเหตุผลถ้ารถไม่ว่างในช่วง [start, end) ("" = ว่าง)
+func carConflict(tx *gorm.DB, carID uint, start, end time.Time, excludeID uint) (string, error) {
+	var n int64
+	if err := tx.Model(&entity.TestDrive{}).
+		Where("car_id = ? AND id <> ? AND status IN ? AND start_at < ? AND end_at > ?",
+			carID, excludeID, activeTestDriveStatuses, end, start).
+		Count(&n).Error; err != nil {
+		return "", err
+	}
+	if n > 0 {
+		return "test_drive", nil
+	}
+
+	// สัญญาเช่ากันรถทั้งวันตั้งแต่วันเริ่มถึงวันสิ้นสุด
+	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	if err := tx.Model(&entity.RentContract{}).
+		Joins("JOIN rent_lists ON rent_lists.id = rent_contracts.rent_list_id").
+		Where("rent_lists.car_id = ? AND rent_contracts.date_start < ? AND rent_contracts.date_end >= ?",
+			carID, end, dayStart).
+		Count(&n).Error; err != nil {
+		return "", err
+	}
+	if n > 0 {
+		return "rental", nil
+	}
+
+	if err := tx.Model(&entity.PickupDelivery{}).
+		Joins("JOIN sales_contracts ON sales_contracts.id = pickup_deliveries.sales_contract_id").
+		Joins("JOIN sale_lists ON sale_lists.id = sales_contracts.sale_list_id").
+		Where("sale_lists.car_id = ? AND pickup_deliveries.status <> ? AND pickup_deliveries.date_time < ? AND pickup_deliveries.date_time > ?",
+			carID, pickupDeliveryCancelled, end, start.Add(-deliveryBlockLength)).
+		Count(&n).Error; err != nil {
+		return "", err
+	}
+	if n > 0 {
+		return "delivery", nil
+	}
+
+	block, err := MaintenanceBlock(tx, carID, start, end)
+	if err != nil {
+		return "", err
+	}
+	if block != nil {
+		return "maintenance", nil
+	}
+	return "", nil
+}
+
+// Slots ช่องเวลาทดลองขับของประกาศขายในวันที่กำหนด
+func (s *TestDriveService) Slots(saleListID uint, date string) ([]TestDriveSlot, error) {
+	day, err := parseLocalDay(date)
+	if err != nil {
+		return nil, invalidTestDrive("date must be YYYY-MM-DD")
+	}
+	sale, err := openSaleList(s.db, saleListID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slots := []TestDriveSlot{}
+	open := day.Add(testDriveOpenHour * time.Hour)
+	closeAt := day.Add(testDriveCloseHour * time.Hour)
+	for start := open; !start.Add(testDriveSlotLength).After(closeAt); start = start.Add(testDriveSlotLength) {
+		slot := TestDriveSlot{StartAt: start, EndAt: start.Add(testDriveSlotLength)}
+		if !start.After(now) {
+			slot.Reason = "past"
+		} else if slot.Reason, err = carConflict(s.db, sale.CarID, slot.StartAt, slot.EndAt, 0); err != nil {
+			return nil, err
+		}
+		slot.Available = slot.Reason == ""
+		slots = append(slots, slot)
+	}
+	return slots, nil
+}
+
+// validSlot เวลาเริ่มต้องตรงช่องในเวลาทำการและยังไม่ผ่านไป
+func validSlot(start time.Time) error {
+	local := start.In(time.Local)
+	if local.Minute() != 0 || local.Second() != 0 ||
+		local.Hour() < testDriveOpenHour || local.Add(testDriveSlotLength).Hour() > testDriveCloseHour ||
+		local.Add(testDriveSlotLength).Day() != local.Day() {
+		return invalidTestDrive(fmt.Sprintf("start_at must be on the hour between %02d:00 and %02d:00",
+			testDriveOpenHour, testDriveCloseHour-int(testDriveSlotLength/time.Hour)))
+	}
+	if !start.After(time.Now()) {
+		return invalidTestDrive("start_at must be in the future")
+	}
+	return nil
+}
+
+// Book ลูกค้าจองช่องเวลาทดลองขับ ใบขับขี่ต้องยังไม่หมดอายุในวันนัด
+func (s *TestDriveService) Book(customerID uint, in TestDriveInput) (*entity.TestDrive, error) {
+	start, err := parseLocalDateTime(in.StartAt)
+	if err != nil {
+		return nil, invalidTestDrive("start_at must be RFC3339 or YYYY-MM-DD HH:MM")
+	}
+	if err := validSlot(start); err != nil {
+		return nil, err
+	}
+	license := strings.ToUpper(strings.TrimSpace(in.LicenseNumber))
+	if license == "" {
+		return nil, invalidTestDrive("license_number is required")
+	}
+	expiry, err := parseLocalDay(in.LicenseExpiry)
+	if err != nil {
+		return nil, invalidTestDrive("license_expiry must be YYYY-MM-DD")
+	}
+	if expiry.Before(time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)) {
+		return nil, invalidTestDrive("driving licence expires before the test drive")
+	}
+
+	end := start.Add(testDriveSlotLength)
+	var drive entity.TestDrive
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		sale, err := openSaleList(tx, in.SaleListID)
+		if err != nil {
+			return err
+		}
+		reason, err := carConflict(tx, sale.CarID, start, end, 0)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return fmt.Errorf("%w (%s)", ErrTestDriveUnavailable, reason)
+		}
+		var mine int64
+		if err := tx.Model(&entity.TestDrive{}).
+			Where("customer_id = ? AND status IN ? AND start_at < ? AND end_at > ?",
+				customerID, activeTestDriveStatuses, end, start).
+			Count(&mine).Error; err != nil {
+			return err
+		}
+		if mine > 0 {
+			return invalidTestDrive("you already have a test drive in this slot")
+		}
+
+		drive = entity.TestDrive{
+			SaleListID:    sale.ID,
+			CarID:         sale.CarID,
+			CustomerID:    customerID,
+			StartAt:       start,
+			EndAt:         end,
+			LicenseNumber: license,
+			LicenseExpiry: expiry,
+			Status:        entity.TestDriveRequested,
+			Note:          strings.TrimSpace(in.Note),
+		}
+		return tx.Create(&drive).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(drive.ID)
+}
+
+func (s *TestDriveService) preload() *gorm.DB {
+	return s.db.Preload("SaleList.Car").Preload("Customer").Preload("Employee")
+}
+
+// Get นัดทดลองขับพร้อมรถ ลูกค้า และพนักงาน
+func (s *TestDriveService) Get(id uint) (*entity.TestDrive, error) {
+	var drive entity.TestDrive
+	if err := s.preload().First(&drive, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrTestDriveNotFound)
+	}
+	return &drive, nil
+}
+
+// List นัดทั้งหมดตามตัวกรอง (ฝั่งร้าน)
+func (s *TestDriveService) List(f TestDriveFilter) ([]entity.TestDrive, error) {
+	q := s.preload().Order("start_at ASC")
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.EmployeeID != 0 {
+		q = q.Where("employee_id = ?", f.EmployeeID)
+	}
+	if f.SaleListID != 0 {
+		q = q.Where("sale_list_id = ?", f.SaleListID)
+	}
+	if f.Date != "" {
+		day, err := parseLocalDay(f.Date)
+		if err != nil {
+			return nil, ErrInvalidDateFilter
+		}
+		q = q.Where("start_at >= ? AND start_at < ?", day, day.AddDate(0, 0, 1))
+	}
+	drives := []entity.TestDrive{}
+	return drives, q.Find(&drives).Error
+}
+
+// CustomerDrives นัดของลูกค้าคนหนึ่ง ล่าสุดก่อน
+func (s *TestDriveService) CustomerDrives(customerID uint) ([]entity.TestDrive, error) {
+	drives := []entity.TestDrive{}
+	err := s.preload().Where("customer_id = ?", customerID).Order("start_at DESC").Find(&drives).Error
+	return drives, err
+}
+
+// Assign กำหนดพนักงานพาทดลองขับ พนักงานต้องไม่มีนัดอื่นทับช่วงเวลา
+// พนักงานรับนัดให้ตัวเองได้เท่านั้น ผู้จัดการกำหนดให้ใครก็ได้
+func (s *TestDriveService) Assign(actor StaffActor, id, employeeID uint) (*entity.TestDrive, error) {
+	if !actor.IsManager() {
+		employeeID = actor.EmployeeID
+	}
+	if employeeID == 0 {
+		return nil, invalidTestDrive("employee_id is required")
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var drive entity.TestDrive
+		if err := tx.First(&drive, id).Error; err != nil {
+			return notFoundAs(err, ErrTestDriveNotFound)
+		}
+		if drive.Status != entity.TestDriveRequested && drive.Status != entity.TestDriveConfirmed {
+			return ErrTestDriveTransition
+		}
+		ok, err := recordExists(tx, &entity.Employee{}, "employee_id = ?", employeeID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidTestDrive("employee does not exist")
+		}
+		var busy int64
+		if err := tx.Model(&entity.TestDrive{}).
+			Where("employee_id = ? AND id <> ? AND status IN ? AND start_at < ? AND end_at > ?",
+				employeeID, id, activeTestDriveStatuses, drive.EndAt, drive.StartAt).
+			Count(&busy).Error; err != nil {
+			return err
+		}
+		if busy > 0 {
+			return ErrTestDriveEmployeeBusy
+		}
+		return tx.Model(&drive).Update("employee_id", employeeID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// ChangeStatus เดินสถานะ requested -> confirmed -> completed/no_show (ยกเลิกได้ก่อนจบ)
+// ยืนยันได้เมื่อมีพนักงานแล้ว และสรุปผลได้หลังถึงเวลานัด
+func (s *TestDriveService) ChangeStatus(id uint, in TestDriveStatusInput) (*entity.TestDrive, error) {
+	next := strings.ToLower(strings.TrimSpace(in.Status))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var drive entity.TestDrive
+		if err := tx.First(&drive, id).Error; err != nil {
+			return notFoundAs(err, ErrTestDriveNotFound)
+		}
+		if !containsString(testDriveTransitions[drive.Status], next) {
+			return fmt.Errorf("%w: %s -> %s", ErrTestDriveTransition, drive.Status, next)
+		}
+		switch next {
+		case entity.TestDriveConfirmed:
+			if drive.EmployeeID == nil {
+				return invalidTestDrive("assign an employee before confirming")
+			}
+			// ตรวจซ้ำ เผื่อมีการเช่า/ส่งมอบเกิดขึ้นหลังลูกค้าจอง
+			reason, err := carConflict(tx, drive.CarID, drive.StartAt, drive.EndAt, drive.ID)
+			if err != nil {
+				return err
+			}
+			if reason != "" {
+				return fmt.Errorf("%w (%s)", ErrTestDriveUnavailable, reason)
+			}
+		case entity.TestDriveCompleted, entity.TestDriveNoShow:
+			if time.Now().Before(drive.StartAt) {
+				return invalidTestDrive("cannot record the outcome before the appointment starts")
+			}
+		}
+		if err := tx.Model(&drive).Updates(map[string]interface{}{
+			"status":      next,
+			"status_note": strings.TrimSpace(in.Note),
+		}).Error; err != nil {
+			return err
+		}
+		if next != entity.TestDriveConfirmed {
+			return nil
+		}
+		var employee entity.Employee
+		if err := tx.Select("first_name", "last_name").First(&employee, *drive.EmployeeID).Error; err != nil {
+			return err
+		}
+		return Notify(tx, NotificationInput{
+			Role:   entity.AuditRoleCustomer,
+			UserID: drive.CustomerID,
+			Event:  entity.NotificationTestDriveConfirmed,
+			CarID:  drive.CarID,
+			Data: map[string]interface{}{
+				"StartAt":  drive.StartAt,
+				"Employee": strings.TrimSpace(employee.FirstName + " " + employee.LastName),
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// CancelByCustomer ลูกค้ายกเลิกนัดของตัวเองก่อนถึงเวลา
+func (s *TestDriveService) CancelByCustomer(customerID, id uint) (*entity.TestDrive, error) {
+	var drive entity.TestDrive
+	if err := s.db.Where("customer_id = ?", customerID).First(&drive, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrTestDriveNotFound)
+	}
+	if !drive.StartAt.After(time.Now()) {
+		return nil, invalidTestDrive("appointment has already started")
+	}
+	return s.ChangeStatus(id, TestDriveStatusInput{Status: entity.TestDriveCancelled, Note: "cancelled by customer"})
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
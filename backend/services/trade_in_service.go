@@ -0,0 +1,474 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PanuAutawo/CarTentManagement/backend/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTradeInNotFound        = errors.New("trade-in not found")
+	ErrInvalidTradeIn         = errors.New("invalid trade-in")
+	ErrTradeInState           = errors.New("trade-in is not in a state that allows this action")
+	ErrTradeInOfferExpired    = errors.New("trade-in offer has expired")
+	ErrTradeInExceedsBalance  = errors.New("trade-in value exceeds the contract's outstanding balance")
+	ErrTradeInContractInvalid = errors.New("sales contract does not belong to this customer")
+)
+
+const (
+	// ข้อเสนอของผู้จัดการมีอายุ 7 วัน
+	tradeInOfferValidity = 7 * 24 * time.Hour
+	// ช่องทางชำระที่ใช้บันทึกมูลค่ารถเทิร์นใน Payment
+	tradeInPaymentMethod = "รถเทิร์น"
+)
+
+var tradeInConditions = map[string]bool{
+	entity.TradeInConditionGood: true,
+	entity.TradeInConditionFair: true,
+	entity.TradeInConditionPoor: true,
+}
+
+// TradeInInput ข้อมูลรถที่ลูกค้าส่งมาให้ประเมิน
+type TradeInInput struct {
+	BrandName              string  `json:"brand_name" binding:"required"`
+	ModelName              string  `json:"model_name" binding:"required"`
+	SubModelName           string  `json:"sub_model_name"`
+	YearManufacture        int     `json:"year_manufacture" binding:"required"`
+	Color                  string  `json:"color"`
+	Mileage                int     `json:"mileage"`
+	VIN                    string  `json:"vin"`
+	EngineNumber           string  `json:"engine_number"`
+	LicensePlate           string  `json:"license_plate"`
+	RegistrationProvinceID uint    `json:"registration_province_id"`
+	Description            string  `json:"description"`
+	AskingPrice            float64 `json:"asking_price"`
+}
+
+// TradeInAppraisalItemInput ผลตรวจหนึ่งระบบ
+type TradeInAppraisalItemInput struct {
+	CarSystemID uint    `json:"car_system_id" binding:"required"`
+	Condition   string  `json:"condition" binding:"required"`
+	Deduction   float64 `json:"deduction"`
+	Note        string  `json:"note"`
+}
+
+// TradeInAppraisalInput ผลประเมินต้องครบทุกระบบใน CarSystem
+type TradeInAppraisalInput struct {
+	AppraisedValue float64                     `json:"appraised_value" binding:"required"`
+	Note           string                      `json:"note"`
+	Items          []TradeInAppraisalItemInput `json:"items" binding:"required"`
+}
+
+type TradeInOfferInput struct {
+	OfferAmount float64 `json:"offer_amount" binding:"required"`
+	Note        string  `json:"note"`
+}
+
+// TradeInFilter ?status=&customer_id=
+type TradeInFilter struct {
+	Status     string `form:"status"`
+	CustomerID uint   `form:"customer_id"`
+}
+
+// TradeInService รับประเมินรถเทิร์น อนุมัติราคา และรับรถเข้าสต็อก
+type TradeInService struct {
+	db        *gorm.DB
+	inventory *CarInventoryService
+}
+
+func NewTradeInService(db *gorm.DB) *TradeInService {
+	return &TradeInService{db: db, inventory: NewCarInventoryService(db)}
+}
+
+func (s *TradeInService) WithContext(ctx context.Context) *TradeInService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func invalidTradeIn(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidTradeIn, reason)
+}
+
+func (s *TradeInService) preload() *gorm.DB {
+	return s.db.Preload("Photos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Preload("Items.CarSystem").Preload("Customer").Preload("Employee")
+}
+
+// Submit ลูกค้าส่งรถเข้าประเมิน
+func (s *TradeInService) Submit(customerID uint, in TradeInInput) (*entity.TradeIn, error) {
+	if in.YearManufacture < 1950 || in.YearManufacture > time.Now().Year()+1 {
+		return nil, invalidTradeIn("year_manufacture is out of range")
+	}
+	if in.Mileage < 0 || in.AskingPrice < 0 {
+		return nil, invalidTradeIn("mileage and asking_price must not be negative")
+	}
+	vin := NormalizeVIN(in.VIN)
+	if vin != "" {
+		if err := ValidateVIN(vin); err != nil {
+			return nil, err
+		}
+	}
+	if in.RegistrationProvinceID != 0 {
+		ok, err := recordExists(s.db, &entity.Province{}, "id = ?", in.RegistrationProvinceID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, invalidTradeIn("registration province does not exist")
+		}
+	}
+
+	trade := entity.TradeIn{
+		CustomerID:             customerID,
+		BrandName:              strings.TrimSpace(in.BrandName),
+		ModelName:              strings.TrimSpace(in.ModelName),
+		SubModelName:           strings.TrimSpace(in.SubModelName),
+		YearManufacture:        in.YearManufacture,
+		Color:                  strings.TrimSpace(in.Color),
+		Mileage:                in.Mileage,
+		VIN:                    vin,
+		EngineNumber:           NormalizeEngineNumber(in.EngineNumber),
+		LicensePlate:           NormalizePlate(in.LicensePlate),
+		RegistrationProvinceID: in.RegistrationProvinceID,
+		Description:            strings.TrimSpace(in.Description),
+		AskingPrice:            in.AskingPrice,
+		Status:                 entity.TradeInSubmitted,
+	}
+	if err := s.db.Create(&trade).Error; err != nil {
+		return nil, err
+	}
+	return s.Get(trade.ID)
+}
+
+// AddPhotos ต่อท้ายรูปของรถเทิร์นที่ยังไม่ปิดเรื่อง
+func (s *TradeInService) AddPhotos(customerID, id uint, paths []string) ([]entity.TradeInPhoto, error) {
+	var photos []entity.TradeInPhoto
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		trade, err := s.customerTradeIn(tx, customerID, id)
+		if err != nil {
+			return err
+		}
+		if trade.Status != entity.TradeInSubmitted && trade.Status != entity.TradeInAppraised {
+			return ErrTradeInState
+		}
+		var maxOrder int
+		if err := tx.Model(&entity.TradeInPhoto{}).Where("trade_in_id = ?", id).
+			Select("COALESCE(MAX(sort_order), 0)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		for i, p := range paths {
+			photos = append(photos, entity.TradeInPhoto{TradeInID: id, Path: p, SortOrder: maxOrder + i + 1})
+		}
+		return tx.Create(&photos).Error
+	})
+	return photos, err
+}
+
+func (s *TradeInService) customerTradeIn(tx *gorm.DB, customerID, id uint) (*entity.TradeIn, error) {
+	var trade entity.TradeIn
+	if err := tx.Where("customer_id = ?", customerID).First(&trade, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrTradeInNotFound)
+	}
+	return &trade, nil
+}
+
+// Get รถเทิร์นพร้อมรูปและผลประเมิน
+func (s *TradeInService) Get(id uint) (*entity.TradeIn, error) {
+	var trade entity.TradeIn
+	if err := s.preload().First(&trade, id).Error; err != nil {
+		return nil, notFoundAs(err, ErrTradeInNotFound)
+	}
+	return &trade, nil
+}
+
+// GetForCustomer ลูกค้าดูได้เฉพาะของตัวเอง
+func (s *TradeInService) GetForCustomer(customerID, id uint) (*entity.TradeIn, error) {
+	if _, err := s.customerTradeIn(s.db, customerID, id); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// List รายการรถเทิร์น ล่าสุดก่อน
+func (s *TradeInService) List(f TradeInFilter) ([]entity.TradeIn, error) {
+	q := s.preload().Order("created_at DESC")
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.CustomerID != 0 {
+		q = q.Where("customer_id = ?", f.CustomerID)
+	}
+	trades := []entity.TradeIn{}
+	return trades, q.Find(&trades).Error
+}
+
+// Appraise บันทึกผลประเมินตามรายการ CarSystem (ประเมินซ้ำได้จนกว่าผู้จัดการจะเสนอราคา)
+func (s *TradeInService) Appraise(actor StaffActor, id uint, in TradeInAppraisalInput) (*entity.TradeIn, error) {
+	if in.AppraisedValue <= 0 {
+		return nil, invalidTradeIn("appraised_value must be greater than 0")
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var trade entity.TradeIn
+		if err := tx.First(&trade, id).Error; err != nil {
+			return notFoundAs(err, ErrTradeInNotFound)
+		}
+		if trade.Status != entity.TradeInSubmitted && trade.Status != entity.TradeInAppraised {
+			return ErrTradeInState
+		}
+
+		var systemIDs []uint
+		if err := tx.Model(&entity.CarSystem{}).Pluck("id", &systemIDs).Error; err != nil {
+			return err
+		}
+		pending := make(map[uint]bool, len(systemIDs))
+		for _, sid := range systemIDs {
+			pending[sid] = true
+		}
+		items := make([]entity.TradeInAppraisalItem, 0, len(in.Items))
+		for _, item := range in.Items {
+			if !pending[item.CarSystemID] {
+				return invalidTradeIn(fmt.Sprintf("car_system_id %d is unknown or listed twice", item.CarSystemID))
+			}
+			delete(pending, item.CarSystemID)
+			cond := strings.ToLower(strings.TrimSpace(item.Condition))
+			if !tradeInConditions[cond] {
+				return invalidTradeIn("condition must be good, fair or poor")
+			}
+			if item.Deduction < 0 {
+				return invalidTradeIn("deduction must not be negative")
+			}
+			items = append(items, entity.TradeInAppraisalItem{
+				TradeInID:   id,
+				CarSystemID: item.CarSystemID,
+				Condition:   cond,
+				Deduction:   roundBaht(item.Deduction),
+				Note:        strings.TrimSpace(item.Note),
+			})
+		}
+		if len(pending) > 0 {
+			return invalidTradeIn("every car system in the checklist must be appraised")
+		}
+
+		if err := tx.Unscoped().Where("trade_in_id = ?", id).Delete(&entity.TradeInAppraisalItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			if err := tx.Create(&items).Error; err != nil {
+				return err
+			}
+		}
+		// ผู้จัดการประเมินเองได้ แต่ผู้ประเมินที่บันทึกไว้ต้องเป็นพนักงาน
+		updates := map[string]interface{}{
+			"appraised_value": roundBaht(in.AppraisedValue),
+			"appraisal_note":  strings.TrimSpace(in.Note),
+			"appraised_at":    time.Now(),
+			"status":          entity.TradeInAppraised,
+		}
+		if !actor.IsManager() {
+			updates["employee_id"] = actor.EmployeeID
+		}
+		return tx.Model(&trade).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Offer ผู้จัดการอนุมัติราคาเสนอซื้อหลังประเมินแล้ว
+func (s *TradeInService) Offer(managerID, id uint, in TradeInOfferInput) (*entity.TradeIn, error) {
+	if in.OfferAmount <= 0 {
+		return nil, invalidTradeIn("offer_amount must be greater than 0")
+	}
+	now := time.Now()
+	expires := now.Add(tradeInOfferValidity)
+	res := s.db.Model(&entity.TradeIn{}).
+		Where("id = ? AND status = ?", id, entity.TradeInAppraised).
+		Updates(map[string]interface{}{
+			"manager_id":       managerID,
+			"offer_amount":     roundBaht(in.OfferAmount),
+			"offer_note":       strings.TrimSpace(in.Note),
+			"offered_at":       now,
+			"offer_expires_at": expires,
+			"status":           entity.TradeInOffered,
+		})
+	if err := s.stateResult(res, id); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Reject ร้านไม่รับซื้อ (ก่อนลูกค้าตอบรับ)
+func (s *TradeInService) Reject(id uint, reason string) (*entity.TradeIn, error) {
+	res := s.db.Model(&entity.TradeIn{}).
+		Where("id = ? AND status IN ?", id,
+			[]string{entity.TradeInSubmitted, entity.TradeInAppraised, entity.TradeInOffered}).
+		Updates(map[string]interface{}{"status": entity.TradeInRejected, "reason_closed": strings.TrimSpace(reason)})
+	if err := s.stateResult(res, id); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Decline ลูกค้าปฏิเสธข้อเสนอ หรือถอนคำขอก่อนได้ข้อเสนอ
+func (s *TradeInService) Decline(customerID, id uint, reason string) (*entity.TradeIn, error) {
+	trade, err := s.customerTradeIn(s.db, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+	next := entity.TradeInWithdrawn
+	switch trade.Status {
+	case entity.TradeInOffered:
+		next = entity.TradeInDeclined
+	case entity.TradeInSubmitted, entity.TradeInAppraised:
+	default:
+		return nil, ErrTradeInState
+	}
+	res := s.db.Model(&entity.TradeIn{}).Where("id = ? AND status = ?", id, trade.Status).
+		Updates(map[string]interface{}{"status": next, "reason_closed": strings.TrimSpace(reason)})
+	if err := s.stateResult(res, id); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// แยกกรณีไม่พบกับสถานะไม่ถูกต้องเมื่ออัปเดตแบบมีเงื่อนไขสถานะ
+func (s *TradeInService) stateResult(res *gorm.DB, id uint) error {
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+	ok, err := recordExists(s.db, &entity.TradeIn{}, "id = ?", id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTradeInNotFound
+	}
+	return ErrTradeInState
+}
+
+// Accept ลูกค้ารับข้อเสนอ: รถเข้าสต็อกเป็น Car คันใหม่ และมูลค่าถูกบันทึกเป็นการชำระในสัญญาซื้อขายของลูกค้า
+func (s *TradeInService) Accept(customerID, id, salesContractID uint) (*entity.TradeIn, error) {
+	var carID uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		trade, err := s.customerTradeIn(tx, customerID, id)
+		if err != nil {
+			return err
+		}
+		if trade.Status != entity.TradeInOffered {
+			return ErrTradeInState
+		}
+		if trade.OfferExpiresAt != nil && time.Now().After(*trade.OfferExpiresAt) {
+			return ErrTradeInOfferExpired
+		}
+
+		var contract entity.SalesContract
+		if err := tx.Preload("SaleList").Where("customer_id = ?", customerID).
+			First(&contract, salesContractID).Error; err != nil {
+			return notFoundAs(err, ErrTradeInContractInvalid)
+		}
+		if contract.SaleList == nil {
+			return ErrTradeInContractInvalid
+		}
+		var paid float64
+		if err := tx.Model(&entity.Payment{}).
+			Select("COALESCE(SUM("+paymentAmountExpr+"), 0)").
+			Where("sales_contract_id = ? AND status = ?", contract.ID, PaymentStatusPaid).
+			Scan(&paid).Error; err != nil {
+			return err
+		}
+		if trade.OfferAmount > roundBaht(contract.SaleList.SalePrice-paid) {
+			return ErrTradeInExceedsBalance
+		}
+
+		car := entity.Car{ManagerID: derefUint(trade.ManagerID)}
+		name := strings.TrimSpace(strings.Join([]string{trade.BrandName, trade.ModelName, trade.SubModelName}, " "))
+		if err := s.inventory.applyInput(tx, &car, CarInput{
+			CarName:                name,
+			YearManufacture:        trade.YearManufacture,
+			PurchasePrice:          trade.OfferAmount,
+			PurchaseDate:           time.Now().Format("2006-01-02"),
+			Color:                  trade.Color,
+			Mileage:                trade.Mileage,
+			Condition:              trade.AppraisalNote,
+			VIN:                    trade.VIN,
+			EngineNumber:           trade.EngineNumber,
+			LicensePlate:           trade.LicensePlate,
+			RegistrationProvinceID: trade.RegistrationProvinceID,
+			BrandName:              trade.BrandName,
+			ModelName:              trade.ModelName,
+			SubModelName:           trade.SubModelName,
+		}); err != nil {
+			return err
+		}
+		if err := tx.Create(&car).Error; err != nil {
+			return err
+		}
+		carID = car.ID
+
+		var photos []entity.TradeInPhoto
+		if err := tx.Where("trade_in_id = ?", trade.ID).Order("sort_order ASC, id ASC").Find(&photos).Error; err != nil {
+			return err
+		}
+		if len(photos) > 0 {
+			pictures := make([]entity.CarPicture, len(photos))
+			for i, p := range photos {
+				pictures[i] = entity.CarPicture{CarID: car.ID, Path: p.Path, SortOrder: i + 1}
+			}
+			if err := tx.Create(&pictures).Error; err != nil {
+				return err
+			}
+		}
+
+		var method entity.PaymentMethod
+		if err := tx.Where(entity.PaymentMethod{MethodName: tradeInPaymentMethod}).
+			Attrs(entity.PaymentMethod{Description: "มูลค่ารถเทิร์นที่หักจากราคารถ"}).
+			FirstOrCreate(&method).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		payment := entity.Payment{
+			Amount:          strconv.FormatFloat(trade.OfferAmount, 'f', 2, 64),
+			PaymentDate:     now,
+			Status:          PaymentStatusPaid,
+			CustomerID:      customerID,
+			EmployeeID:      contract.EmployeeID,
+			SalesContractID: contract.ID,
+			PaymentMethodID: method.ID,
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(trade).Updates(map[string]interface{}{
+			"status":            entity.TradeInAccepted,
+			"sales_contract_id": contract.ID,
+			"payment_id":        payment.ID,
+			"car_id":            car.ID,
+			"accepted_at":       now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.inventory.reindex(carID)
+	return s.Get(id)
+}
+
+func derefUint(v *uint) uint {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
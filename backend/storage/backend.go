@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// ObjectInfo ข้อมูลของไฟล์ที่เก็บไว้
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Backend ที่เก็บไฟล์ (local filesystem หรือ S3-compatible)
+// key ใช้ "/" คั่นโฟลเดอร์ เช่น images/cars/<hash>.jpg
+type Backend interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL ลิงก์ดาวน์โหลดที่หมดอายุตาม ttl สำหรับไฟล์ส่วนตัว เช่น สัญญาและใบเสร็จ
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+var keySegment = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidKey ตรวจว่า key ไม่พาออกนอกที่เก็บ (ห้าม .., / นำหน้า หรืออักขระแปลก)
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 512 {
+		return false
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if !keySegment.MatchString(seg) || strings.Contains(seg, "..") {
+			return false
+		}
+	}
+	return true
+}
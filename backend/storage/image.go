@@ -0,0 +1,365 @@
+// Package storage เก็บไฟล์รูปที่ผู้ใช้อัปโหลดอย่างปลอดภัย
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // ให้ image.Decode อ่าน gif ได้
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	MaxImageBytes  = 10 << 20   // 10 MB ต่อไฟล์
+	MaxImagePixels = 50_000_000 // กันไฟล์ที่ขยายแล้วใหญ่ผิดปกติ
+	WebMaxSide     = 1600
+	ThumbMaxSide   = 320
+	jpegQuality    = 85
+)
+
+// ขนาดรูปที่เก็บไว้
+const (
+	VariantOriginal = ""
+	VariantWeb      = "web"
+	VariantThumb    = "thumb"
+)
+
+var (
+	ErrImageTooLarge       = fmt.Errorf("image exceeds %d bytes", MaxImageBytes)
+	ErrImageTooManyPixels  = errors.New("image dimensions are too large")
+	ErrUnsupportedImage    = errors.New("unsupported image type (allowed: jpeg, png, gif)")
+	ErrInvalidImageName    = errors.New("invalid image name")
+	ErrImageNotFound       = errors.New("image not found")
+	contentAddressedName   = regexp.MustCompile(`^[0-9a-f]{64}(_web|_thumb)?\.(jpg|png)$`)
+	safeImageName          = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	allowedImageMediaTypes = map[string]string{
+		"image/jpeg": "jpg",
+		"image/png":  "png",
+		"image/gif":  "png", // gif ถูกแปลงเป็น png (เก็บเฉพาะเฟรมแรก)
+	}
+)
+
+// StoredImage ผลการบันทึกรูป
+type StoredImage struct {
+	Name        string // ชื่อไฟล์ต้นฉบับ (content-addressed) เก็บใน CarPicture.Path
+	WebName     string
+	ThumbName   string
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// CarImagePrefix โฟลเดอร์ของรูปรถใน Backend (local: ./public/images/cars)
+const CarImagePrefix = "images/cars/"
+
+// ImageStore บันทึกรูปลง Backend โดยตั้งชื่อจาก hash ของเนื้อไฟล์
+type ImageStore struct {
+	backend Backend
+	prefix  string
+}
+
+func NewImageStore(backend Backend, prefix string) *ImageStore {
+	return &ImageStore{backend: backend, prefix: prefix}
+}
+
+// Save ตรวจชนิดไฟล์จากเนื้อไฟล์ จำกัดขนาด ลบ metadata (EXIF/GPS) แล้วบันทึกต้นฉบับ รูปขนาดเว็บ และ thumbnail
+func (s *ImageStore) Save(r io.Reader) (*StoredImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	ext, ok := allowedImageMediaTypes[http.DetectContentType(data)]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, ErrImageTooManyPixels
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if format == "jpeg" {
+		// ข้อมูล EXIF จะไม่ถูกเขียนกลับ จึงหมุนรูปตาม orientation ไว้ก่อน
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	out := &StoredImage{
+		Name:        hash + "." + ext,
+		WebName:     hash + "_web.jpg",
+		ThumbName:   hash + "_thumb.jpg",
+		ContentType: "image/jpeg",
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+
+	if ext == "png" {
+		out.ContentType = "image/png"
+	}
+
+	// ต้นฉบับถูก encode ใหม่ทั้งหมด metadata เดิมจึงหายไป
+	if err := s.writeIfMissing(out.Name, func(w io.Writer) error {
+		if ext == "png" {
+			return png.Encode(w, img)
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 92})
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.writeIfMissing(out.WebName, func(w io.Writer) error {
+		return jpeg.Encode(w, flatten(fit(img, WebMaxSide)), &jpeg.Options{Quality: jpegQuality})
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.writeIfMissing(out.ThumbName, func(w io.Writer) error {
+		return jpeg.Encode(w, flatten(fit(img, ThumbMaxSide)), &jpeg.Options{Quality: jpegQuality})
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ไฟล์ชื่อเดียวกันมีเนื้อหาเดียวกันเสมอ จึงไม่เขียนทับ
+func (s *ImageStore) writeIfMissing(name string, encode func(io.Writer) error) error {
+	ctx := context.Background()
+	if _, err := s.backend.Stat(ctx, s.prefix+name); err == nil {
+		return nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf); err != nil {
+		return err
+	}
+	return s.backend.Put(ctx, s.prefix+name, &buf, mime.TypeByExtension(filepath.Ext(name)))
+}
+
+// VariantName ชื่อไฟล์ของรูปขนาดที่ต้องการ (รูปเก่าที่ไม่มีขนาดย่อยจะคืนชื่อเดิม)
+func VariantName(name, variant string) string {
+	if variant == VariantOriginal || !IsContentAddressed(name) || strings.Contains(name, "_") {
+		return name
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "_" + variant + ".jpg"
+}
+
+// IsContentAddressed ชื่อไฟล์มาจาก hash ของเนื้อไฟล์ (เนื้อหาไม่เปลี่ยน แคชได้ถาวร)
+func IsContentAddressed(name string) bool {
+	return contentAddressedName.MatchString(name)
+}
+
+// Open เปิดไฟล์รูปตามชื่อ ชื่อที่มี path หรืออักขระแปลกจะถูกปฏิเสธ
+func (s *ImageStore) Open(name string) (io.ReadCloser, *ObjectInfo, error) {
+	if !safeImageName.MatchString(name) || strings.Contains(name, "..") {
+		return nil, nil, ErrInvalidImageName
+	}
+	rc, info, err := s.backend.Get(context.Background(), s.prefix+name)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil, ErrImageNotFound
+	}
+	return rc, info, err
+}
+
+// Exists มีไฟล์รูปชื่อนี้ใน Backend แล้วหรือยัง
+func (s *ImageStore) Exists(name string) (bool, error) {
+	if !safeImageName.MatchString(name) || strings.Contains(name, "..") {
+		return false, ErrInvalidImageName
+	}
+	_, err := s.backend.Stat(context.Background(), s.prefix+name)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete ลบต้นฉบับและขนาดย่อยทั้งหมด
+func (s *ImageStore) Delete(name string) error {
+	if !safeImageName.MatchString(name) || strings.Contains(name, "..") {
+		return ErrInvalidImageName
+	}
+	for _, n := range []string{name, VariantName(name, VariantWeb), VariantName(name, VariantThumb)} {
+		if err := s.backend.Delete(context.Background(), s.prefix+n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ย่อรูปให้ด้านยาวไม่เกิน maxSide (ไม่ขยาย)
+func fit(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+	return resizeBox(src, w, h)
+}
+
+// ย่อรูปด้วยการเฉลี่ยพิกเซลในแต่ละช่อง (box filter)
+func resizeBox(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := max(x0+1, b.Min.X+(x+1)*sw/w)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// JPEG ไม่มีความโปร่งใส จึงวางบนพื้นขาว
+func flatten(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// jpegOrientation อ่านค่า EXIF Orientation (1-8) จาก APP1 ถ้าไม่มีคืน 1
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // เริ่มข้อมูลภาพแล้ว
+			return 1
+		}
+		size := int(data[i+2])<<8 | int(data[i+3])
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(seg) > 14 && string(seg[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(t []byte) int {
+	if len(t) < 8 {
+		return 1
+	}
+	var u16 func([]byte) int
+	var u32 func([]byte) int
+	switch string(t[:2]) {
+	case "II":
+		u16 = func(b []byte) int { return int(b[0]) | int(b[1])<<8 }
+		u32 = func(b []byte) int { return u16(b) | u16(b[2:])<<16 }
+	case "MM":
+		u16 = func(b []byte) int { return int(b[0])<<8 | int(b[1]) }
+		u32 = func(b []byte) int { return u16(b)<<16 | u16(b[2:]) }
+	default:
+		return 1
+	}
+	ifd := u32(t[4:])
+	if ifd < 8 || ifd+2 > len(t) {
+		return 1
+	}
+	n := u16(t[ifd:])
+	for k := 0; k < n; k++ {
+		e := ifd + 2 + k*12
+		if e+12 > len(t) {
+			return 1
+		}
+		if u16(t[e:]) == 0x0112 {
+			if o := u16(t[e+8:]); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// หมุน/กลับรูปตามค่า EXIF Orientation
+func applyOrientation(src image.Image, o int) image.Image {
+	if o <= 1 || o > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	swap := o >= 5
+	dw, dh := w, h
+	if swap {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch o {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func newTestImageStore(t *testing.T) *ImageStore {
+	t.Helper()
+	return NewImageStore(NewLocalBackend(t.TempDir(), "/files", []byte("signing-key")), CarImagePrefix)
+}
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 60), G: uint8(y * 80), B: 200, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeTestImage(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, testImage(), nil)
+	case "png":
+		err = png.Encode(&buf, testImage())
+	case "gif":
+		err = gif.Encode(&buf, testImage(), nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageStoreSaveContentType(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []byte
+		wantErr     error
+		wantExt     string
+		contentType string
+	}{
+		{"jpeg", encodeTestImage(t, "jpeg"), nil, ".jpg", "image/jpeg"},
+		{"png", encodeTestImage(t, "png"), nil, ".png", "image/png"},
+		{"gif เก็บเป็น png", encodeTestImage(t, "gif"), nil, ".png", "image/png"},
+		{"ข้อความ", []byte("hello, not an image"), ErrUnsupportedImage, "", ""},
+		{"html", []byte("<html><body>x</body></html>"), ErrUnsupportedImage, "", ""},
+		{"pdf", []byte("%PDF-1.4\n%âãÏÓ\n"), ErrUnsupportedImage, "", ""},
+		{"bmp", append([]byte("BM"), make([]byte, 64)...), ErrUnsupportedImage, "", ""},
+		{"หัว png แต่ไฟล์เสีย", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...), ErrUnsupportedImage, "", ""},
+		{"ว่าง", nil, ErrUnsupportedImage, "", ""},
+		{"ใหญ่เกิน", make([]byte, MaxImageBytes+1), ErrImageTooLarge, "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestImageStore(t)
+			out, err := s.Save(bytes.NewReader(tc.data))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(out.Name, tc.wantExt) || out.ContentType != tc.contentType {
+				t.Errorf("name = %q content type = %q, want *%s %s", out.Name, out.ContentType, tc.wantExt, tc.contentType)
+			}
+			if out.Width != 4 || out.Height != 3 {
+				t.Errorf("size = %dx%d, want 4x3", out.Width, out.Height)
+			}
+			for _, n := range []string{out.Name, out.WebName, out.ThumbName} {
+				if !IsContentAddressed(n) {
+					t.Errorf("%q is not content addressed", n)
+				}
+				if ok, err := s.Exists(n); !ok || err != nil {
+					t.Errorf("Exists(%q) = %v, %v", n, ok, err)
+				}
+			}
+		})
+	}
+}
+
+func TestImageStoreRejectsUnsafeNames(t *testing.T) {
+	s := newTestImageStore(t)
+	cases := []struct {
+		name    string
+		wantErr error
+	}{
+		{"car.jpg", ErrImageNotFound},
+		{"toyota_camry-2020.png", ErrImageNotFound},
+		{strings.Repeat("a", 64) + ".jpg", ErrImageNotFound},
+		{"", ErrInvalidImageName},
+		{"../secret.jpg", ErrInvalidImageName},
+		{"a/../../etc/passwd", ErrInvalidImageName},
+		{"cars/a.jpg", ErrInvalidImageName},
+		{`cars\a.jpg`, ErrInvalidImageName},
+		{".hidden.jpg", ErrInvalidImageName},
+		{"-rf.jpg", ErrInvalidImageName},
+		{"a..jpg", ErrInvalidImageName},
+		{"รถ.jpg", ErrInvalidImageName},
+		{"a b.jpg", ErrInvalidImageName},
+		{"a.jpg\x00.png", ErrInvalidImageName},
+	}
+	for _, tc := range cases {
+		if _, _, err := s.Open(tc.name); !errors.Is(err, tc.wantErr) {
+			t.Errorf("Open(%q) = %v, want %v", tc.name, err, tc.wantErr)
+		}
+		invalid := tc.wantErr == ErrInvalidImageName
+		if _, err := s.Exists(tc.name); errors.Is(err, ErrInvalidImageName) != invalid {
+			t.Errorf("Exists(%q) err = %v, want invalid %v", tc.name, err, invalid)
+		}
+		if err := s.Delete(tc.name); errors.Is(err, ErrInvalidImageName) != invalid {
+			t.Errorf("Delete(%q) err = %v, want invalid %v", tc.name, err, invalid)
+		}
+	}
+}
+
+func TestImageVariantName(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	cases := []struct {
+		name, variant, want string
+	}{
+		{hash + ".jpg", VariantOriginal, hash + ".jpg"},
+		{hash + ".jpg", VariantWeb, hash + "_web.jpg"},
+		{hash + ".png", VariantThumb, hash + "_thumb.jpg"},
+		{hash + "_web.jpg", VariantThumb, hash + "_web.jpg"},
+		{"old-upload.jpg", VariantWeb, "old-upload.jpg"}, // รูปเก่าไม่มีขนาดย่อย
+		{strings.ToUpper(hash) + ".jpg", VariantWeb, strings.ToUpper(hash) + ".jpg"},
+		{hash + ".gif", VariantWeb, hash + ".gif"},
+	}
+	for _, tc := range cases {
+		if got := VariantName(tc.name, tc.variant); got != tc.want {
+			t.Errorf("VariantName(%q, %q) = %q, want %q", tc.name, tc.variant, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// LocalBackend เก็บไฟล์ในโฟลเดอร์บนเครื่อง
+// ลิงก์ที่เซ็นแล้วชี้ไปที่ baseURL (handler GET /files/*key) พร้อม expires และ signature
+type LocalBackend struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalBackend(root, baseURL string, secret []byte) *LocalBackend {
+	return &LocalBackend{root: root, baseURL: baseURL, secret: secret}
+}
+
+func (b *LocalBackend) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(b.root, filepath.FromSlash(key)), nil
+}
+
+func (b *LocalBackend) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	target, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// เขียนไฟล์ชั่วคราวก่อนแล้วค่อย rename เพื่อไม่ให้มีไฟล์ครึ่งๆ กลางๆ
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (b *LocalBackend) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := b.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, _ := b.path(key)
+	f, err := os.Open(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+func (b *LocalBackend) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	target, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{
+		Key:         key,
+		Size:        fi.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     fi.ModTime(),
+	}, nil
+}
+
+func (b *LocalBackend) Delete(_ context.Context, key string) error {
+	target, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (b *LocalBackend) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if _, err := b.Stat(ctx, key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", b.sign(key, expires))
+	return b.baseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+// VerifySignature ตรวจลิงก์ที่สร้างจาก SignedURL ว่าลายเซ็นถูกต้องและยังไม่หมดอายุ
+func (b *LocalBackend) VerifySignature(key, expires, signature string) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(b.sign(key, expires)), []byte(signature))
+}
+
+func (b *LocalBackend) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, b.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
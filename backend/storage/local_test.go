@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalSignedURLVerification(t *testing.T) {
+	ctx := context.Background()
+	b := NewLocalBackend(t.TempDir(), "/files", []byte("signing-key"))
+	key := "sales-contracts/3/contract.pdf"
+	if err := b.Put(ctx, key, strings.NewReader("pdf"), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := b.SignedURL(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/files/"+key {
+		t.Fatalf("path = %q", u.Path)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	if !b.VerifySignature(key, expires, signature) {
+		t.Fatal("fresh signature should verify")
+	}
+	if b.VerifySignature("sales-contracts/4/contract.pdf", expires, signature) {
+		t.Error("signature must not verify for another key")
+	}
+	if b.VerifySignature(key, expires, strings.Repeat("0", len(signature))) {
+		t.Error("tampered signature must not verify")
+	}
+	later := time.Now().Add(time.Hour).Unix()
+	if b.VerifySignature(key, strconv.FormatInt(later, 10), signature) {
+		t.Error("signature must not verify with extended expiry")
+	}
+	other := NewLocalBackend(t.TempDir(), "/files", []byte("other-key"))
+	if other.VerifySignature(key, expires, signature) {
+		t.Error("signature must not verify with another secret")
+	}
+
+	past, err := b.SignedURL(ctx, key, -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pu, _ := url.Parse(past)
+	if b.VerifySignature(key, pu.Query().Get("expires"), pu.Query().Get("signature")) {
+		t.Error("expired signature must not verify")
+	}
+
+	if _, err := b.SignedURL(ctx, "sales-contracts/9/missing.pdf", time.Minute); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SignedURL for missing file: got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testBucket    = "documents"
+	testRegion    = "ap-southeast-1"
+	testAccessKey = "minio-access"
+	testSecretKey = "minio-secret"
+)
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+// fakeS3 ตัวแทน MinIO แบบ path-style ที่ตรวจลายเซ็น SigV4 ทั้งแบบ header และ presigned URL
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	now     func() time.Time
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	f := &fakeS3{objects: map[string]fakeObject{}, now: time.Now}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+	body, _ := io.ReadAll(r.Body)
+
+	if r.URL.Query().Get("X-Amz-Signature") != "" {
+		if reason := f.verifyPresigned(r); reason != "" {
+			http.Error(w, reason, http.StatusForbidden)
+			return
+		}
+	} else if reason := f.verifyHeader(r, body); reason != "" {
+		http.Error(w, reason, http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{data: body, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet, http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verifyHeader ตรวจ Authorization header แบบ AWS4-HMAC-SHA256
+func (f *fakeS3) verifyHeader(r *http.Request, body []byte) string {
+	auth := strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	fields := map[string]string{}
+	for _, part := range strings.Split(auth, ", ") {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			fields[k] = v
+		}
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		return "XAmzContentSHA256Mismatch"
+	}
+	if !strings.HasPrefix(fields["Credential"], testAccessKey+"/") {
+		return "InvalidAccessKeyId"
+	}
+
+	var headers strings.Builder
+	for _, name := range strings.Split(fields["SignedHeaders"], ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), "", headers.String(), fields["SignedHeaders"], r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	if !hmac.Equal([]byte(fields["Signature"]), []byte(sigV4(amzDate, canonical))) {
+		return "SignatureDoesNotMatch"
+	}
+	return ""
+}
+
+// verifyPresigned ตรวจลายเซ็นใน query และเวลาหมดอายุตามนาฬิกาของ server
+func (f *fakeS3) verifyPresigned(r *http.Request) string {
+	q := r.URL.Query()
+	signature := q.Get("X-Amz-Signature")
+	q.Del("X-Amz-Signature")
+
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, awsEscape(k)+"="+awsEscape(q.Get(k)))
+	}
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), strings.Join(parts, "&"), "host:" + r.Host + "\n", "host", "UNSIGNED-PAYLOAD",
+	}, "\n")
+	if !hmac.Equal([]byte(signature), []byte(sigV4(q.Get("X-Amz-Date"), canonical))) {
+		return "SignatureDoesNotMatch"
+	}
+
+	signedAt, err := time.Parse("20060102T150405Z", q.Get("X-Amz-Date"))
+	if err != nil {
+		return "AuthorizationQueryParametersError"
+	}
+	seconds, err := strconv.Atoi(q.Get("X-Amz-Expires"))
+	if err != nil {
+		return "AuthorizationQueryParametersError"
+	}
+	if f.now().After(signedAt.Add(time.Duration(seconds) * time.Second)) {
+		return "Request has expired"
+	}
+	return ""
+}
+
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sigV4(amzDate, canonical string) string {
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	day := amzDate[:8]
+	scope := day + "/" + testRegion + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := mac([]byte("AWS4"+testSecretKey), day)
+	key = mac(key, testRegion)
+	key = mac(key, "s3")
+	key = mac(key, "aws4_request")
+	return hex.EncodeToString(mac(key, stringToSign))
+}
+
+func newTestS3Backend(t *testing.T, endpoint, secret string) *S3Backend {
+	t.Helper()
+	b, err := NewS3Backend(S3Config{
+		Endpoint:  endpoint,
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: secret,
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	_, srv := newFakeS3(t)
+	b := newTestS3Backend(t, srv.URL, testSecretKey)
+	ctx := context.Background()
+	key := "sales-contracts/12/สัญญา 1.pdf"
+	if err := b.Put(ctx, key, strings.NewReader("%PDF-1.4 test"), "application/pdf"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Put with spaces in key: got %v, want ErrInvalidKey", err)
+	}
+
+	key = "sales-contracts/12/contract.pdf"
+	content := []byte("%PDF-1.4 test")
+	if err := b.Put(ctx, key, bytes.NewReader(content), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	info, err := b.Stat(ctx, key)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size != int64(len(content)) || info.ContentType != "application/pdf" {
+		t.Fatalf("Stat = %+v", info)
+	}
+
+	rc, info, err := b.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, content) || info.ContentType != "application/pdf" {
+		t.Fatalf("Get = %q (%s)", got, info.ContentType)
+	}
+
+	if err := b.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := b.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after delete: got %v, want ErrNotFound", err)
+	}
+	if err := b.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete of missing object should succeed, got %v", err)
+	}
+}
+
+func TestS3RejectsWrongSecret(t *testing.T) {
+	_, srv := newFakeS3(t)
+	b := newTestS3Backend(t, srv.URL, "wrong-secret")
+	err := b.Put(context.Background(), "images/cars/a.jpg", strings.NewReader("jpg"), "image/jpeg")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("Put with wrong secret: got %v", err)
+	}
+}
+
+func TestS3SignedURLExpiry(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	b := newTestS3Backend(t, srv.URL, testSecretKey)
+	ctx := context.Background()
+	key := "receipts/7/receipt.pdf"
+	if err := b.Put(ctx, key, strings.NewReader("receipt"), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := b.SignedURL(ctx, key, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(signed)
+	if u.Query().Get("X-Amz-Expires") != "600" {
+		t.Fatalf("X-Amz-Expires = %q, want 600", u.Query().Get("X-Amz-Expires"))
+	}
+
+	fetch := func(link string) (int, string) {
+		resp, err := http.Get(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, body := fetch(signed); code != http.StatusOK || body != "receipt" {
+		t.Fatalf("fresh link: %d %q", code, body)
+	}
+
+	fake.now = func() time.Time { return time.Now().Add(9 * time.Minute) }
+	if code, _ := fetch(signed); code != http.StatusOK {
+		t.Fatalf("link before expiry: %d", code)
+	}
+
+	fake.now = func() time.Time { return time.Now().Add(11 * time.Minute) }
+	if code, body := fetch(signed); code != http.StatusForbidden || !strings.Contains(body, "expired") {
+		t.Fatalf("expired link: %d %q", code, body)
+	}
+
+	fake.now = time.Now
+	q := u.Query()
+	q.Set("X-Amz-Expires", "604800")
+	u.RawQuery = q.Encode()
+	if code, _ := fetch(u.String()); code != http.StatusForbidden {
+		t.Fatalf("link with extended expiry should fail signature check, got %d", code)
+	}
+}
+
+func TestS3SignedURLTTLBounds(t *testing.T) {
+	b := newTestS3Backend(t, "http://127.0.0.1:9000", testSecretKey)
+	for _, ttl := range []time.Duration{0, -time.Minute, maxPresignTTL + time.Second} {
+		if _, err := b.SignedURL(context.Background(), "receipts/1/a.pdf", ttl); err == nil {
+			t.Errorf("SignedURL ttl=%s: expected error", ttl)
+		}
+	}
+	if _, err := b.SignedURL(context.Background(), "../etc/passwd", time.Minute); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("SignedURL with traversal key: got %v, want ErrInvalidKey", err)
+	}
+}
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// คำศัพท์สำหรับตัดคำภาษาไทย (เน้นคำที่พบในข้อมูลรถและคำค้นของลูกค้า)
+var thaiDictionary = map[string]bool{}
+
+var thaiWords = []string{
+	// ทั่วไป
+	"รถ", "รถยนต์", "รถเก๋ง", "รถกระบะ", "กระบะ", "เก๋ง", "คัน", "คันนี้", "มือสอง", "มือเดียว", "ป้ายแดง",
+	"ขาย", "เช่า", "ราคา", "ถูก", "แพง", "ดาวน์", "ผ่อน", "ฟรี", "ประกัน", "ชั้นหนึ่ง",
+	"ใหม่", "เก่า", "สวย", "เดิม", "เดิมๆ", "ไมล์", "น้อย", "มาก", "แท้", "ประหยัด", "น้ำมัน",
+	"เกียร์", "ออโต้", "อัตโนมัติ", "ธรรมดา", "ดีเซล", "เบนซิน", "ไฮบริด", "ไฟฟ้า", "เครื่อง", "ยนต์",
+	"ประตู", "ที่นั่ง", "เร็ว", "แรง", "ทะลุ", "นรก", "นั่ง", "สบาย", "ขับ", "ขี่", "ปี", "รุ่น", "สี",
+	// สภาพ
+	"สภาพ", "ดี", "ดีมาก", "ปานกลาง", "พอใช้", "แย่", "เยี่ยม", "ชน", "ไม่เคยชน", "ไม่", "เคย", "น้ำท่วม",
+	// สี
+	"ขาว", "ดำ", "แดง", "เทา", "เงิน", "บรอนซ์", "บรอนซ์เงิน", "น้ำเงิน", "ฟ้า", "เขียว", "เหลือง", "ส้ม",
+	"น้ำตาล", "ทอง", "ชมพู", "ม่วง", "ครีม",
+	// ยี่ห้อ
+	"โตโยต้า", "ฮอนด้า", "นิสสัน", "มาสด้า", "อีซูซุ", "มิตซูบิชิ", "ฟอร์ด", "เชฟโรเลต", "ซูซูกิ",
+	"เกีย", "ฮุนได", "เบนซ์", "บีเอ็มดับเบิลยู", "วอลโว่", "เอ็มจี", "ซูบารุ", "เลกซัส", "เปอโยต์",
+	// รุ่นยอดนิยม
+	"คัมรี่", "แคมรี่", "โคโรลล่า", "อัลติส", "ยาริส", "วีออส", "ฟอร์จูนเนอร์", "ไฮลักซ์", "รีโว่", "วีโก้",
+	"ซีวิค", "แจ๊ส", "ซิตี้", "แอคคอร์ด", "อัลเมร่า", "มาร์ช", "นาวาร่า", "เรนเจอร์", "เอเวอเรสต์",
+	"ไทรทัน", "ปาเจโร่", "แลนเซอร์", "มิราจ", "ดีแม็กซ์",
+}
+
+// คำพ้องอังกฤษ-ไทยของสี/ยี่ห้อ/รุ่น ใช้ได้ทั้งสองทาง (init สร้างคู่กลับจากไทยเป็นอังกฤษ)
+var thaiSynonyms = map[string][]string{
+	"white":      {"ขาว"},
+	"black":      {"ดำ"},
+	"red":        {"แดง"},
+	"blue":       {"น้ำเงิน", "ฟ้า"},
+	"gray":       {"เทา"},
+	"grey":       {"เทา"},
+	"silver":     {"เงิน", "บรอนซ์เงิน"},
+	"green":      {"เขียว"},
+	"yellow":     {"เหลือง"},
+	"orange":     {"ส้ม"},
+	"brown":      {"น้ำตาล"},
+	"gold":       {"ทอง"},
+	"toyota":     {"โตโยต้า"},
+	"honda":      {"ฮอนด้า"},
+	"nissan":     {"นิสสัน"},
+	"mazda":      {"มาสด้า"},
+	"isuzu":      {"อีซูซุ"},
+	"mitsubishi": {"มิตซูบิชิ"},
+	"ford":       {"ฟอร์ด"},
+	"chevrolet":  {"เชฟโรเลต"},
+	"suzuki":     {"ซูซูกิ"},
+	"mercedes":   {"เบนซ์"},
+	"bmw":        {"บีเอ็มดับเบิลยู"},
+	"camry":      {"คัมรี่", "แคมรี่"},
+	"corolla":    {"โคโรลล่า"},
+	"civic":      {"ซีวิค"},
+	"city":       {"ซิตี้"},
+	"almera":     {"อัลเมร่า"},
+	"ranger":     {"เรนเจอร์"},
+	"triton":     {"ไทรทัน"},
+	"fortuner":   {"ฟอร์จูนเนอร์"},
+}
+
+func init() {
+	for _, w := range thaiWords {
+		thaiDictionary[w] = true
+	}
+
+	english := make([]string, 0, len(thaiSynonyms))
+	for en := range thaiSynonyms {
+		english = append(english, en)
+	}
+	sort.Strings(english)
+	for _, en := range english {
+		for _, th := range thaiSynonyms[en] {
+			if !slices.Contains(thaiSynonyms[th], en) {
+				thaiSynonyms[th] = append(thaiSynonyms[th], en)
+			}
+		}
+	}
+}
+
+// Synonyms คืนคำพ้องของคำที่ผ่าน Tokenize แล้ว (ไทย→อังกฤษ หรืออังกฤษ→ไทย)
+func Synonyms(word string) []string {
+	return thaiSynonyms[word]
+}
+
+func isThai(r rune) bool {
+	return r >= 0x0E00 && r <= 0x0E7F
+}
+
+// SegmentThai ตัดคำภาษาไทยแบบ maximal matching ตามพจนานุกรม
+// ส่วนที่ไม่รู้จักจะถูกรวมเป็นคำเดียว
+func SegmentThai(s string) []string {
+	runes := []rune(s)
+	n := len(runes)
+	if n == 0 {
+		return nil
+	}
+
+	type state struct {
+		unknown int // จำนวนตัวอักษรที่ไม่อยู่ในพจนานุกรม
+		words   int
+		prev    int
+		known   bool
+	}
+	const maxWordLen = 20
+
+	best := make([]*state, n+1)
+	best[0] = &state{}
+	better := func(a, b *state) bool {
+		if a.unknown != b.unknown {
+			return a.unknown < b.unknown
+		}
+		return a.words < b.words
+	}
+
+	for i := 0; i < n; i++ {
+		if best[i] == nil {
+			continue
+		}
+		for j := i + 1; j <= n && j-i <= maxWordLen; j++ {
+			if !thaiDictionary[string(runes[i:j])] {
+				continue
+			}
+			cand := &state{unknown: best[i].unknown, words: best[i].words + 1, prev: i, known: true}
+			if best[j] == nil || better(cand, best[j]) {
+				best[j] = cand
+			}
+		}
+		cand := &state{unknown: best[i].unknown + 1, words: best[i].words + 1, prev: i}
+		if best[i+1] == nil || better(cand, best[i+1]) {
+			best[i+1] = cand
+		}
+	}
+
+	// ย้อนเส้นทาง แล้วรวมตัวอักษรที่ไม่รู้จักที่ติดกันเป็นคำเดียว
+	var spans [][2]int
+	var known []bool
+	for j := n; j > 0; j = best[j].prev {
+		spans = append(spans, [2]int{best[j].prev, j})
+		known = append(known, best[j].known)
+	}
+
+	var words []string
+	start := -1
+	for k := len(spans) - 1; k >= 0; k-- {
+		sp := spans[k]
+		if known[k] {
+			if start >= 0 {
+				words = append(words, string(runes[start:sp[0]]))
+				start = -1
+			}
+			words = append(words, string(runes[sp[0]:sp[1]]))
+			continue
+		}
+		if start < 0 {
+			start = sp[0]
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// Tokenize แปลงข้อความเป็นคำค้นตัวพิมพ์เล็ก แยกตามช่องว่าง/เครื่องหมาย และตัดคำภาษาไทย
+func Tokenize(s string) []string {
+	var tokens []string
+	var buf []rune
+	thai := false
+
+	flush := func() {
+		if len(buf) == 0 {
+			return
+		}
+		if thai {
+			tokens = append(tokens, SegmentThai(string(buf))...)
+		} else {
+			tokens = append(tokens, string(buf))
+		}
+		buf = buf[:0]
+	}
+
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case isThai(r):
+			if !thai {
+				flush()
+				thai = true
+			}
+			buf = append(buf, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if thai {
+				flush()
+				thai = false
+			}
+			buf = append(buf, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
@@ -70,7 +70,7 @@ const EmployeeEditForm: React.FC<Props> = ({ employee, onCancel, onSave }) => {
           </div>
           <div className="form-group">
             <label>ยอดขายรวม</label>
-            <input id="totalSales" value={formData.totalSales || ""} onChange={handleChange} />
+            <input id="totalSales" value={(formData.totalSales || 0).toLocaleString()} disabled />
           </div>
           <div className="modal-buttons">
             <button type="submit">บันทึก</button>
@@ -1,5 +1,5 @@
 import React from "react";
-import type { Leave } from "../../types/leave";
+import { leaveTypeLabels, type Leave } from "../../types/leave";
 
 interface Props {
   leaves: Leave[];
@@ -34,9 +34,9 @@ const LeaveHistory: React.FC<Props> = ({ leaves }) => {
               const status = getStatusLabel(leave.status);
               return (
                 <tr key={leave.leaveID}>
-                  <td>{leave.startDate}</td>
-                  <td>{leave.endDate}</td>
-                  <td>{leave.type}</td>
+                  <td>{leave.startDate.slice(0, 10)}</td>
+                  <td>{leave.endDate.slice(0, 10)}</td>
+                  <td>{leaveTypeLabels[leave.type] ?? leave.type}</td>
                   <td className={status.class}>{status.text}</td>
                 </tr>
               );
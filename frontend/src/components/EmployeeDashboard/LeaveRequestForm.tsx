@@ -10,7 +10,7 @@ interface Props {
 const LeaveRequestForm: React.FC<Props> = ({ onSubmit, onCancel, showNotification }) => {
   const [startDate, setStartDate] = useState("");
   const [endDate, setEndDate] = useState("");
-  const [type, setType] = useState<LeaveType>("ลาป่วย");
+  const [type, setType] = useState<LeaveType>("sick");
 
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
@@ -28,7 +28,7 @@ const LeaveRequestForm: React.FC<Props> = ({ onSubmit, onCancel, showNotificatio
     onSubmit({ startDate, endDate, type });
     setStartDate("");
     setEndDate("");
-    setType("ลาป่วย");
+    setType("sick");
   };
 
   return (
@@ -47,9 +47,9 @@ const LeaveRequestForm: React.FC<Props> = ({ onSubmit, onCancel, showNotificatio
           <div className="form-group">
             <label>ประเภทการลา</label>
             <select value={type} onChange={(e) => setType(e.target.value as LeaveType)}>
-              <option value="ลาป่วย">ลาป่วย</option>
-              <option value="ลากิจ">ลากิจ</option>
-              <option value="ลาพักร้อน">ลาพักร้อน</option>
+              <option value="sick">ลาป่วย</option>
+              <option value="personal">ลากิจ</option>
+              <option value="vacation">ลาพักร้อน</option>
             </select>
           </div>
           <div className="form-group modal-buttons">
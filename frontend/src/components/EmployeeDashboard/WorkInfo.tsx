@@ -3,14 +3,11 @@ import React from "react";
 interface Props {
   position: string;
   jobType: string;
-  totalSales: string;
+  totalSales: number;
 }
 
 const WorkInfo: React.FC<Props> = ({ position, jobType, totalSales }) => {
-  const formatSales = (value: string) => {
-    const num = Number(value.replace(/,/g, ""));
-    return isNaN(num) ? value : num.toLocaleString();
-  };
+  const formatSales = (value: number) => (value || 0).toLocaleString();
 
   return (
     <section className="card work-info">
@@ -101,8 +101,8 @@ const EmployeeEditForm: React.FC<Props> = ({ employee, onCancel, onSave }) => {
             <label>ยอดขายรวม</label>
             <input
               id="totalSales"
-              value={formData.totalSales || ""}
-              onChange={handleChange}
+              value={(formData.totalSales || 0).toLocaleString()}
+              disabled
             />
           </div>
           <div className="modal-buttons">
@@ -32,7 +32,7 @@ const EmployeeTable: React.FC<Props> = ({ employees, showNotification, onSave, o
             birthday: "",
             position: "",
             jobType: "",
-            totalSales: "0",
+            totalSales: 0,
           } as Employee);
           showNotification({ type: "success", message: "กำลังเพิ่มพนักงานใหม่" });
         }}
@@ -60,7 +60,7 @@ const EmployeeTable: React.FC<Props> = ({ employees, showNotification, onSave, o
               <td>{emp.email}</td>
               <td>{emp.position}</td>
               <td>{emp.jobType}</td>
-              <td>{emp.totalSales}</td>
+              <td>{(emp.totalSales || 0).toLocaleString()}</td>
               <td>
                 {/* ปุ่มแก้ไข */}
                 <button onClick={() => {
@@ -1,5 +1,5 @@
 import React from "react";
-import type { Leave } from "../../types/leave";
+import { leaveTypeLabels, type Leave } from "../../types/leave";
 
 interface Props {
   leaveRequests: Leave[];
@@ -28,9 +28,9 @@ const LeaveApprovalList: React.FC<Props> = ({ leaveRequests, onAction }) => {
             {leaveRequests.map(leave => (
               <tr key={leave.leaveID}>
                 <td>{leave.leaveID}</td>
-                <td>{leave.startDate}</td>
-                <td>{leave.endDate}</td>
-                <td>{leave.type}</td>
+                <td>{leave.startDate.slice(0, 10)}</td>
+                <td>{leave.endDate.slice(0, 10)}</td>
+                <td>{leaveTypeLabels[leave.type] ?? leave.type}</td>
                 <td>
                   <button className="approve-btn" onClick={() => onAction(leave.leaveID, "approved")}>
                     อนุมัติ
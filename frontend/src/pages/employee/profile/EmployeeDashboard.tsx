@@ -38,11 +38,11 @@ const EmployeeDashboard: React.FC = () => {
 
   // ✅ โหลดประวัติการลา
   useEffect(() => {
-    if (!formData?.employeeID) return;
-    getLeavesByEmployee(formData.employeeID)
+    if (!formData?.employeeID || !token) return;
+    getLeavesByEmployee(formData.employeeID, token)
       .then(leaves => setLeaveHistory(leaves))
       .catch(() => showNotification({ type: "error", message: "โหลดประวัติการลาไม่สำเร็จ" }));
-  }, [formData?.employeeID]);
+  }, [formData?.employeeID, token]);
 
   // ✅ แสดง Notification
   const showNotification = (notif: { type: "success" | "error"; message: string }) => {
@@ -93,16 +93,17 @@ const EmployeeDashboard: React.FC = () => {
 
   // ✅ ส่งคำขอลา
   const handleLeaveSubmit = async (data: { startDate: string; endDate: string; type: LeaveType }) => {
-    if (!formData) return;
-    const { employeeID } = formData;
+    if (!formData || !token) return;
 
     try {
-      const leave = await createLeave({
-        employeeID,
-        startDate: data.startDate,
-        endDate: data.endDate,
-        type: data.type,
-      });
+      const leave = await createLeave(
+        {
+          startDate: data.startDate,
+          endDate: data.endDate,
+          type: data.type,
+        },
+        token
+      );
 
       setLeaveHistory(prev => [...prev, leave]);
       showNotification({
@@ -110,8 +111,9 @@ const EmployeeDashboard: React.FC = () => {
         message: `ส่งคำขอลาเรียบร้อย: ${data.type} ${data.startDate} ถึง ${data.endDate}`,
       });
       setShowLeaveForm(false);
-    } catch {
-      showNotification({ type: "error", message: "ส่งคำขอลาไม่สำเร็จ" });
+    } catch (err) {
+      const detail = err instanceof Error ? `: ${err.message}` : "";
+      showNotification({ type: "error", message: `ส่งคำขอลาไม่สำเร็จ${detail}` });
     }
   };
 
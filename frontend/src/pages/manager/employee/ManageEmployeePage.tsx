@@ -6,11 +6,13 @@ import Notification from "../../../components/common/Notification";
 
 import { getEmployees, addEmployee, updateEmployee, deleteEmployee } from "../../../services/employeeService";
 import { getPendingLeaves, updateLeaveStatus } from "../../../services/leaveService";
+import { useAuth } from "../../../hooks/useAuth";
 
 import type { Employee } from "../../../types/employee";
 import type { Leave } from "../../../types/leave";
 
 const ManagerDashboard: React.FC = () => {
+  const { token } = useAuth();
   const [activeTab, setActiveTab] = useState("leave");
   const [notification, setNotification] = useState<{ type: "success" | "error"; message: string } | null>(null);
 
@@ -28,12 +30,12 @@ const ManagerDashboard: React.FC = () => {
 
   // ✅ โหลดคำขอลารออนุมัติ
   useEffect(() => {
-    if (activeTab === "leave") {
-      getPendingLeaves()
+    if (activeTab === "leave" && token) {
+      getPendingLeaves(token)
         .then(setLeaveRequests)
         .catch(() => showNotification({ type: "error", message: "โหลดคำขอลาไม่สำเร็จ" }));
     }
-  }, [activeTab]);
+  }, [activeTab, token]);
 
   // ✅ แจ้งเตือน
   const showNotification = (notif: { type: "success" | "error"; message: string }) => {
@@ -79,7 +81,8 @@ const ManagerDashboard: React.FC = () => {
   // ==========================
   const handleLeaveAction = async (leaveID: string, action: "approved" | "denied") => {
     try {
-      await updateLeaveStatus(leaveID, action);
+      if (!token) throw new Error("unauthorized");
+      await updateLeaveStatus(leaveID, action, token);
       setLeaveRequests(prev => prev.filter(l => l.leaveID !== leaveID));
       showNotification({
         type: "success",
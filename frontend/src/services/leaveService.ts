@@ -1,43 +1,71 @@
-import type { Leave, LeaveType } from "../types/leave";
+import type { Leave, LeaveBalance, LeaveType } from "../types/leave";
 
 const API = "http://localhost:8080/api/leaves";
 
-// ✅ ดึงคำขอลารออนุมัติ
-export async function getPendingLeaves(): Promise<Leave[]> {
-  const res = await fetch(`${API}?status=pending`);
+// ✅ ดึงคำขอลารออนุมัติ (ผู้จัดการเห็นทั้งหมด พนักงานเห็นเฉพาะของตัวเอง)
+export async function getPendingLeaves(token: string): Promise<Leave[]> {
+  const res = await fetch(`${API}?status=pending`, {
+    headers: { Authorization: `Bearer ${token}` },
+  });
   if (!res.ok) throw new Error(`getPendingLeaves failed: ${res.status}`);
   return res.json();
 }
 
-// ✅ ดึงคำขอลาทั้งหมดของพนักงาน
-export async function getLeavesByEmployee(employeeID: number): Promise<Leave[]> {
-  const res = await fetch(`${API.replace("/leaves", "")}/employees/${employeeID}/leaves`);
+// ✅ ดึงคำขอลาทั้งหมดของพนักงาน (พนักงานดูได้เฉพาะของตัวเอง)
+export async function getLeavesByEmployee(employeeID: number, token: string): Promise<Leave[]> {
+  const res = await fetch(`${API.replace("/leaves", "")}/employees/${employeeID}/leaves`, {
+    headers: { Authorization: `Bearer ${token}` },
+  });
   if (!res.ok) throw new Error(`getLeavesByEmployee failed: ${res.status}`);
   return res.json();
 }
 
-// ✅ สร้างคำขอลา
-export async function createLeave(data: {
-  employeeID: number;   // ✅ เปลี่ยนจาก string → number
-  startDate: string;
-  endDate: string;
-  type: LeaveType;
-}): Promise<Leave> {
+// ✅ สร้างคำขอลา (ต้องใช้ token ของพนักงาน ระบบลาให้เจ้าของ token เท่านั้น)
+export async function createLeave(
+  data: {
+    startDate: string;
+    endDate: string;
+    type: LeaveType;
+  },
+  token: string
+): Promise<Leave> {
   const res = await fetch(API, {
     method: "POST",
-    headers: { "Content-Type": "application/json" },
+    headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
     body: JSON.stringify(data),
   });
-  if (!res.ok) throw new Error(`createLeave failed: ${res.status}`);
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    throw new Error(body.error || `createLeave failed: ${res.status}`);
+  }
   return res.json();
 }
 
-// ✅ อัปเดตสถานะคำขอลา
-export async function updateLeaveStatus(leaveID: number, status: "approved" | "denied"): Promise<void> {
+// ✅ ดึงวันลาคงเหลือของพนักงาน (พนักงานดูได้เฉพาะของตัวเอง ผู้จัดการดูได้ทุกคน)
+export async function getLeaveBalances(employeeID: number, token: string, year?: number): Promise<LeaveBalance[]> {
+  const query = year ? `?year=${year}` : "";
+  const res = await fetch(`${API.replace("/leaves", "")}/employees/${employeeID}/leave-balances${query}`, {
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  if (!res.ok) throw new Error(`getLeaveBalances failed: ${res.status}`);
+  return res.json();
+}
+
+// ✅ อนุมัติ/ปฏิเสธคำขอลา (ต้องใช้ token ของผู้จัดการ)
+export async function updateLeaveStatus(
+  leaveID: string,
+  status: "approved" | "denied",
+  token: string,
+  comment = ""
+): Promise<Leave> {
   const res = await fetch(`${API}/${leaveID}/status`, {
     method: "PUT",
-    headers: { "Content-Type": "application/json" },
-    body: JSON.stringify({ status }),
+    headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
+    body: JSON.stringify({ status, comment }),
   });
-  if (!res.ok) throw new Error(`updateLeaveStatus failed: ${res.status}`);
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    throw new Error(body.error || `updateLeaveStatus failed: ${res.status}`);
+  }
+  return res.json();
 }
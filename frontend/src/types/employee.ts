@@ -13,5 +13,6 @@ export interface Employee {
   birthday?: string;  // ✅ ใช้ string (YYYY-MM-DD)
   position: string;
   jobType: JobType;
-  totalSales: string;
+  totalSales: number; // คำนวณจากสัญญาซื้อขาย (แก้ไขไม่ได้)
+  salesCount?: number;
 }
@@ -1,11 +1,32 @@
 // src/types/leave.ts
 export type LeaveType = "sick" | "vacation" | "personal";
 
+export const leaveTypeLabels: Record<LeaveType, string> = {
+  sick: "ลาป่วย",
+  personal: "ลากิจ",
+  vacation: "ลาพักร้อน",
+};
+
 export interface Leave {
-  leaveID: number;
+  leaveID: string;
   employeeID: number;   // ✅ จาก string → number
-  startDate: string;
+  startDate: string;    // ISO datetime จาก backend
   endDate: string;
+  days: number;
   type: LeaveType;
+  reason?: string;
   status?: "pending" | "approved" | "denied";
+  decidedByID?: number | null;
+  decidedAt?: string | null;
+  managerComment?: string;
+}
+
+export interface LeaveBalance {
+  employeeID: number;
+  year: number;
+  type: LeaveType;
+  entitled: number;
+  used: number;
+  pending: number;
+  remaining: number;
 }
@@ -17,8 +17,5 @@ export const validateAll = (data: Employee) => {
   if (!data.position) errors.position = "กรุณากรอกตำแหน่ง";
   if (!data.jobType) errors.jobType = "กรุณากรอกประเภทงาน";
 
-  if (!data.totalSales) errors.totalSales = "กรุณากรอกยอดขาย";
-  else if (isNaN(Number(data.totalSales))) errors.totalSales = "ยอดขายต้องเป็นตัวเลข";
-
   return errors;
 };